@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"telemetron/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// maxReportBytes bounds the body of heartbeats and task reports.
+const maxReportBytes = 1 << 20
+
+// @Summary Record an agent heartbeat
+// @Description Registers the agent on first use and marks it alive. Agents without a heartbeat for
+// @Description AGENT_STALE_SECONDS are reported stale. Details left empty keep their registered values.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param name path string true "Agent name"
+// @Param heartbeat body models.AgentHeartbeat false "Agent details"
+// @Success 200 {object} models.Agent
+// @Failure 400 {string} string "Bad request"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 501 {string} string "Agent ingestion is not enabled"
+// @Router /agents/{name}/heartbeat [post]
+func heartbeatHandler(agents *repositories.PushAgentRepository, systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if agents == nil {
+			http.Error(w, "Agent ingestion is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		var heartbeat models.AgentHeartbeat
+		if err := decodeReport(r, &heartbeat); err != nil {
+			http.Error(w, "Invalid heartbeat: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		agent, err := agents.Heartbeat(r.PathValue("name"), heartbeat)
+		respondAgent(w, systemService, agent, err)
+	}
+}
+
+// @Summary Report agent task statuses
+// @Description Sets the status and details of the listed tasks, adding tasks the agent has not
+// @Description reported before. Tasks move from pending to running to completed, failed or
+// @Description cancelled; the last three are final and stay listed for AGENT_TASK_RETENTION_SECONDS.
+// @Description started_at and finished_at default to when the transition is reported. Details left
+// @Description empty keep their previous values. A report also counts as a heartbeat.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param name path string true "Agent name"
+// @Param report body models.TaskReport true "Changed tasks"
+// @Success 200 {object} models.Agent
+// @Failure 400 {string} string "Bad request"
+// @Failure 409 {string} string "Task already finished or transition not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 501 {string} string "Agent ingestion is not enabled"
+// @Router /agents/{name}/tasks [post]
+func tasksHandler(agents *repositories.PushAgentRepository, systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if agents == nil {
+			http.Error(w, "Agent ingestion is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		var report models.TaskReport
+		if err := decodeReport(r, &report); err != nil {
+			http.Error(w, "Invalid task report: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		agent, err := agents.ReportTasks(r.PathValue("name"), report.Tasks)
+		respondAgent(w, systemService, agent, err)
+	}
+}
+
+// decodeReport decodes an optional JSON body into v.
+func decodeReport(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(io.LimitReader(r.Body, maxReportBytes)).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func respondAgent(w http.ResponseWriter, systemService *services.SystemService, agent models.Agent, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrInvalidTask):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repositories.ErrTaskFinished), errors.Is(err, repositories.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		logger.Log.Error("Failed to record agent report", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	default:
+		systemService.Invalidate(models.SectionAgents)
+		writeJSON(w, http.StatusOK, agent)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"testing"
+	"time"
+)
+
+func newAgentsMux(t *testing.T) (*http.ServeMux, *services.SystemService) {
+	t.Helper()
+	agents, err := repositories.NewPushAgentRepository("", time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemService := services.NewSystemService(agents, repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(), repositories.NewMockLiteLLMRepository(),
+		services.WithCacheTTL(time.Hour))
+	t.Cleanup(systemService.Close)
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /agents/{name}/heartbeat", heartbeatHandler(agents, systemService))
+	mux.Handle("POST /agents/{name}/tasks", tasksHandler(agents, systemService))
+	return mux, systemService
+}
+
+func postJSON(mux http.Handler, path, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", path, strings.NewReader(body)))
+	return rr
+}
+
+func TestAgentIngestion(t *testing.T) {
+	mux, systemService := newAgentsMux(t)
+
+	// Prime the cache so the reports below must invalidate it.
+	if state, _ := systemService.GetSystemState(context.Background()); len(state.Agents) != 0 {
+		t.Fatalf("Expected no agents before the first heartbeat, got %+v", state.Agents)
+	}
+
+	rr := postJSON(mux, "/agents/agent-7/heartbeat", `{"description": "Summarizer", "max_parallel_invocations": 2, "models": ["gpt-4"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var agent models.Agent
+	if err := json.Unmarshal(rr.Body.Bytes(), &agent); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if agent.Name != "agent-7" || agent.MaxParallelInvocations != 2 || agent.Activity.LastHeartbeat.IsZero() {
+		t.Errorf("Unexpected registered agent %+v", agent)
+	}
+
+	rr = postJSON(mux, "/agents/agent-7/tasks", `{"tasks": [{"id": "task-1", "status": "running"}, {"id": "task-2", "status": "pending"}]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	postJSON(mux, "/agents/agent-7/tasks", `{"tasks": [{"id": "task-1", "status": "failed", "error": "model request timed out",
+		"model": "gpt-4", "input_tokens": 1200, "queue": "default", "queue_task_id": "q-1"}]}`)
+
+	state, err := systemService.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Agents) != 1 {
+		t.Fatalf("Expected the reported agent in the system state, got %+v", state.Agents)
+	}
+	tasks := state.Agents[0].Activity.ActiveTaskIDs
+	if len(tasks) != 2 || tasks[0].Status != models.TaskFailed || tasks[1].Status != models.TaskPending {
+		t.Fatalf("Unexpected tasks %+v", tasks)
+	}
+	failed := tasks[0]
+	if failed.StartedAt.IsZero() || failed.FinishedAt.Before(failed.StartedAt) || failed.Error != "model request timed out" ||
+		failed.Model != "gpt-4" || failed.InputTokens != 1200 || failed.QueueTaskID != "q-1" {
+		t.Errorf("Expected the failed task with its lifecycle and details, got %+v", failed)
+	}
+}
+
+func TestAgentIngestion_Errors(t *testing.T) {
+	mux, _ := newAgentsMux(t)
+	postJSON(mux, "/agents/agent-1/tasks", `{"tasks": [{"id": "task-1", "status": "completed"}]}`)
+
+	tests := []struct {
+		path, body string
+		code       int
+	}{
+		{"/agents/agent-1/heartbeat", `{"models": "gpt-4"}`, http.StatusBadRequest},
+		{"/agents/agent-1/tasks", `{"tasks": [{"id": "task-2", "status": "done"}]}`, http.StatusBadRequest},
+		{"/agents/agent-1/tasks", `{"tasks": [{"status": "running"}]}`, http.StatusBadRequest},
+		{"/agents/agent-1/tasks", `{"tasks": [{"id": "task-1", "status": "running"}]}`, http.StatusConflict},
+		{"/agents/agent-1/tasks", `{"tasks": [{"id": "task-3", "status": "running"}]}`, http.StatusOK},
+		{"/agents/agent-1/tasks", `{"tasks": [{"id": "task-3", "status": "pending"}]}`, http.StatusConflict},
+		{"/agents/agent-1/heartbeat", ``, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rr := postJSON(mux, tt.path, tt.body); rr.Code != tt.code {
+			t.Errorf("%s %s: expected status code %d, got %d", tt.path, tt.body, tt.code, rr.Code)
+		}
+	}
+}
+
+func TestAgentIngestion_Disabled(t *testing.T) {
+	for _, handler := range []http.Handler{heartbeatHandler(nil, nil), tasksHandler(nil, nil)} {
+		rr := postJSON(handler, "/agents/agent-1/heartbeat", `{}`)
+		if rr.Code != http.StatusNotImplemented {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotImplemented, rr.Code)
+		}
+	}
+}
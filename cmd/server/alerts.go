@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"telemetron/internal/alerting"
+)
+
+// @Summary List alerts
+// @Description Returns the alerts raised by the configured rules. By default these are the active
+// @Description (pending and firing) alerts, oldest first; state=resolved lists recently resolved ones.
+// @Tags alerts
+// @Produce json
+// @Param state query string false "Filter by state: pending, firing or resolved"
+// @Success 200 {array} alerting.Alert
+// @Failure 400 {string} string "Bad request"
+// @Failure 501 {string} string "Alerting is not enabled"
+// @Router /alerts [get]
+func alertsHandler(engine *alerting.Engine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if engine == nil {
+			http.Error(w, "Alerting is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		switch state := r.URL.Query().Get("state"); state {
+		case "":
+			writeJSON(w, http.StatusOK, engine.Active())
+		case alerting.StateResolved:
+			writeJSON(w, http.StatusOK, engine.Resolved())
+		case alerting.StatePending, alerting.StateFiring:
+			alerts := []alerting.Alert{}
+			for _, alert := range engine.Active() {
+				if alert.State == state {
+					alerts = append(alerts, alert)
+				}
+			}
+			writeJSON(w, http.StatusOK, alerts)
+		default:
+			http.Error(w, "Invalid 'state', expected pending, firing or resolved", http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"telemetron/internal/alerting"
+	"telemetron/internal/models"
+	"testing"
+	"time"
+)
+
+type overloadedStateSource struct{}
+
+func (overloadedStateSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	return &models.SystemState{
+		Agents: []models.Agent{{Name: "agent-1", MaxParallelInvocations: 1, Activity: models.Activity{
+			ActiveTaskIDs: []models.TaskStatus{{ID: "task-1", Status: models.TaskRunning}, {ID: "task-2", Status: models.TaskRunning}},
+		}}},
+		LiteLLM: []models.LiteLLM{{Model: "gpt-4", Provider: "openai", TPM: 990, TPMMax: 1000}},
+	}, nil
+}
+
+func TestAlertsHandler(t *testing.T) {
+	rules, err := alerting.ParseRules([]byte(`
+rules:
+  - name: agent-overloaded
+    condition: agent_overload
+  - name: litellm-tpm-high
+    condition: litellm_tpm_usage
+    for: 5m
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := alerting.NewEngine(overloadedStateSource{}, rules, time.Hour)
+	if _, err := engine.Evaluate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	handler := alertsHandler(engine)
+
+	tests := []struct {
+		query string
+		rules []string
+	}{
+		{"", []string{"agent-overloaded", "litellm-tpm-high"}},
+		{"?state=firing", []string{"agent-overloaded"}},
+		{"?state=pending", []string{"litellm-tpm-high"}},
+		{"?state=resolved", []string{}},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/alerts"+tt.query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %d, got %d", tt.query, http.StatusOK, rr.Code)
+		}
+
+		var alerts []alerting.Alert
+		if err := json.Unmarshal(rr.Body.Bytes(), &alerts); err != nil {
+			t.Fatalf("%s: failed to unmarshal response: %v", tt.query, err)
+		}
+		if alerts == nil {
+			t.Errorf("%s: expected an empty array rather than null", tt.query)
+		}
+		got := map[string]bool{}
+		for _, alert := range alerts {
+			got[alert.Rule] = true
+		}
+		if len(alerts) != len(tt.rules) {
+			t.Errorf("%s: expected rules %v, got %+v", tt.query, tt.rules, alerts)
+		}
+		for _, rule := range tt.rules {
+			if !got[rule] {
+				t.Errorf("%s: missing alert for %s", tt.query, rule)
+			}
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/alerts?state=silenced", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for unknown state, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestAlertsHandler_Disabled(t *testing.T) {
+	rr := httptest.NewRecorder()
+	alertsHandler(nil).ServeHTTP(rr, httptest.NewRequest("GET", "/alerts", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotImplemented, rr.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"telemetron/internal/diff"
+	"telemetron/internal/history"
+	"telemetron/internal/models"
+	"telemetron/internal/services"
+	"telemetron/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// @Summary Diff two snapshots
+// @Description Compares the recorded snapshots nearest to from and to, matching agents, workloads,
+// @Description pods, queue tasks and LiteLLM entries by identity. Omitting to (or to=now) compares
+// @Description against the live state. format=patch returns an RFC 6902 JSON Patch instead.
+// @Tags history
+// @Produce json
+// @Param from query string true "Start time (RFC3339)"
+// @Param to query string false "End time (RFC3339) or 'now' (default)"
+// @Param format query string false "json (default) or patch"
+// @Success 200 {object} diff.Diff
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "No snapshot recorded"
+// @Failure 501 {string} string "History is not enabled"
+// @Router /system/diff [get]
+func diffHandler(store *history.Store, systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if store == nil {
+			http.Error(w, "History is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		query := r.URL.Query()
+		format := query.Get("format")
+		if format != "" && format != "json" && format != "patch" {
+			http.Error(w, "Invalid 'format', expected json or patch", http.StatusBadRequest)
+			return
+		}
+
+		if query.Get("from") == "" {
+			http.Error(w, "Missing 'from' timestamp", http.StatusBadRequest)
+			return
+		}
+		from, ok := resolveSnapshot(w, r, store, systemService, query.Get("from"))
+		if !ok {
+			return
+		}
+
+		rawTo := query.Get("to")
+		if rawTo == "" {
+			rawTo = "now"
+		}
+		to, ok := resolveSnapshot(w, r, store, systemService, rawTo)
+		if !ok {
+			return
+		}
+
+		if format == "patch" {
+			ops, err := diff.Patch(from, to)
+			if err != nil {
+				logger.Log.Error("Failed to compute patch", zap.Error(err))
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json-patch+json")
+			writeJSON(w, http.StatusOK, ops)
+			return
+		}
+
+		result, err := diff.Compute(from, to)
+		if err != nil {
+			logger.Log.Error("Failed to compute diff", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// resolveSnapshot loads the state for a diff bound: "now" is the live
+// state, anything else the recorded snapshot nearest to that time. It
+// writes the error response itself and reports whether to continue.
+func resolveSnapshot(w http.ResponseWriter, r *http.Request, store *history.Store, systemService *services.SystemService, raw string) (*models.SystemState, bool) {
+	if raw == "now" {
+		state, err := systemService.GetSystemState(r.Context())
+		if err != nil {
+			logger.Log.Error("Failed to get system state", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return nil, false
+		}
+		return state, true
+	}
+
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		http.Error(w, "Invalid timestamp '"+raw+"', expected RFC3339 or 'now'", http.StatusBadRequest)
+		return nil, false
+	}
+
+	snapshot, err := store.Nearest(at)
+	if errors.Is(err, history.ErrNotFound) {
+		http.Error(w, "No snapshot recorded", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		logger.Log.Error("Failed to read history", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	snapshot.State.CapturedAt = snapshot.CapturedAt
+	return &snapshot.State, true
+}
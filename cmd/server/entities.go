@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"telemetron/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// @Summary List agents
+// @Description Returns every agent from the snapshot cache, as in the agents section of /system/state.
+// @Tags agents
+// @Produce json
+// @Success 200 {array} models.Agent
+// @Failure 500 {string} string "Internal server error"
+// @Router /agents [get]
+func agentsHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agents, err := systemService.GetAgents(r.Context())
+		if lookupFailed(w, r, err, "") {
+			return
+		}
+		writeJSON(w, http.StatusOK, agents)
+	}
+}
+
+// @Summary Get an agent
+// @Tags agents
+// @Produce json
+// @Param name path string true "Agent name"
+// @Success 200 {object} models.Agent
+// @Failure 404 {string} string "Agent not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /agents/{name} [get]
+func agentHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, err := systemService.GetAgent(r.Context(), r.PathValue("name"))
+		if lookupFailed(w, r, err, "Agent not found") {
+			return
+		}
+		writeJSON(w, http.StatusOK, agent)
+	}
+}
+
+// @Summary List an agent's tasks
+// @Description Returns the agent's current tasks and those that finished within the retention period.
+// @Tags agents
+// @Produce json
+// @Param name path string true "Agent name"
+// @Success 200 {array} models.TaskStatus
+// @Failure 404 {string} string "Agent not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /agents/{name}/tasks [get]
+func agentTasksHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, err := systemService.GetAgent(r.Context(), r.PathValue("name"))
+		if lookupFailed(w, r, err, "Agent not found") {
+			return
+		}
+		tasks := agent.Activity.ActiveTaskIDs
+		if tasks == nil {
+			tasks = []models.TaskStatus{}
+		}
+		writeJSON(w, http.StatusOK, tasks)
+	}
+}
+
+// @Summary Get a workload
+// @Description Returns the workload of a deployment with its pods and their utilization.
+// @Tags workloads
+// @Produce json
+// @Param deployment path string true "Deployment name"
+// @Success 200 {object} models.Workload
+// @Failure 404 {string} string "Workload not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /workloads/{deployment} [get]
+func workloadHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workload, err := systemService.GetWorkload(r.Context(), r.PathValue("deployment"))
+		if lookupFailed(w, r, err, "Workload not found") {
+			return
+		}
+		writeJSON(w, http.StatusOK, workload)
+	}
+}
+
+// @Summary Get a pod
+// @Tags workloads
+// @Produce json
+// @Param deployment path string true "Deployment name"
+// @Param id path string true "Pod ID"
+// @Success 200 {object} models.Pod
+// @Failure 404 {string} string "Workload or pod not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /workloads/{deployment}/pods/{id} [get]
+func podHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workload, err := systemService.GetWorkload(r.Context(), r.PathValue("deployment"))
+		if lookupFailed(w, r, err, "Workload not found") {
+			return
+		}
+		for _, pod := range workload.Pods {
+			if pod.PodID == r.PathValue("id") {
+				writeJSON(w, http.StatusOK, pod)
+				return
+			}
+		}
+		http.Error(w, "Pod not found", http.StatusNotFound)
+	}
+}
+
+// @Summary Get a queue
+// @Tags queues
+// @Produce json
+// @Param name path string true "Queue name"
+// @Success 200 {object} models.Queue
+// @Failure 404 {string} string "Queue not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /queues/{name} [get]
+func queueHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		queue, err := systemService.GetQueue(r.Context(), r.PathValue("name"))
+		if lookupFailed(w, r, err, "Queue not found") {
+			return
+		}
+		writeJSON(w, http.StatusOK, queue)
+	}
+}
+
+// @Summary Get a model
+// @Description Returns the model's LiteLLM entries, one per provider serving it. Model names may
+// @Description contain slashes.
+// @Tags models
+// @Produce json
+// @Param model path string true "Model name"
+// @Success 200 {array} models.LiteLLM
+// @Failure 404 {string} string "Model not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /models/{model} [get]
+func modelHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entries, err := systemService.GetModel(r.Context(), r.PathValue("model"))
+		if lookupFailed(w, r, err, "Model not found") {
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+	}
+}
+
+// lookupFailed answers a failed lookup: 404 with notFound when the source
+// has no such entity, nothing when the client went away, and 500 when the
+// source failed. It reports whether err was set.
+func lookupFailed(w http.ResponseWriter, r *http.Request, err error, notFound string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, repositories.ErrNotFound):
+		http.Error(w, notFound, http.StatusNotFound)
+	case r.Context().Err() != nil:
+		// The client went away; there is nobody left to answer.
+	default:
+		logger.Log.Error("Failed to look up entity", zap.String("path", r.URL.Path), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+	return true
+}
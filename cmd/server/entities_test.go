@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"testing"
+	"time"
+)
+
+func newEntityMux(systemService *services.SystemService) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /agents", agentsHandler(systemService))
+	mux.Handle("GET /agents/{name}", agentHandler(systemService))
+	mux.Handle("GET /agents/{name}/tasks", agentTasksHandler(systemService))
+	mux.Handle("GET /workloads/{deployment}", workloadHandler(systemService))
+	mux.Handle("GET /workloads/{deployment}/pods/{id}", podHandler(systemService))
+	mux.Handle("GET /queues/{name}", queueHandler(systemService))
+	mux.Handle("GET /models/{model...}", modelHandler(systemService))
+	mux.Handle("POST /agents/{name}/tasks", tasksHandler(nil, systemService))
+	return mux
+}
+
+func TestEntityHandlers(t *testing.T) {
+	systemService := services.NewSystemService(repositories.NewMockAgentRepository(), repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(), repositories.NewMockLiteLLMRepository())
+	defer systemService.Close()
+	mux := newEntityMux(systemService)
+
+	tests := []struct {
+		path   string
+		status int
+		target interface{}
+	}{
+		{"/agents", http.StatusOK, &[]models.Agent{}},
+		{"/agents/agent-1", http.StatusOK, &models.Agent{}},
+		{"/agents/agent-1/tasks", http.StatusOK, &[]models.TaskStatus{}},
+		{"/workloads/agent-deployment-1", http.StatusOK, &models.Workload{}},
+		{"/workloads/agent-deployment-1/pods/pod-2", http.StatusOK, &models.Pod{}},
+		{"/queues/priority", http.StatusOK, &models.Queue{}},
+		{"/models/gpt-4", http.StatusOK, &[]models.LiteLLM{}},
+		{"/agents/agent-9", http.StatusNotFound, nil},
+		{"/agents/agent-9/tasks", http.StatusNotFound, nil},
+		{"/workloads/missing", http.StatusNotFound, nil},
+		{"/workloads/agent-deployment-1/pods/pod-9", http.StatusNotFound, nil},
+		{"/queues/missing", http.StatusNotFound, nil},
+		{"/models/openai/gpt-5", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+		if rr.Code != tt.status {
+			t.Errorf("GET %s: expected status code %d, got %d: %s", tt.path, tt.status, rr.Code, rr.Body)
+			continue
+		}
+		if tt.target != nil {
+			if err := json.Unmarshal(rr.Body.Bytes(), tt.target); err != nil {
+				t.Errorf("GET %s: failed to unmarshal response: %v", tt.path, err)
+			}
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/agents/agent-1/tasks", nil))
+	var tasks []models.TaskStatus
+	json.Unmarshal(rr.Body.Bytes(), &tasks)
+	if len(tasks) != 2 || tasks[0].ID != "task-1" {
+		t.Errorf("Expected agent-1's tasks, got %+v", tasks)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/workloads/agent-deployment-1/pods/pod-2", nil))
+	var pod models.Pod
+	json.Unmarshal(rr.Body.Bytes(), &pod)
+	if pod.PodID != "pod-2" || pod.Utilization.Memory.Limit != 2<<30 {
+		t.Errorf("Expected pod-2 with its utilization, got %+v", pod)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", "/agents/agent-1/tasks", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("Expected task reports to keep their route, got %d", rr.Code)
+	}
+}
+
+func TestEntityHandlers_SourceFailed(t *testing.T) {
+	systemService := services.NewSystemService(failingAgentRepository{}, failingWorkloadRepository{},
+		slowQueueRepository{}, failingLiteLLMRepository{}, services.WithSourceTimeout(10*time.Millisecond))
+	defer systemService.Close()
+	mux := newEntityMux(systemService)
+
+	for _, path := range []string{"/agents", "/agents/agent-1", "/workloads/agent-deployment-1", "/queues/default", "/models/gpt-4"} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("GET %s: expected status code %d, got %d", path, http.StatusInternalServerError, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"telemetron/internal/graph"
+	"telemetron/internal/services"
+	"telemetron/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// @Summary Get the entity graph
+// @Description Correlates the sections of the current state: agents with their workloads, pods and
+// @Description models, and agent tasks with the queues and queue tasks they came from. References to
+// @Description entities missing from their section, such as a deployment absent from the workload
+// @Description section or a model not configured in LiteLLM, are nodes marked missing and listed
+// @Description in dangling.
+// @Tags system
+// @Produce json
+// @Success 200 {object} graph.Graph
+// @Failure 500 {string} string "Internal server error"
+// @Router /system/graph [get]
+func graphHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := systemService.GetSystemState(r.Context())
+		if err != nil {
+			logger.Log.Error("Failed to get system state", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, graph.Build(state))
+	}
+}
+
+// @Summary Get an agent's correlated view
+// @Description Follows an agent's references in the current state: its workload with the pods'
+// @Description utilization, the rate limit headroom of its models with every provider, and the
+// @Description queued tasks its tasks came from, together with its dangling references.
+// @Tags system
+// @Produce json
+// @Param name path string true "Agent name"
+// @Success 200 {object} graph.AgentView
+// @Failure 404 {string} string "Agent not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /system/graph/agents/{name} [get]
+func agentGraphHandler(systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := systemService.GetSystemState(r.Context())
+		if err != nil {
+			logger.Log.Error("Failed to get system state", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		view, ok := graph.ViewAgent(state, r.PathValue("name"))
+		if !ok {
+			http.Error(w, "Agent not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, view)
+	}
+}
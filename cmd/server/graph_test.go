@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"telemetron/internal/graph"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"testing"
+)
+
+func newGraphMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+	systemService := services.NewSystemService(repositories.NewMockAgentRepository(), repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(), repositories.NewMockLiteLLMRepository())
+	t.Cleanup(systemService.Close)
+
+	mux := http.NewServeMux()
+	mux.Handle("/system/graph", graphHandler(systemService))
+	mux.Handle("/system/graph/agents/{name}", agentGraphHandler(systemService))
+	return mux
+}
+
+func TestGraphHandler(t *testing.T) {
+	mux := newGraphMux(t)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/system/graph", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var g graph.Graph
+	if err := json.Unmarshal(rr.Body.Bytes(), &g); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(g.Nodes) == 0 || len(g.Edges) == 0 {
+		t.Errorf("Expected nodes and edges, got %+v", g)
+	}
+	// The mock agent-2 is deployed as a deployment the mock workload lacks.
+	found := false
+	for _, ref := range g.Dangling {
+		found = found || (ref.From == "agent:agent-2" && ref.To == "workload:agent-deployment-2")
+	}
+	if !found {
+		t.Errorf("Expected agent-2's missing deployment to be dangling, got %+v", g.Dangling)
+	}
+}
+
+func TestAgentGraphHandler(t *testing.T) {
+	mux := newGraphMux(t)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/system/graph/agents/agent-1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var view graph.AgentView
+	if err := json.Unmarshal(rr.Body.Bytes(), &view); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if view.Agent.Name != "agent-1" || view.Workload == nil || len(view.Workload.Pods) == 0 {
+		t.Errorf("Expected agent-1 with its workload's pods, got %+v", view)
+	}
+	if len(view.Models) == 0 || len(view.Models[0].Providers) == 0 || view.Models[0].Providers[0].TPM.Limit == 0 {
+		t.Errorf("Expected agent-1's models with their rate limits, got %+v", view.Models)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/system/graph/agents/agent-9", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for an unknown agent, got %d", http.StatusNotFound, rr.Code)
+	}
+}
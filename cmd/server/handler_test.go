@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"telemetron/internal/models"
 	"telemetron/internal/repositories"
 	"telemetron/internal/services"
 	"testing"
+	"time"
 )
 
 func TestSystemStateHandler_Success(t *testing.T) {
@@ -150,3 +153,166 @@ func TestHealthCheckHandler(t *testing.T) {
 		t.Errorf("Expected body '%s', got '%s'", expected, rr.Body.String())
 	}
 }
+
+type slowQueueRepository struct{}
+
+func (slowQueueRepository) GetAll(ctx context.Context) ([]models.Queue, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowQueueRepository) Get(ctx context.Context, name string) (*models.Queue, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowQueueRepository) Close() {}
+
+func TestSystemStateHandler_PartialSnapshot(t *testing.T) {
+	systemService := services.NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		slowQueueRepository{},
+		repositories.NewMockLiteLLMRepository(),
+		services.WithSourceTimeout(10*time.Millisecond),
+	)
+	defer systemService.Close()
+
+	req := httptest.NewRequest("GET", "/system/state", nil)
+	rr := httptest.NewRecorder()
+
+	systemStateHandler(systemService).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var state models.SystemState
+	if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(state.Agents) == 0 {
+		t.Error("Expected agents despite the queue source failing")
+	}
+
+	queues := state.Status["queues"]
+	if queues.Status != models.SectionError || queues.Error == "" {
+		t.Errorf("Expected queues section in error, got %+v", queues)
+	}
+
+	if state.Status["agents"].Status != models.SectionOK {
+		t.Errorf("Expected agents section ok, got %+v", state.Status["agents"])
+	}
+}
+
+func TestSystemStateHandler_AllSectionsFailed(t *testing.T) {
+	systemService := services.NewSystemService(
+		failingAgentRepository{},
+		failingWorkloadRepository{},
+		slowQueueRepository{},
+		failingLiteLLMRepository{},
+		services.WithSourceTimeout(10*time.Millisecond),
+	)
+	defer systemService.Close()
+
+	req := httptest.NewRequest("GET", "/system/state", nil)
+	rr := httptest.NewRecorder()
+
+	systemStateHandler(systemService).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+
+	var state models.SystemState
+	if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil {
+		t.Fatalf("Expected JSON body describing the failures: %v", err)
+	}
+
+	if len(state.Status) != 4 {
+		t.Errorf("Expected status for 4 sections, got %v", state.Status)
+	}
+}
+
+func TestSystemStateHandler_Query(t *testing.T) {
+	systemService := services.NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(),
+		repositories.NewMockLiteLLMRepository(),
+	)
+	defer systemService.Close()
+	handler := systemStateHandler(systemService)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?include=agents&agent=agent-1&task_status=running", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if _, ok := doc["workload"]; ok {
+		t.Error("Expected sections not included to be dropped")
+	}
+	var agents []models.Agent
+	json.Unmarshal(doc["agents"], &agents)
+	if len(agents) != 1 || agents[0].Name != "agent-1" || len(agents[0].Activity.ActiveTaskIDs) != 1 {
+		t.Errorf("Expected agent-1 with its running task only, got %+v", agents)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?provider=anthropic&query=litellm[].model", nil))
+	var names []string
+	if err := json.Unmarshal(rr.Body.Bytes(), &names); err != nil || len(names) != 1 || names[0] != "claude-3-opus" {
+		t.Errorf("Expected the anthropic model's name, got %s", rr.Body.String())
+	}
+
+	for _, raw := range []string{"include=pods", "task_status=done", "query=agents[", "query=abs(agents)"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?"+raw, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, raw, rr.Code)
+		}
+	}
+}
+
+var errBackendDown = errors.New("backend down")
+
+type failingAgentRepository struct{}
+
+func (failingAgentRepository) GetAll(context.Context) ([]models.Agent, error) {
+	return nil, errBackendDown
+}
+
+func (failingAgentRepository) Get(context.Context, string) (*models.Agent, error) {
+	return nil, errBackendDown
+}
+
+func (failingAgentRepository) Close() {}
+
+type failingWorkloadRepository struct{}
+
+func (failingWorkloadRepository) GetAll(context.Context) ([]models.Workload, error) {
+	return nil, errBackendDown
+}
+
+func (failingWorkloadRepository) Get(context.Context, string) (*models.Workload, error) {
+	return nil, errBackendDown
+}
+
+func (failingWorkloadRepository) Close() {}
+
+type failingLiteLLMRepository struct{}
+
+func (failingLiteLLMRepository) GetAll(context.Context) ([]models.LiteLLM, error) {
+	return nil, errBackendDown
+}
+
+func (failingLiteLLMRepository) Get(context.Context, string) ([]models.LiteLLM, error) {
+	return nil, errBackendDown
+}
+
+func (failingLiteLLMRepository) Close() {}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"telemetron/internal/consistency"
+	"telemetron/internal/history"
+	"telemetron/internal/services"
+)
+
+// @Summary Check the system state's invariants
+// @Description Runs the consistency checks on the current state, or with at on the recorded snapshot
+// @Description nearest to that time, and lists every violation with its severity and the graph IDs of
+// @Description the offending entities. The report is healthy when no check found an error; checks
+// @Description that need a section in error are skipped.
+// @Tags system
+// @Produce json
+// @Param at query string false "Point in time (RFC3339) to read from history"
+// @Success 200 {object} consistency.Report
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "No snapshot recorded"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 501 {string} string "History is not enabled"
+// @Router /system/health-report [get]
+func healthReportHandler(store *history.Store, systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		at := r.URL.Query().Get("at")
+		if at == "" {
+			at = "now"
+		}
+		if at != "now" && store == nil {
+			http.Error(w, "History is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		state, ok := resolveSnapshot(w, r, store, systemService, at)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, consistency.Check(state))
+	}
+}
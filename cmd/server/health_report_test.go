@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"telemetron/internal/consistency"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"testing"
+	"time"
+)
+
+func TestHealthReportHandler(t *testing.T) {
+	systemService := services.NewSystemService(repositories.NewMockAgentRepository(), repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(), repositories.NewMockLiteLLMRepository())
+	defer systemService.Close()
+	handler := healthReportHandler(newTestHistory(t), systemService)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/health-report", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var report consistency.Report
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(report.Checks) == 0 {
+		t.Errorf("Expected the checks that ran, got %+v", report)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/health-report?at=2026-02-06T10:10:00Z", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if !report.CapturedAt.Equal(time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the report of the nearest snapshot, got one captured at %v", report.CapturedAt)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/health-report?at=yesterday", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid time, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	healthReportHandler(nil, systemService).ServeHTTP(rr, httptest.NewRequest("GET", "/system/health-report?at=2026-02-06T10:10:00Z", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status code %d without history, got %d", http.StatusNotImplemented, rr.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"telemetron/internal/history"
+	"telemetron/internal/query"
+	"telemetron/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultHistoryLimit = 100
+
+// stateAtHandler serves /system/state?at=<RFC3339> from the history store
+// and delegates every other request to live.
+func stateAtHandler(store *history.Store, live http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw := r.URL.Query().Get("at")
+		if raw == "" {
+			live.ServeHTTP(w, r)
+			return
+		}
+
+		if store == nil {
+			http.Error(w, "History is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		q, err := query.Parse(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		at, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, "Invalid 'at' timestamp, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+
+		snapshot, err := store.Nearest(at)
+		if errors.Is(err, history.ErrNotFound) {
+			http.Error(w, "No snapshot recorded", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Log.Error("Failed to read history", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		snapshot.State.CapturedAt = snapshot.CapturedAt
+		writeQueried(w, http.StatusOK, q, &snapshot.State)
+	}
+}
+
+// @Summary List historical snapshots
+// @Description Returns snapshots recorded between from and to (RFC3339), oldest first.
+// @Tags history
+// @Produce json
+// @Param from query string false "Start time (RFC3339), defaults to the oldest snapshot"
+// @Param to query string false "End time (RFC3339), defaults to now"
+// @Param limit query int false "Maximum snapshots to return (default 100, 0 for all)"
+// @Success 200 {array} history.Snapshot
+// @Failure 400 {string} string "Bad request"
+// @Failure 501 {string} string "History is not enabled"
+// @Router /system/history [get]
+func historyHandler(store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if store == nil {
+			http.Error(w, "History is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		query := r.URL.Query()
+		from, err := parseTimeParam(query.Get("from"), time.Time{})
+		if err != nil {
+			http.Error(w, "Invalid 'from' timestamp, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+		to, err := parseTimeParam(query.Get("to"), time.Now())
+		if err != nil {
+			http.Error(w, "Invalid 'to' timestamp, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultHistoryLimit
+		if raw := query.Get("limit"); raw != "" {
+			if limit, err = strconv.Atoi(raw); err != nil || limit < 0 {
+				http.Error(w, "Invalid 'limit', expected a non-negative integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		snapshots, err := store.Range(from, to, limit)
+		if err != nil {
+			logger.Log.Error("Failed to read history", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, snapshots)
+	}
+}
+
+func parseTimeParam(raw string, fallback time.Time) (time.Time, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
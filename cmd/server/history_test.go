@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"telemetron/internal/diff"
+	"telemetron/internal/history"
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"testing"
+	"time"
+)
+
+func newTestHistory(t *testing.T) *history.Store {
+	t.Helper()
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	base := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	store.Save(base, &models.SystemState{ID: "system-1", Agents: []models.Agent{{Name: "early"}}})
+	store.Save(base.Add(time.Hour), &models.SystemState{ID: "system-1", Agents: []models.Agent{{Name: "late"}}})
+	return store
+}
+
+func TestStateAtHandler(t *testing.T) {
+	live := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("live"))
+	})
+	handler := stateAtHandler(newTestHistory(t), live)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state", nil))
+	if rr.Body.String() != "live" {
+		t.Errorf("Expected request without 'at' to be served live, got %q", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?at=2026-02-06T10:50:00Z", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var state models.SystemState
+	if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if state.Agents[0].Name != "late" || !state.CapturedAt.Equal(time.Date(2026, 2, 6, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected nearest snapshot at 11:00, got %+v", state)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?at=2026-02-06T10:50:00Z&query=agents[].name", nil))
+	if body := strings.TrimSpace(rr.Body.String()); body != `["late"]` {
+		t.Errorf("Expected the query to run on the recorded snapshot, got %s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?at=yesterday", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for bad timestamp, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestStateAtHandler_HistoryDisabled(t *testing.T) {
+	handler := stateAtHandler(nil, http.NotFoundHandler())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/state?at=2026-02-06T10:00:00Z", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotImplemented, rr.Code)
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	handler := historyHandler(newTestHistory(t))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/history?from=2026-02-06T09:00:00Z&to=2026-02-06T10:30:00Z", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var snapshots []history.Snapshot
+	if err := json.Unmarshal(rr.Body.Bytes(), &snapshots); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].State.Agents[0].Name != "early" {
+		t.Errorf("Expected only the early snapshot, got %+v", snapshots)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/history?limit=-1", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for bad limit, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	systemService := services.NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(),
+		repositories.NewMockLiteLLMRepository(),
+	)
+	defer systemService.Close()
+
+	handler := diffHandler(newTestHistory(t), systemService)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/diff?from=2026-02-06T10:00:00Z&to=2026-02-06T11:00:00Z", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var result diff.Diff
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result.Changes) != 2 || !result.From.Equal(time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected early agent removed and late agent added, got %+v", result)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/diff?from=2026-02-06T10:00:00Z&format=patch", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json-patch+json" {
+		t.Errorf("Expected JSON Patch content type, got %s", ct)
+	}
+
+	var ops []map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &ops); err != nil || len(ops) == 0 {
+		t.Errorf("Expected patch operations against the live state, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/system/diff", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without 'from', got %d", http.StatusBadRequest, rr.Code)
+	}
+}
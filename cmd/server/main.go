@@ -7,39 +7,89 @@ package main
 // @BasePath /
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
+	"os"
 	_ "telemetron/docs" // Import generated docs
+	"telemetron/internal/alerting"
+	"telemetron/internal/history"
+	"telemetron/internal/metrics"
+	"telemetron/internal/models"
+	"telemetron/internal/query"
 	"telemetron/internal/repositories"
 	"telemetron/internal/services"
+	"telemetron/internal/stream"
+	"telemetron/internal/webhooks"
 	"telemetron/pkg/config"
 	"telemetron/pkg/logger"
+	"time"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 )
 
 // @Summary Get system state
-// @Description Returns the current state of agents, workloads, queues, and LiteLLM models
+// @Description Returns the current state of agents, workloads, queues, and LiteLLM models.
+// @Description Sections whose source failed are reported in the status block; the
+// @Description response is 503 only when every section failed.
+// @Description With at, returns the recorded snapshot nearest to that time instead.
+// @Description include, the entity filters and query narrow the response; filters take comma
+// @Description separated values, and query is a JMESPath expression evaluated on the filtered
+// @Description snapshot whose result is returned as is.
 // @Tags system
 // @Produce json
+// @Param at query string false "Point in time (RFC3339) to read from history"
+// @Param include query string false "Sections to return: agents, workload, queues, litellm"
+// @Param agent query string false "Agent names to keep"
+// @Param deployment query string false "Deployments whose workloads and agents to keep"
+// @Param task_status query string false "Task states to keep; agents left without tasks are dropped"
+// @Param provider query string false "LiteLLM providers to keep"
+// @Param query query string false "JMESPath expression, e.g. agents[].name"
 // @Success 200 {object} models.SystemState
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "No snapshot recorded"
 // @Failure 500 {string} string "Internal server error"
+// @Failure 501 {string} string "History is not enabled"
+// @Failure 503 {object} models.SystemState
 // @Router /system/state [get]
 func systemStateHandler(systemService *services.SystemService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		state, err := systemService.GetSystemState()
+		q, err := query.Parse(r.URL.Query())
 		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		state, err := systemService.GetSystemState(r.Context())
+		if err != nil {
+			if r.Context().Err() != nil {
+				// The client went away; there is nobody left to answer.
+				return
+			}
 			logger.Log.Error("Failed to get system state", zap.Error(err))
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(state); err != nil {
-			logger.Log.Error("Failed to encode response", zap.Error(err))
+		available := false
+		for section, status := range state.Status {
+			if status.Status != models.SectionError {
+				available = true
+			}
+			if status.Status != models.SectionOK {
+				logger.Log.Warn("System state section unavailable",
+					zap.String("section", section),
+					zap.String("status", status.Status),
+					zap.String("error", status.Error))
+			}
 		}
+
+		status := http.StatusOK
+		if !available {
+			status = http.StatusServiceUnavailable
+		}
+		writeQueried(w, status, q, state)
 	}
 }
 
@@ -50,19 +100,138 @@ func main() {
 		panic(fmt.Sprintf("Failed to initialize logger: %v", err))
 	}
 	defer logger.Close()
+	if err := cfg.Validate(); err != nil {
+		logger.Log.Fatal("Invalid configuration", zap.Error(err))
+	}
 
 	// Initialize repositories
-	agentRepo := repositories.NewMockAgentRepository()
-	workloadRepo := repositories.NewMockWorkloadRepository()
-	queueRepo := repositories.NewMockQueueRepository()
-	llmRepo := repositories.NewMockLiteLLMRepository()
+	registry := repositories.NewRegistry()
+	traceReceiver, err := newTraceReceiver(cfg, registry)
+	if err != nil {
+		logger.Log.Fatal("Failed to load OTLP mapping", zap.Error(err))
+	}
+	repos, err := registry.Build(cfg)
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize repositories", zap.Error(err))
+	}
 
 	// Initialize service
-	systemService := services.NewSystemService(agentRepo, workloadRepo, queueRepo, llmRepo)
+	systemService := services.NewSystemService(
+		repos.Agents, repos.Workloads, repos.Queues, repos.LiteLLM,
+		services.WithCacheTTL(time.Duration(cfg.CacheTTL)*time.Second),
+		services.WithSourceTimeout(time.Duration(cfg.SourceTimeout)*time.Second),
+	)
 	defer systemService.Close()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Initialize history
+	var historyStore *history.Store
+	if cfg.HistoryPath != "" {
+		historyStore, err = history.Open(cfg.HistoryPath)
+		if err != nil {
+			logger.Log.Fatal("Failed to open history store", zap.Error(err))
+		}
+		defer historyStore.Close()
+
+		snapshotter := history.NewSnapshotter(systemService, historyStore,
+			time.Duration(cfg.HistoryInterval)*time.Second,
+			history.Retention{
+				MaxAge:       time.Duration(cfg.HistoryRetention) * time.Hour,
+				MaxSnapshots: cfg.HistoryMaxSnapshots,
+			})
+		go snapshotter.Run(ctx)
+	}
+
+	// Initialize change stream. While someone is listening (an SSE client or
+	// the webhook dispatcher) it polls with the cache's max age capped at the
+	// stream interval so that events are not held back by CACHE_TTL; idle
+	// polls read through the cache to spare the backends.
+	streamInterval := time.Duration(cfg.StreamInterval) * time.Second
+	var broadcaster *stream.Broadcaster
+	streamSource := stream.StateSourceFunc(func(ctx context.Context) (*models.SystemState, error) {
+		if broadcaster.Subscribers() > 0 {
+			return systemService.GetSystemStateWithin(ctx, streamInterval)
+		}
+		return systemService.GetSystemState(ctx)
+	})
+	broadcaster = stream.NewBroadcaster(streamSource, streamInterval, cfg.StreamBuffer)
+	go broadcaster.Run(ctx)
+
+	// Initialize webhooks
+	var dispatcher *webhooks.Dispatcher
+	if cfg.WebhooksPath != "" {
+		subscriptions, err := webhooks.LoadSubscriptions(cfg.WebhooksPath)
+		if err != nil {
+			logger.Log.Fatal("Failed to load webhook subscriptions", zap.Error(err))
+		}
+
+		opts := []webhooks.Option{
+			webhooks.WithMaxAttempts(cfg.WebhookMaxAttempts),
+			webhooks.WithBackoff(time.Duration(cfg.WebhookBackoff) * time.Second),
+			webhooks.WithTimeout(time.Duration(cfg.WebhookTimeout) * time.Second),
+		}
+		if cfg.WebhookDeadLetterPath != "" {
+			deadLetters, err := os.OpenFile(cfg.WebhookDeadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+			if err != nil {
+				logger.Log.Fatal("Failed to open webhook dead-letter log", zap.Error(err))
+			}
+			defer deadLetters.Close()
+			opts = append(opts, webhooks.WithDeadLetterLog(deadLetters))
+		}
+
+		dispatcher = webhooks.NewDispatcher(subscriptions, opts...)
+		go dispatcher.Run(ctx)
+		go dispatcher.Watch(ctx, broadcaster)
+	}
+
+	// Initialize alerting
+	var alertEngine *alerting.Engine
+	if cfg.AlertRulesPath != "" {
+		rules, err := alerting.LoadRules(cfg.AlertRulesPath)
+		if err != nil {
+			logger.Log.Fatal("Failed to load alert rules", zap.Error(err))
+		}
+
+		var opts []alerting.Option
+		if dispatcher != nil {
+			opts = append(opts, alerting.WithNotifier(dispatcher.NotifyAlerts))
+		}
+		alertEngine = alerting.NewEngine(systemService, rules,
+			time.Duration(cfg.AlertInterval)*time.Second, opts...)
+		go alertEngine.Run(ctx)
+	}
+
 	// Setup handlers
-	http.HandleFunc("/system/state", systemStateHandler(systemService))
+	exporter := metrics.New(systemService, time.Duration(cfg.SourceTimeout)*time.Second)
+	route := func(pattern string, handler http.Handler) {
+		http.Handle(pattern, exporter.Instrument(pattern, handler))
+	}
+
+	route("/system/state", stateAtHandler(historyStore, systemStateHandler(systemService)))
+	route("/system/history", historyHandler(historyStore))
+	route("/system/diff", diffHandler(historyStore, systemService))
+	route("/system/graph", graphHandler(systemService))
+	route("/system/graph/agents/{name}", agentGraphHandler(systemService))
+	route("/system/health-report", healthReportHandler(historyStore, systemService))
+	route("/system/stream", streamHandler(broadcaster, time.Duration(cfg.StreamHeartbeat)*time.Second))
+	route("GET /agents", agentsHandler(systemService))
+	route("GET /agents/{name}", agentHandler(systemService))
+	route("GET /agents/{name}/tasks", agentTasksHandler(systemService))
+	route("GET /workloads/{deployment}", workloadHandler(systemService))
+	route("GET /workloads/{deployment}/pods/{id}", podHandler(systemService))
+	route("GET /queues/{name}", queueHandler(systemService))
+	route("GET /models/{model...}", modelHandler(systemService))
+	route("/alerts", alertsHandler(alertEngine))
+	route("/admin/webhooks/deliveries", deliveriesHandler(dispatcher))
+
+	// Agents report themselves only with AGENT_BACKEND=push.
+	pushAgents, _ := repos.Agents.(*repositories.PushAgentRepository)
+	route("POST /agents/{name}/heartbeat", heartbeatHandler(pushAgents, systemService))
+	route("POST /agents/{name}/tasks", tasksHandler(pushAgents, systemService))
+	route("POST /v1/traces", tracesHandler(traceReceiver, systemService))
+	http.Handle("/metrics", exporter.Handler())
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Telemetron API - visit /system/state or /swagger/"))
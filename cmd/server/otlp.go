@@ -0,0 +1,183 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"telemetron/internal/models"
+	"telemetron/internal/otlp"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"telemetron/pkg/config"
+	"telemetron/pkg/logger"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLPBackend is the backend name of the agents and LiteLLM sections
+// derived from traces.
+const OTLPBackend = "otlp"
+
+// maxTraceBytes bounds the decompressed body of a trace export.
+const maxTraceBytes = 8 << 20
+
+// newTraceReceiver returns the receiver feeding the sections whose backend
+// is otlp, registered with registry, or nil when no section uses it.
+func newTraceReceiver(cfg *config.Config, registry *repositories.Registry) (*otlp.Receiver, error) {
+	if cfg.AgentBackend != OTLPBackend && cfg.LiteLLMBackend != OTLPBackend {
+		return nil, nil
+	}
+
+	mapping := otlp.DefaultMapping()
+	if cfg.OTLPMappingPath != "" {
+		var err error
+		if mapping, err = otlp.LoadMapping(cfg.OTLPMappingPath); err != nil {
+			return nil, err
+		}
+	}
+	receiver := otlp.NewReceiver(mapping,
+		time.Duration(cfg.AgentStaleAfter)*time.Second, time.Duration(cfg.AgentTaskRetention)*time.Second,
+		time.Duration(cfg.OTLPEvictAfter)*time.Second)
+
+	registry.Agents.Register(OTLPBackend, func(*config.Config) (repositories.AgentRepository, error) {
+		return receiver.AgentRepository(), nil
+	})
+	registry.LiteLLM.Register(OTLPBackend, func(*config.Config) (repositories.LiteLLMRepository, error) {
+		return receiver.LiteLLMRepository(), nil
+	})
+	return receiver, nil
+}
+
+// @Summary Receive OpenTelemetry traces
+// @Description OTLP/HTTP trace endpoint, in binary protobuf (application/x-protobuf) or JSON
+// @Description (application/json), optionally gzip-encoded. Agent, task and model call spans are
+// @Description recognized by the attributes in OTLP_MAPPING_PATH and feed the agents and litellm
+// @Description sections whose backend is otlp. The response uses the request's encoding.
+// @Tags agents
+// @Accept application/x-protobuf,json
+// @Produce application/x-protobuf,json
+// @Success 200 {object} object "ExportTraceServiceResponse"
+// @Failure 400 {string} string "Bad request"
+// @Failure 413 {string} string "Export too large"
+// @Failure 415 {string} string "Unsupported content type"
+// @Failure 501 {string} string "OTLP receiver is not enabled"
+// @Router /v1/traces [post]
+func tracesHandler(receiver *otlp.Receiver, systemService *services.SystemService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if receiver == nil {
+			http.Error(w, "OTLP receiver is not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType != "application/x-protobuf" && contentType != "application/json" {
+			http.Error(w, "Unsupported content type, expected application/x-protobuf or application/json",
+				http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body := io.Reader(r.Body)
+		switch r.Header.Get("Content-Encoding") {
+		case "", "identity":
+		case "gzip":
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid gzip body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			body = gz
+		default:
+			http.Error(w, "Unsupported content encoding", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(body, maxTraceBytes+1))
+		if err != nil {
+			http.Error(w, "Failed to read body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(data) > maxTraceBytes {
+			http.Error(w, "Export too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var export coltracepb.ExportTraceServiceRequest
+		if contentType == "application/json" {
+			err = unmarshalTracesJSON(data, &export)
+		} else {
+			err = proto.Unmarshal(data, &export)
+		}
+		if err != nil {
+			http.Error(w, "Invalid trace export: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		receiver.Export(&export)
+		systemService.Invalidate(models.SectionAgents)
+		systemService.Invalidate(models.SectionLiteLLM)
+
+		var response []byte
+		if contentType == "application/json" {
+			response, err = protojson.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		} else {
+			response, err = proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		}
+		if err != nil {
+			logger.Log.Error("Failed to encode OTLP response", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(response)
+	}
+}
+
+// unmarshalTracesJSON decodes the OTLP JSON encoding. It differs from
+// protojson in that trace and span IDs are hex rather than base64, so the
+// IDs protojson read as base64 are turned back into their hex text and
+// decoded from that.
+func unmarshalTracesJSON(data []byte, export *coltracepb.ExportTraceServiceRequest) error {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, export); err != nil {
+		return err
+	}
+
+	fromHex := func(id *[]byte) error {
+		if len(*id) == 0 {
+			return nil
+		}
+		decoded, err := hex.DecodeString(base64.StdEncoding.EncodeToString(*id))
+		if err != nil {
+			return fmt.Errorf("invalid trace or span id: %w", err)
+		}
+		*id = decoded
+		return nil
+	}
+	for _, resourceSpans := range export.GetResourceSpans() {
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				for _, id := range []*[]byte{&span.TraceId, &span.SpanId, &span.ParentSpanId} {
+					if err := fromHex(id); err != nil {
+						return err
+					}
+				}
+				for _, link := range span.GetLinks() {
+					for _, id := range []*[]byte{&link.TraceId, &link.SpanId} {
+						if err := fromHex(id); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
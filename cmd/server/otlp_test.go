@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"telemetron/internal/models"
+	"telemetron/internal/otlp"
+	"telemetron/internal/repositories"
+	"telemetron/internal/services"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTracesMux(t *testing.T) (*http.ServeMux, *services.SystemService) {
+	t.Helper()
+	receiver := otlp.NewReceiver(otlp.DefaultMapping(), time.Minute, time.Minute, time.Hour)
+	systemService := services.NewSystemService(receiver.AgentRepository(), repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(), receiver.LiteLLMRepository(),
+		services.WithCacheTTL(time.Hour))
+	t.Cleanup(systemService.Close)
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /v1/traces", tracesHandler(receiver, systemService))
+	return mux, systemService
+}
+
+func postTraces(mux http.Handler, contentType, encoding string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/v1/traces", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestTracesHandler_Protobuf(t *testing.T) {
+	mux, systemService := newTracesMux(t)
+
+	// Prime the cache so the export below must invalidate it.
+	if state, _ := systemService.GetSystemState(context.Background()); len(state.Agents) != 0 {
+		t.Fatalf("Expected no agents before the first export, got %+v", state.Agents)
+	}
+
+	data, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+				Key: "gen_ai.agent.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "agent-7"}},
+			}}},
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+				TraceId:         bytes.Repeat([]byte{0xab}, 16),
+				EndTimeUnixNano: uint64(time.Now().UnixNano()),
+				Attributes: []*commonpb.KeyValue{
+					{Key: "gen_ai.operation.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "chat"}}},
+					{Key: "gen_ai.request.model", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "gpt-4"}}},
+					{Key: "gen_ai.usage.input_tokens", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 42}}},
+				},
+			}}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write(data)
+	gz.Close()
+
+	rr := postTraces(mux, "application/x-protobuf", "gzip", gzipped.Bytes())
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Errorf("Expected a protobuf response, got %q", ct)
+	}
+	var response coltracepb.ExportTraceServiceResponse
+	if err := proto.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	state, err := systemService.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Agents) != 1 || state.Agents[0].Name != "agent-7" {
+		t.Fatalf("Expected agent-7 in the system state, got %+v", state.Agents)
+	}
+	tasks := state.Agents[0].Activity.ActiveTaskIDs
+	if len(tasks) != 1 || tasks[0].ID != strings.Repeat("ab", 16) || tasks[0].Status != models.TaskRunning {
+		t.Errorf("Expected the trace to be a running task, got %+v", tasks)
+	}
+	if len(state.LiteLLM) != 1 || state.LiteLLM[0].Model != "gpt-4" || state.LiteLLM[0].TPM != 42 {
+		t.Errorf("Expected gpt-4 usage in the system state, got %+v", state.LiteLLM)
+	}
+}
+
+func TestTracesHandler_JSON(t *testing.T) {
+	mux, systemService := newTracesMux(t)
+
+	body := `{"resourceSpans": [{
+		"resource": {"attributes": [{"key": "gen_ai.agent.name", "value": {"stringValue": "agent-7"}}]},
+		"scopeSpans": [{"spans": [{
+			"traceId": "5b8efff798038103d269b633813fc60c",
+			"spanId": "eee19b7ec3c1b174",
+			"name": "invoke_agent",
+			"status": {"code": 2},
+			"attributes": [{"key": "gen_ai.operation.name", "value": {"stringValue": "invoke_agent"}}]
+		}]}]
+	}]}`
+	rr := postTraces(mux, "application/json", "", []byte(body))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON response, got %q", ct)
+	}
+
+	state, err := systemService.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Agents) != 1 {
+		t.Fatalf("Expected one agent, got %+v", state.Agents)
+	}
+	tasks := state.Agents[0].Activity.ActiveTaskIDs
+	if len(tasks) != 1 || tasks[0].ID != "5b8efff798038103d269b633813fc60c" || tasks[0].Status != models.TaskFailed {
+		t.Errorf("Expected the trace to be a failed task keyed by its hex ID, got %+v", tasks)
+	}
+}
+
+func TestTracesHandler_Errors(t *testing.T) {
+	mux, _ := newTracesMux(t)
+
+	if rr := postTraces(mux, "text/plain", "", []byte("spans")); rr.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status code %d for text/plain, got %d", http.StatusUnsupportedMediaType, rr.Code)
+	}
+	if rr := postTraces(mux, "application/x-protobuf", "", []byte{0xff, 0xff}); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid protobuf, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := postTraces(mux, "application/json", "", []byte(`{"resourceSpans": [{"scopeSpans": [{"spans": [{"traceId": "zz"}]}]}]}`)); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid trace ID, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr := httptest.NewRecorder()
+	tracesHandler(nil, nil).ServeHTTP(rr, httptest.NewRequest("POST", "/v1/traces", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status code %d without a receiver, got %d", http.StatusNotImplemented, rr.Code)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"telemetron/internal/models"
+	"telemetron/internal/query"
+	"telemetron/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// writeJSON encodes v as the response body with the given status code.
+// A Content-Type already set by the caller is kept.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Log.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// writeQueried writes the part of state that q selects. Expressions that
+// fail on the snapshot, such as a function given the wrong type, are the
+// client's error.
+func writeQueried(w http.ResponseWriter, status int, q query.Query, state *models.SystemState) {
+	body, err := q.Apply(state)
+	if errors.Is(err, query.ErrInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Failed to apply query", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, body)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"telemetron/internal/stream"
+	"telemetron/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// @Summary Stream system state changes
+// @Description Server-Sent Events stream. Sends a "state" event with the full snapshot, then change
+// @Description events such as task.status, task.failed, queue.depth, pod.added or agent.removed. Reconnecting with
+// @Description Last-Event-ID replays the missed events when they are still buffered.
+// @Tags system
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 500 {string} string "Streaming unsupported"
+// @Router /system/stream [get]
+func streamHandler(broadcaster *stream.Broadcaster, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+
+		replay, sub := broadcaster.Subscribe(lastEventID)
+		defer broadcaster.Unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		for _, event := range replay {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case <-r.Context().Done():
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event stream.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		logger.Log.Error("Failed to encode stream event", zap.Error(err))
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"telemetron/internal/models"
+	"telemetron/internal/stream"
+	"testing"
+	"time"
+)
+
+type staticStateSource struct{}
+
+func (staticStateSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	return &models.SystemState{ID: "system-1"}, nil
+}
+
+func TestStreamHandler(t *testing.T) {
+	broadcaster := stream.NewBroadcaster(staticStateSource{}, time.Hour, 10)
+	broadcaster.Poll(context.Background())
+
+	server := httptest.NewServer(streamHandler(broadcaster, 20*time.Millisecond))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 5 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	if lines[0] != "id: 0" || lines[1] != "event: state" || !strings.HasPrefix(lines[2], `data: {"id":"system-1"`) {
+		t.Errorf("Expected initial state event, got %q", lines[:3])
+	}
+
+	if lines[4] != ": heartbeat" {
+		t.Errorf("Expected heartbeat after the state event, got %q", lines[4])
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"telemetron/internal/webhooks"
+)
+
+const defaultDeliveryLimit = 100
+
+// @Summary List webhook deliveries
+// @Description Returns recent webhook deliveries, most recent first, with their attempts, last response and payload.
+// @Tags admin
+// @Produce json
+// @Param subscription query string false "Only deliveries to this subscription"
+// @Param status query string false "Filter by status: pending, retrying, delivered or dead"
+// @Param limit query int false "Maximum deliveries to return (default 100, 0 for all)"
+// @Success 200 {array} webhooks.Delivery
+// @Failure 400 {string} string "Bad request"
+// @Failure 501 {string} string "Webhooks are not enabled"
+// @Router /admin/webhooks/deliveries [get]
+func deliveriesHandler(dispatcher *webhooks.Dispatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dispatcher == nil {
+			http.Error(w, "Webhooks are not enabled", http.StatusNotImplemented)
+			return
+		}
+
+		query := r.URL.Query()
+		status := query.Get("status")
+		switch status {
+		case "", webhooks.StatusPending, webhooks.StatusRetrying, webhooks.StatusDelivered, webhooks.StatusDead:
+		default:
+			http.Error(w, "Invalid 'status', expected pending, retrying, delivered or dead", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultDeliveryLimit
+		if raw := query.Get("limit"); raw != "" {
+			var err error
+			if limit, err = strconv.Atoi(raw); err != nil || limit < 0 {
+				http.Error(w, "Invalid 'limit', expected a non-negative integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, dispatcher.Deliveries(query.Get("subscription"), status, limit))
+	}
+}
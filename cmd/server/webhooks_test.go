@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"telemetron/internal/webhooks"
+	"testing"
+	"time"
+)
+
+func TestDeliveriesHandler(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	dispatcher := webhooks.NewDispatcher([]webhooks.Subscription{
+		{Name: "ops", URL: receiver.URL},
+		{Name: "audit", URL: receiver.URL},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dispatcher.Run(ctx)
+
+	dispatcher.Publish("task.failed", map[string]string{"task_id": "task-1"})
+	deadline := time.Now().Add(2 * time.Second)
+	for len(dispatcher.Deliveries("", webhooks.StatusDelivered, 0)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	handler := deliveriesHandler(dispatcher)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/admin/webhooks/deliveries?subscription=ops&status=delivered", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var deliveries []webhooks.Delivery
+	if err := json.Unmarshal(rr.Body.Bytes(), &deliveries); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Subscription != "ops" || deliveries[0].Event != "task.failed" {
+		t.Errorf("Expected one delivered task.failed to ops, got %+v", deliveries)
+	}
+
+	for _, query := range []string{"?status=lost", "?limit=-1"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/admin/webhooks/deliveries"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+func TestDeliveriesHandler_Disabled(t *testing.T) {
+	rr := httptest.NewRecorder()
+	deliveriesHandler(nil).ServeHTTP(rr, httptest.NewRequest("GET", "/admin/webhooks/deliveries", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotImplemented, rr.Code)
+	}
+}
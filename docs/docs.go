@@ -15,25 +15,1276 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/webhooks/deliveries": {
+            "get": {
+                "description": "Returns recent webhook deliveries, most recent first, with their attempts, last response and payload.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only deliveries to this subscription",
+                        "name": "subscription",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status: pending, retrying, delivered or dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum deliveries to return (default 100, 0 for all)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/webhooks.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Webhooks are not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/agents": {
+            "get": {
+                "description": "Returns every agent from the snapshot cache, as in the agents section of /system/state.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agents"
+                ],
+                "summary": "List agents",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Agent"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/agents/{name}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agents"
+                ],
+                "summary": "Get an agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Agent"
+                        }
+                    },
+                    "404": {
+                        "description": "Agent not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/agents/{name}/heartbeat": {
+            "post": {
+                "description": "Registers the agent on first use and marks it alive. Agents without a heartbeat for\nAGENT_STALE_SECONDS are reported stale. Details left empty keep their registered values.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agents"
+                ],
+                "summary": "Record an agent heartbeat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Agent details",
+                        "name": "heartbeat",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.AgentHeartbeat"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Agent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Agent ingestion is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/agents/{name}/tasks": {
+            "get": {
+                "description": "Returns the agent's current tasks and those that finished within the retention period.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agents"
+                ],
+                "summary": "List an agent's tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TaskStatus"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Agent not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Sets the status and details of the listed tasks, adding tasks the agent has not\nreported before. Tasks move from pending to running to completed, failed or\ncancelled; the last three are final and stay listed for AGENT_TASK_RETENTION_SECONDS.\nstarted_at and finished_at default to when the transition is reported. Details left\nempty keep their previous values. A report also counts as a heartbeat.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agents"
+                ],
+                "summary": "Report agent task statuses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changed tasks",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskReport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Agent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Task already finished or transition not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Agent ingestion is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/alerts": {
+            "get": {
+                "description": "Returns the alerts raised by the configured rules. By default these are the active\n(pending and firing) alerts, oldest first; state=resolved lists recently resolved ones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alerts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by state: pending, firing or resolved",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/alerting.Alert"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "Alerting is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/models/{model}": {
+            "get": {
+                "description": "Returns the model's LiteLLM entries, one per provider serving it. Model names may\ncontain slashes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "models"
+                ],
+                "summary": "Get a model",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Model name",
+                        "name": "model",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LiteLLM"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Model not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queues/{name}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "queues"
+                ],
+                "summary": "Get a queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Queue name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Queue"
+                        }
+                    },
+                    "404": {
+                        "description": "Queue not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/diff": {
+            "get": {
+                "description": "Compares the recorded snapshots nearest to from and to, matching agents, workloads,\npods, queue tasks and LiteLLM entries by identity. Omitting to (or to=now) compares\nagainst the live state. format=patch returns an RFC 6902 JSON Patch instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Diff two snapshots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC3339)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC3339) or 'now' (default)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or patch",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/diff.Diff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No snapshot recorded",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "History is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/graph": {
+            "get": {
+                "description": "Correlates the sections of the current state: agents with their workloads, pods and\nmodels, and agent tasks with the queues and queue tasks they came from. References to\nentities missing from their section, such as a deployment absent from the workload\nsection or a model not configured in LiteLLM, are nodes marked missing and listed\nin dangling.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get the entity graph",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/graph.Graph"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/graph/agents/{name}": {
+            "get": {
+                "description": "Follows an agent's references in the current state: its workload with the pods'\nutilization, the rate limit headroom of its models with every provider, and the\nqueued tasks its tasks came from, together with its dangling references.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get an agent's correlated view",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agent name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/graph.AgentView"
+                        }
+                    },
+                    "404": {
+                        "description": "Agent not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/health-report": {
+            "get": {
+                "description": "Runs the consistency checks on the current state, or with at on the recorded snapshot\nnearest to that time, and lists every violation with its severity and the graph IDs of\nthe offending entities. The report is healthy when no check found an error; checks\nthat need a section in error are skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Check the system state's invariants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC3339) to read from history",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/consistency.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No snapshot recorded",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "History is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/history": {
+            "get": {
+                "description": "Returns snapshots recorded between from and to (RFC3339), oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "List historical snapshots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC3339), defaults to the oldest snapshot",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC3339), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum snapshots to return (default 100, 0 for all)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/history.Snapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "History is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/system/state": {
             "get": {
-                "description": "Returns the current state of agents, workloads, queues, and LiteLLM models",
+                "description": "Returns the current state of agents, workloads, queues, and LiteLLM models.\nSections whose source failed are reported in the status block; the\nresponse is 503 only when every section failed.\nWith at, returns the recorded snapshot nearest to that time instead.\ninclude, the entity filters and query narrow the response; filters take comma\nseparated values, and query is a JMESPath expression evaluated on the filtered\nsnapshot whose result is returned as is.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get system state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC3339) to read from history",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sections to return: agents, workload, queues, litellm",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Agent names to keep",
+                        "name": "agent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Deployments whose workloads and agents to keep",
+                        "name": "deployment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Task states to keep; agents left without tasks are dropped",
+                        "name": "task_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "LiteLLM providers to keep",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JMESPath expression, e.g. agents[].name",
+                        "name": "query",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SystemState"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "No snapshot recorded",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "History is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.SystemState"
+                        }
+                    }
+                }
+            }
+        },
+        "/system/stream": {
+            "get": {
+                "description": "Server-Sent Events stream. Sends a \"state\" event with the full snapshot, then change\nevents such as task.status, task.failed, queue.depth, pod.added or agent.removed. Reconnecting with\nLast-Event-ID replays the missed events when they are still buffered.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Stream system state changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "text/event-stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Streaming unsupported",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/traces": {
+            "post": {
+                "description": "OTLP/HTTP trace endpoint, in binary protobuf (application/x-protobuf) or JSON\n(application/json), optionally gzip-encoded. Agent, task and model call spans are\nrecognized by the attributes in OTLP_MAPPING_PATH and feed the agents and litellm\nsections whose backend is otlp. The response uses the request's encoding.",
+                "consumes": [
+                    "application/x-protobuf",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-protobuf",
+                    "application/json"
+                ],
+                "tags": [
+                    "agents"
+                ],
+                "summary": "Receive OpenTelemetry traces",
+                "responses": {
+                    "200": {
+                        "description": "ExportTraceServiceResponse",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Export too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported content type",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "OTLP receiver is not enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/workloads/{deployment}": {
+            "get": {
+                "description": "Returns the workload of a deployment with its pods and their utilization.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get system state",
+                "tags": [
+                    "workloads"
+                ],
+                "summary": "Get a workload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment name",
+                        "name": "deployment",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SystemState"
+                            "$ref": "#/definitions/models.Workload"
+                        }
+                    },
+                    "404": {
+                        "description": "Workload not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/workloads/{deployment}/pods/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workloads"
+                ],
+                "summary": "Get a pod",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment name",
+                        "name": "deployment",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pod ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Pod"
+                        }
+                    },
+                    "404": {
+                        "description": "Workload or pod not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "alerting.Alert": {
+            "type": "object",
+            "properties": {
+                "active_since": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "fired_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "section": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "consistency.CheckResult": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "skipped": {
+                    "type": "boolean"
+                },
+                "violations": {
+                    "type": "integer"
+                }
+            }
+        },
+        "consistency.Report": {
+            "type": "object",
+            "properties": {
+                "captured_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/consistency.CheckResult"
+                    }
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/consistency.Violation"
+                    }
+                }
+            }
+        },
+        "consistency.Violation": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "type": "string"
+                },
+                "entities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expected": {},
+                "message": {
+                    "type": "string"
+                },
+                "observed": {},
+                "severity": {
+                    "type": "string"
+                }
+            }
+        },
+        "diff.Change": {
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/diff.FieldChange"
+                    }
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "section": {
+                    "type": "string"
+                },
+                "value": {}
+            }
+        },
+        "diff.Diff": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/diff.Change"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "diff.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "from": {},
+                "to": {}
+            }
+        },
+        "graph.AgentView": {
+            "type": "object",
+            "properties": {
+                "agent": {
+                    "$ref": "#/definitions/models.Agent"
+                },
+                "captured_at": {
+                    "type": "string"
+                },
+                "dangling": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.Reference"
+                    }
+                },
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.ModelView"
+                    }
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.TaskView"
+                    }
+                },
+                "workload": {
+                    "$ref": "#/definitions/models.Workload"
+                }
+            }
+        },
+        "graph.Edge": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "graph.Graph": {
+            "type": "object",
+            "properties": {
+                "captured_at": {
+                    "type": "string"
+                },
+                "dangling": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.Reference"
+                    }
+                },
+                "edges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.Edge"
+                    }
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.Node"
+                    }
+                }
+            }
+        },
+        "graph.ModelView": {
+            "type": "object",
+            "properties": {
+                "model": {
+                    "type": "string"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graph.ProviderUsage"
+                    }
+                }
+            }
+        },
+        "graph.Node": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "missing": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "graph.ProviderUsage": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string"
+                },
+                "rpm": {
+                    "$ref": "#/definitions/models.ResourceUsage"
+                },
+                "tpm": {
+                    "$ref": "#/definitions/models.ResourceUsage"
+                }
+            }
+        },
+        "graph.Reference": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "graph.TaskView": {
+            "type": "object",
+            "properties": {
+                "duration_seconds": {
+                    "type": "number"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "queue_task": {
+                    "$ref": "#/definitions/models.QueueTask"
+                },
+                "queue_task_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "failed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskState"
                         }
-                    }
+                    ]
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "history.Snapshot": {
+            "type": "object",
+            "properties": {
+                "captured_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/models.SystemState"
+                }
+            }
+        },
         "models.Activity": {
             "type": "object",
             "properties": {
@@ -43,6 +1294,15 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.TaskStatus"
                     }
                 },
+                "last_heartbeat": {
+                    "type": "string"
+                },
+                "stale": {
+                    "type": "boolean"
+                },
+                "staleness_seconds": {
+                    "type": "number"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -74,6 +1334,66 @@ const docTemplate = `{
                 }
             }
         },
+        "models.AgentHeartbeat": {
+            "type": "object",
+            "properties": {
+                "deployment_name": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "max_parallel_invocations": {
+                    "type": "integer"
+                },
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CacheInfo": {
+            "type": "object",
+            "properties": {
+                "age_seconds": {
+                    "type": "number"
+                },
+                "fetched_at": {
+                    "type": "string"
+                },
+                "stale": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ConsumerGroup": {
+            "type": "object",
+            "properties": {
+                "consumers": {
+                    "type": "integer"
+                },
+                "lag": {
+                    "type": "integer"
+                },
+                "last_delivered_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PartitionLag"
+                    }
+                },
+                "pending": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.LiteLLM": {
             "type": "object",
             "properties": {
@@ -106,11 +1426,48 @@ const docTemplate = `{
                 "active_pods": {
                     "type": "integer"
                 },
+                "staleness_seconds": {
+                    "type": "number"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "models.Partition": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "end_offset": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "start_offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PartitionLag": {
+            "type": "object",
+            "properties": {
+                "committed_offset": {
+                    "type": "integer"
+                },
+                "end_offset": {
+                    "type": "integer"
+                },
+                "lag": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.Pod": {
             "type": "object",
             "properties": {
@@ -125,6 +1482,9 @@ const docTemplate = `{
                 },
                 "status": {
                     "type": "string"
+                },
+                "utilization": {
+                    "$ref": "#/definitions/models.Utilization"
                 }
             }
         },
@@ -139,9 +1499,27 @@ const docTemplate = `{
         "models.Queue": {
             "type": "object",
             "properties": {
+                "consumer_groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ConsumerGroup"
+                    }
+                },
+                "max_wait_seconds": {
+                    "type": "number"
+                },
                 "name": {
                     "type": "string"
                 },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Partition"
+                    }
+                },
+                "staleness_seconds": {
+                    "type": "number"
+                },
                 "tasks": {
                     "type": "array",
                     "items": {
@@ -156,6 +1534,9 @@ const docTemplate = `{
         "models.QueueTask": {
             "type": "object",
             "properties": {
+                "age_seconds": {
+                    "type": "number"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -167,6 +1548,37 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ResourceUsage": {
+            "type": "object",
+            "properties": {
+                "headroom": {
+                    "type": "number"
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "ratio": {
+                    "type": "number"
+                },
+                "used": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.SectionStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "last_success": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
         "models.SystemState": {
             "type": "object",
             "properties": {
@@ -176,6 +1588,15 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.Agent"
                     }
                 },
+                "cache": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.CacheInfo"
+                    }
+                },
+                "captured_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -191,6 +1612,12 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.Queue"
                     }
                 },
+                "status": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.SectionStatus"
+                    }
+                },
                 "workload": {
                     "type": "array",
                     "items": {
@@ -199,14 +1626,91 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TaskReport": {
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskStatus"
+                    }
+                }
+            }
+        },
+        "models.TaskState": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "TaskPending",
+                "TaskRunning",
+                "TaskCompleted",
+                "TaskFailed",
+                "TaskCancelled"
+            ]
+        },
         "models.TaskStatus": {
             "type": "object",
             "properties": {
+                "duration_seconds": {
+                    "type": "number"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "status": {
+                "input_tokens": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "output_tokens": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "queue_task_id": {
                     "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "failed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskState"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.Utilization": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "$ref": "#/definitions/models.ResourceUsage"
+                },
+                "memory": {
+                    "$ref": "#/definitions/models.ResourceUsage"
                 }
             }
         },
@@ -223,16 +1727,76 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "pod_max_cpu": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "500m"
                 },
                 "pod_max_ram": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2Gi"
                 },
                 "pods": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/models.Pod"
                     }
+                },
+                "utilization": {
+                    "$ref": "#/definitions/models.WorkloadUtilization"
+                }
+            }
+        },
+        "models.WorkloadUtilization": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "$ref": "#/definitions/models.ResourceUsage"
+                },
+                "memory": {
+                    "$ref": "#/definitions/models.ResourceUsage"
+                },
+                "pods_near_limit": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhooks.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_code": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscription": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,229 @@
+// Package alerting evaluates declarative rules against each SystemState
+// snapshot and tracks the resulting alerts through pending, firing and
+// resolved states.
+package alerting
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/logger"
+)
+
+// Alert states. An alert is pending while its condition holds for less
+// than the rule's for duration.
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+const maxResolved = 100
+
+// StateSource produces the live system state to evaluate.
+type StateSource interface {
+	GetSystemState(ctx context.Context) (*models.SystemState, error)
+}
+
+// Alert is one rule breached by one entity.
+type Alert struct {
+	Rule        string     `json:"rule"`
+	Severity    string     `json:"severity"`
+	Section     string     `json:"section"`
+	Entity      string     `json:"entity"`
+	State       string     `json:"state"`
+	Value       float64    `json:"value"`
+	Threshold   float64    `json:"threshold"`
+	Message     string     `json:"message"`
+	Description string     `json:"description,omitempty"`
+	ActiveSince time.Time  `json:"active_since"`
+	FiredAt     *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Engine evaluates rules against the state at a fixed interval and keeps
+// the active alerts plus the most recently resolved ones.
+type Engine struct {
+	source   StateSource
+	rules    []Rule
+	interval time.Duration
+	now      func() time.Time
+	notify   func([]Alert)
+
+	mu       sync.Mutex
+	active   map[alertKey]*Alert
+	resolved []Alert
+}
+
+type alertKey struct {
+	rule   string
+	entity string
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithNotifier calls notify with the alerts that started firing or
+// resolved after each evaluation that produced any.
+func WithNotifier(notify func([]Alert)) Option {
+	return func(e *Engine) {
+		e.notify = notify
+	}
+}
+
+func NewEngine(source StateSource, rules []Rule, interval time.Duration, opts ...Option) *Engine {
+	e := &Engine{
+		source:   source,
+		rules:    rules,
+		interval: interval,
+		now:      time.Now,
+		active:   make(map[alertKey]*Alert),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run evaluates immediately and then every interval until ctx is done.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.Evaluate(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Error("Failed to evaluate alert rules", zap.Error(err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Evaluate fetches the state once, updates the alerts and returns those
+// that started firing or resolved.
+func (e *Engine) Evaluate(ctx context.Context) ([]Alert, error) {
+	state, err := e.source.GetSystemState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions := e.evaluate(state, e.now())
+	for _, alert := range transitions {
+		logger.Log.Info("Alert "+alert.State,
+			zap.String("rule", alert.Rule),
+			zap.String("entity", alert.Entity),
+			zap.String("severity", alert.Severity),
+			zap.String("message", alert.Message))
+	}
+	if e.notify != nil && len(transitions) > 0 {
+		e.notify(transitions)
+	}
+	return transitions, nil
+}
+
+func (e *Engine) evaluate(state *models.SystemState, now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var transitions []Alert
+	for _, rule := range e.rules {
+		section := conditions[rule.Condition].section
+		// A section in error has no data; keep its alerts as they are
+		// rather than resolving them.
+		if status, ok := state.Status[section]; ok && status.Status == models.SectionError {
+			continue
+		}
+
+		breached := make(map[alertKey]bool)
+		for _, s := range rule.breaching(state, now) {
+			key := alertKey{rule: rule.Name, entity: s.entity}
+			breached[key] = true
+
+			alert, ok := e.active[key]
+			if !ok {
+				alert = &Alert{
+					Rule:        rule.Name,
+					Severity:    rule.Severity,
+					Section:     section,
+					Entity:      s.entity,
+					State:       StatePending,
+					Threshold:   rule.threshold(),
+					Description: rule.Description,
+					ActiveSince: now,
+				}
+				e.active[key] = alert
+			}
+			alert.Value = s.value
+			alert.Message = s.message
+
+			if alert.State == StatePending && now.Sub(alert.ActiveSince) >= rule.For {
+				firedAt := now
+				alert.State = StateFiring
+				alert.FiredAt = &firedAt
+				transitions = append(transitions, *alert)
+			}
+		}
+
+		for key, alert := range e.active {
+			if key.rule != rule.Name || breached[key] {
+				continue
+			}
+			delete(e.active, key)
+			if alert.State != StateFiring {
+				continue
+			}
+			resolvedAt := now
+			alert.State = StateResolved
+			alert.ResolvedAt = &resolvedAt
+			e.resolved = append(e.resolved, *alert)
+			transitions = append(transitions, *alert)
+		}
+	}
+
+	if excess := len(e.resolved) - maxResolved; excess > 0 {
+		e.resolved = append([]Alert(nil), e.resolved[excess:]...)
+	}
+	return transitions
+}
+
+// Active returns the pending and firing alerts, oldest first.
+func (e *Engine) Active() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(e.active))
+	for _, alert := range e.active {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].ActiveSince.Equal(alerts[j].ActiveSince) {
+			return alerts[i].ActiveSince.Before(alerts[j].ActiveSince)
+		}
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return alerts[i].Entity < alerts[j].Entity
+	})
+	return alerts
+}
+
+// Resolved returns recently resolved alerts, most recent first.
+func (e *Engine) Resolved() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]Alert, len(e.resolved))
+	for i, alert := range e.resolved {
+		alerts[len(alerts)-1-i] = alert
+	}
+	return alerts
+}
@@ -0,0 +1,245 @@
+package alerting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+const testRules = `
+rules:
+  - name: agent-overloaded
+    condition: agent_overload
+    severity: critical
+  - name: litellm-tpm-high
+    condition: litellm_tpm_usage
+    threshold: 0.9
+    for: 5m
+  - name: queue-task-stale
+    condition: queue_task_age
+    max_age: 10m
+  - name: workload-at-capacity
+    condition: workload_at_capacity
+`
+
+type staticSource struct {
+	state *models.SystemState
+}
+
+func (s *staticSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	return s.state, nil
+}
+
+func testState(now time.Time) *models.SystemState {
+	return &models.SystemState{
+		Agents: []models.Agent{
+			{Name: "agent-1", MaxParallelInvocations: 1, Activity: models.Activity{
+				ActiveTaskIDs: []models.TaskStatus{
+					{ID: "task-1", Status: models.TaskRunning},
+					{ID: "task-2", Status: models.TaskRunning},
+				},
+			}},
+			{Name: "agent-2", MaxParallelInvocations: 5},
+		},
+		Workload: []models.Workload{
+			{DeploymentName: "deploy-1", MaxPods: 3, Live: models.LiveWorkload{ActivePods: 3}},
+			{DeploymentName: "deploy-2", MaxPods: 3, Live: models.LiveWorkload{ActivePods: 1}},
+		},
+		Queues: []models.Queue{{Name: "default", Tasks: []models.QueueTask{
+			{ID: "task-3", SubmittedAt: now.Add(-15 * time.Minute)},
+			{ID: "task-4", SubmittedAt: now.Add(-time.Minute)},
+		}}},
+		LiteLLM: []models.LiteLLM{
+			{Model: "gpt-4", Provider: "openai", TPM: 950, TPMMax: 1000},
+			{Model: "gpt-4", Provider: "azure", TPM: 100, TPMMax: 1000},
+		},
+	}
+}
+
+func newTestEngine(t *testing.T, source StateSource) (*Engine, *time.Time) {
+	t.Helper()
+	rules, err := ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+	now := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	engine := NewEngine(source, rules, time.Minute)
+	engine.now = func() time.Time { return now }
+	return engine, &now
+}
+
+func findAlert(alerts []Alert, rule, entity string) *Alert {
+	for i := range alerts {
+		if alerts[i].Rule == rule && alerts[i].Entity == entity {
+			return &alerts[i]
+		}
+	}
+	return nil
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(testRules))
+	if err != nil {
+		t.Fatalf("ParseRules failed: %v", err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %d", len(rules))
+	}
+	if rules[0].Severity != SeverityCritical || *rules[0].Threshold != 1 {
+		t.Errorf("Unexpected agent rule %+v", rules[0])
+	}
+	if rules[1].For != 5*time.Minute || rules[1].Severity != SeverityWarning {
+		t.Errorf("Unexpected litellm rule %+v", rules[1])
+	}
+	if rules[2].MaxAge != 10*time.Minute {
+		t.Errorf("Expected max_age of 10m, got %s", rules[2].MaxAge)
+	}
+}
+
+func TestParseRules_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown condition": "rules:\n  - name: a\n    condition: nope\n",
+		"missing name":      "rules:\n  - condition: agent_overload\n",
+		"duplicate name":    "rules:\n  - name: a\n    condition: agent_overload\n  - name: a\n    condition: agent_overload\n",
+		"missing max_age":   "rules:\n  - name: a\n    condition: queue_task_age\n",
+		"bad severity":      "rules:\n  - name: a\n    condition: agent_overload\n    severity: loud\n",
+		"bad duration":      "rules:\n  - name: a\n    condition: agent_overload\n    for: soon\n",
+	}
+	for name, data := range tests {
+		if _, err := ParseRules([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	engine, now := newTestEngine(t, &staticSource{})
+	state := testState(*now)
+
+	transitions := engine.evaluate(state, *now)
+
+	for _, expected := range []struct{ rule, entity string }{
+		{"agent-overloaded", "agent-1"},
+		{"queue-task-stale", "default/task-3"},
+		{"workload-at-capacity", "deploy-1"},
+	} {
+		alert := findAlert(transitions, expected.rule, expected.entity)
+		if alert == nil || alert.State != StateFiring {
+			t.Errorf("Expected %s to fire for %s, got %+v", expected.rule, expected.entity, transitions)
+		}
+	}
+	if len(transitions) != 3 {
+		t.Errorf("Expected 3 firing transitions, got %+v", transitions)
+	}
+
+	pending := findAlert(engine.Active(), "litellm-tpm-high", "gpt-4/openai")
+	if pending == nil || pending.State != StatePending {
+		t.Fatalf("Expected litellm alert to be pending, got %+v", engine.Active())
+	}
+	if pending.Value != 0.95 || pending.Threshold != 0.9 {
+		t.Errorf("Unexpected value %v / threshold %v", pending.Value, pending.Threshold)
+	}
+	if findAlert(engine.Active(), "workload-at-capacity", "deploy-2") != nil {
+		t.Error("Expected deploy-2 to be below capacity")
+	}
+}
+
+func TestEngine_ForDuration(t *testing.T) {
+	engine, now := newTestEngine(t, &staticSource{})
+	state := testState(*now)
+
+	engine.evaluate(state, *now)
+	if transitions := engine.evaluate(state, now.Add(4*time.Minute)); findAlert(transitions, "litellm-tpm-high", "gpt-4/openai") != nil {
+		t.Fatal("Expected litellm alert to stay pending before its for duration")
+	}
+
+	transitions := engine.evaluate(state, now.Add(5*time.Minute))
+	alert := findAlert(transitions, "litellm-tpm-high", "gpt-4/openai")
+	if alert == nil || alert.State != StateFiring {
+		t.Fatalf("Expected litellm alert to fire after 5m, got %+v", transitions)
+	}
+	if !alert.ActiveSince.Equal(*now) || !alert.FiredAt.Equal(now.Add(5*time.Minute)) {
+		t.Errorf("Unexpected timestamps %s / %s", alert.ActiveSince, alert.FiredAt)
+	}
+}
+
+func TestEngine_Resolve(t *testing.T) {
+	engine, now := newTestEngine(t, &staticSource{})
+	state := testState(*now)
+	engine.evaluate(state, *now)
+
+	// Finished tasks stay listed for a while but no longer load the agent.
+	state.Agents[0].Activity.ActiveTaskIDs[1].Status = models.TaskCompleted
+	state.LiteLLM[0].TPM = 100
+	transitions := engine.evaluate(state, now.Add(time.Minute))
+
+	resolved := findAlert(transitions, "agent-overloaded", "agent-1")
+	if resolved == nil || resolved.State != StateResolved || resolved.ResolvedAt == nil {
+		t.Fatalf("Expected agent alert to resolve, got %+v", transitions)
+	}
+	if findAlert(transitions, "litellm-tpm-high", "gpt-4/openai") != nil {
+		t.Error("Expected a pending alert to clear without a resolved transition")
+	}
+	if findAlert(engine.Active(), "agent-overloaded", "agent-1") != nil {
+		t.Error("Expected resolved alert to leave the active list")
+	}
+	if findAlert(engine.Resolved(), "agent-overloaded", "agent-1") == nil {
+		t.Error("Expected resolved alert to be kept in the resolved list")
+	}
+}
+
+func TestEngine_SectionErrorKeepsAlerts(t *testing.T) {
+	engine, now := newTestEngine(t, &staticSource{})
+	engine.evaluate(testState(*now), *now)
+
+	failed := &models.SystemState{Status: map[string]models.SectionStatus{
+		models.SectionAgents: {Status: models.SectionError, Error: "unavailable"},
+	}}
+	transitions := engine.evaluate(failed, now.Add(time.Minute))
+
+	if findAlert(transitions, "agent-overloaded", "agent-1") != nil {
+		t.Error("Expected agent alert not to resolve while the section is in error")
+	}
+	if findAlert(engine.Active(), "agent-overloaded", "agent-1") == nil {
+		t.Error("Expected agent alert to remain active")
+	}
+	if findAlert(transitions, "workload-at-capacity", "deploy-1") == nil {
+		t.Error("Expected workload alert to resolve once the section reports no breach")
+	}
+}
+
+func TestEngine_EvaluateFromSource(t *testing.T) {
+	engine, now := newTestEngine(t, nil)
+	engine.source = &staticSource{state: testState(*now)}
+
+	transitions, err := engine.Evaluate(context.Background())
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	alert := findAlert(transitions, "queue-task-stale", "default/task-3")
+	if alert == nil || !strings.Contains(alert.Message, "15m0s") {
+		t.Errorf("Expected stale task alert with its age, got %+v", alert)
+	}
+}
+
+func TestEngine_Notifier(t *testing.T) {
+	rules, _ := ParseRules([]byte(testRules))
+	now := time.Now()
+
+	var notified []Alert
+	engine := NewEngine(&staticSource{state: testState(now)}, rules, time.Minute,
+		WithNotifier(func(alerts []Alert) { notified = append(notified, alerts...) }))
+
+	engine.Evaluate(context.Background())
+	if len(notified) != 3 {
+		t.Fatalf("Expected 3 firing alerts to be notified, got %+v", notified)
+	}
+
+	engine.Evaluate(context.Background())
+	if len(notified) != 3 {
+		t.Errorf("Expected no notification without transitions, got %+v", notified)
+	}
+}
@@ -0,0 +1,259 @@
+package alerting
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"telemetron/internal/models"
+)
+
+// Conditions a rule can check. Each one yields a value per entity that is
+// compared against the rule's threshold.
+const (
+	// AgentOverload compares running tasks to max parallel invocations.
+	AgentOverload = "agent_overload"
+	// LiteLLMTPMUsage compares current TPM to the model's TPM limit.
+	LiteLLMTPMUsage = "litellm_tpm_usage"
+	// LiteLLMRPMUsage compares current RPM to the model's RPM limit.
+	LiteLLMRPMUsage = "litellm_rpm_usage"
+	// QueueTaskAge compares how long a queued task has waited to max_age.
+	QueueTaskAge = "queue_task_age"
+	// WorkloadAtCapacity compares active pods to the deployment's max pods.
+	WorkloadAtCapacity = "workload_at_capacity"
+)
+
+// Alert severities.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Rule is one declarative alerting rule. A rule fires for an entity once
+// its condition has held for at least For.
+type Rule struct {
+	Name        string        `yaml:"name"`
+	Condition   string        `yaml:"condition"`
+	Threshold   *float64      `yaml:"threshold"`
+	MaxAge      time.Duration `yaml:"max_age"`
+	For         time.Duration `yaml:"for"`
+	Severity    string        `yaml:"severity"`
+	Description string        `yaml:"description"`
+}
+
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// condition describes how a rule's value is derived from the state.
+type condition struct {
+	section   string
+	threshold float64
+	inclusive bool
+	check     func(state *models.SystemState, now time.Time) []sample
+}
+
+// sample is the value of a condition for one entity.
+type sample struct {
+	entity  string
+	value   float64
+	message string
+}
+
+var conditions = map[string]condition{
+	AgentOverload: {
+		section:   models.SectionAgents,
+		threshold: 1,
+		check:     agentOverload,
+	},
+	LiteLLMTPMUsage: {
+		section:   models.SectionLiteLLM,
+		threshold: 0.9,
+		check:     litellmTPMUsage,
+	},
+	LiteLLMRPMUsage: {
+		section:   models.SectionLiteLLM,
+		threshold: 0.9,
+		check:     litellmRPMUsage,
+	},
+	QueueTaskAge: {
+		section: models.SectionQueues,
+		check:   queueTaskAge,
+	},
+	WorkloadAtCapacity: {
+		section:   models.SectionWorkload,
+		threshold: 1,
+		inclusive: true,
+		check:     workloadAtCapacity,
+	},
+}
+
+// LoadRules reads rules from a YAML file of the form
+//
+//	rules:
+//	  - name: litellm-tpm-high
+//	    condition: litellm_tpm_usage
+//	    threshold: 0.9
+//	    for: 5m
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(data)
+}
+
+// ParseRules decodes and validates YAML rules, filling in default
+// thresholds and severities.
+func ParseRules(data []byte) ([]Rule, error) {
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse alert rules: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.Rules))
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if rule.Name == "" {
+			return nil, fmt.Errorf("alert rule %d has no name", i+1)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate alert rule %q", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		cond, ok := conditions[rule.Condition]
+		if !ok {
+			return nil, fmt.Errorf("alert rule %q: unknown condition %q", rule.Name, rule.Condition)
+		}
+		if rule.Condition == QueueTaskAge {
+			if rule.MaxAge <= 0 {
+				return nil, fmt.Errorf("alert rule %q: %s requires max_age", rule.Name, QueueTaskAge)
+			}
+		} else if rule.Threshold == nil {
+			threshold := cond.threshold
+			rule.Threshold = &threshold
+		}
+		if rule.For < 0 {
+			return nil, fmt.Errorf("alert rule %q: for must not be negative", rule.Name)
+		}
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityWarning
+		case SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			return nil, fmt.Errorf("alert rule %q: unknown severity %q", rule.Name, rule.Severity)
+		}
+	}
+	return file.Rules, nil
+}
+
+// threshold returns the value the rule's samples are compared against.
+func (r Rule) threshold() float64 {
+	if r.Condition == QueueTaskAge {
+		return r.MaxAge.Seconds()
+	}
+	return *r.Threshold
+}
+
+// breaching returns the samples that violate the rule.
+func (r Rule) breaching(state *models.SystemState, now time.Time) []sample {
+	cond := conditions[r.Condition]
+	threshold := r.threshold()
+
+	var matched []sample
+	for _, s := range cond.check(state, now) {
+		if s.value > threshold || (cond.inclusive && s.value == threshold) {
+			matched = append(matched, s)
+		}
+	}
+	return matched
+}
+
+func agentOverload(state *models.SystemState, _ time.Time) []sample {
+	var samples []sample
+	for _, agent := range state.Agents {
+		if agent.MaxParallelInvocations <= 0 {
+			continue
+		}
+		running := 0
+		for _, task := range agent.Activity.ActiveTaskIDs {
+			if task.Status == models.TaskRunning {
+				running++
+			}
+		}
+		samples = append(samples, sample{
+			entity:  agent.Name,
+			value:   float64(running) / float64(agent.MaxParallelInvocations),
+			message: fmt.Sprintf("agent %s is running %d tasks for %d parallel invocations", agent.Name, running, agent.MaxParallelInvocations),
+		})
+	}
+	return samples
+}
+
+func litellmTPMUsage(state *models.SystemState, _ time.Time) []sample {
+	var samples []sample
+	for _, llm := range state.LiteLLM {
+		if llm.TPMMax <= 0 {
+			continue
+		}
+		samples = append(samples, sample{
+			entity:  llm.Model + "/" + llm.Provider,
+			value:   float64(llm.TPM) / float64(llm.TPMMax),
+			message: fmt.Sprintf("%s on %s is at %d of %d tokens per minute", llm.Model, llm.Provider, llm.TPM, llm.TPMMax),
+		})
+	}
+	return samples
+}
+
+func litellmRPMUsage(state *models.SystemState, _ time.Time) []sample {
+	var samples []sample
+	for _, llm := range state.LiteLLM {
+		if llm.RPMMax <= 0 {
+			continue
+		}
+		samples = append(samples, sample{
+			entity:  llm.Model + "/" + llm.Provider,
+			value:   float64(llm.RPM) / float64(llm.RPMMax),
+			message: fmt.Sprintf("%s on %s is at %d of %d requests per minute", llm.Model, llm.Provider, llm.RPM, llm.RPMMax),
+		})
+	}
+	return samples
+}
+
+func queueTaskAge(state *models.SystemState, now time.Time) []sample {
+	var samples []sample
+	for _, queue := range state.Queues {
+		for _, task := range queue.Tasks {
+			if task.SubmittedAt.IsZero() {
+				continue
+			}
+			age := now.Sub(task.SubmittedAt)
+			samples = append(samples, sample{
+				entity:  queue.Name + "/" + task.ID,
+				value:   age.Seconds(),
+				message: fmt.Sprintf("task %s has waited in queue %s for %s", task.ID, queue.Name, age.Truncate(time.Second)),
+			})
+		}
+	}
+	return samples
+}
+
+func workloadAtCapacity(state *models.SystemState, _ time.Time) []sample {
+	var samples []sample
+	for _, workload := range state.Workload {
+		if workload.MaxPods <= 0 {
+			continue
+		}
+		samples = append(samples, sample{
+			entity:  workload.DeploymentName,
+			value:   float64(workload.Live.ActivePods) / float64(workload.MaxPods),
+			message: fmt.Sprintf("deployment %s is running %d of %d pods", workload.DeploymentName, workload.Live.ActivePods, workload.MaxPods),
+		})
+	}
+	return samples
+}
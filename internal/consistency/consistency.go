@@ -0,0 +1,282 @@
+// Package consistency checks the invariants a system snapshot should hold
+// across and within its sections, such as agents running no more tasks than
+// they allow in parallel, and reports the entities that break them.
+package consistency
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"telemetron/internal/graph"
+	"telemetron/internal/models"
+)
+
+// Checks run on every snapshot.
+const (
+	// AgentParallelism: running tasks per agent <= max parallel invocations.
+	AgentParallelism = "agent_parallelism"
+	// WorkloadActivePods: active pods == the number of running pods.
+	WorkloadActivePods = "workload_active_pods"
+	// WorkloadMaxPods: active pods <= max pods.
+	WorkloadMaxPods = "workload_max_pods"
+	// LiteLLMTPM: TPM <= the TPM limit.
+	LiteLLMTPM = "litellm_tpm"
+	// LiteLLMRPM: RPM <= the RPM limit.
+	LiteLLMRPM = "litellm_rpm"
+	// TaskQueuedAndRunning: no task is running while still queued.
+	TaskQueuedAndRunning = "task_queued_and_running"
+)
+
+// Violation severities. Errors break a limit; warnings are inconsistencies
+// that sections fetched at slightly different times can briefly show.
+const (
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Report is the outcome of checking one snapshot. It is healthy when no
+// check found an error. Checks that need a section in error are skipped.
+type Report struct {
+	CapturedAt time.Time     `json:"captured_at"`
+	Healthy    bool          `json:"healthy"`
+	Checks     []CheckResult `json:"checks"`
+	Violations []Violation   `json:"violations"`
+}
+
+// CheckResult summarizes one check.
+type CheckResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Skipped     bool   `json:"skipped,omitempty"`
+	Violations  int    `json:"violations"`
+}
+
+// Violation is one entity breaking a check. Entities are the IDs of the
+// offending nodes in the entity graph, e.g. "agent:agent-1"; Observed and
+// Expected are the values compared, when the check compares values.
+type Violation struct {
+	Check    string      `json:"check"`
+	Severity string      `json:"severity"`
+	Message  string      `json:"message"`
+	Entities []string    `json:"entities"`
+	Observed interface{} `json:"observed,omitempty"`
+	Expected interface{} `json:"expected,omitempty"`
+}
+
+type check struct {
+	name        string
+	description string
+	severity    string
+	sections    []string
+	run         func(state *models.SystemState) []Violation
+}
+
+var checks = []check{
+	{
+		name:        AgentParallelism,
+		description: "Running tasks per agent do not exceed its max parallel invocations.",
+		severity:    SeverityError,
+		sections:    []string{models.SectionAgents},
+		run:         agentParallelism,
+	},
+	{
+		name:        WorkloadActivePods,
+		description: "A workload's active pods equal the number of its running pods.",
+		severity:    SeverityWarning,
+		sections:    []string{models.SectionWorkload},
+		run:         workloadActivePods,
+	},
+	{
+		name:        WorkloadMaxPods,
+		description: "A workload's active pods do not exceed its max pods.",
+		severity:    SeverityError,
+		sections:    []string{models.SectionWorkload},
+		run:         workloadMaxPods,
+	},
+	{
+		name:        LiteLLMTPM,
+		description: "A model's tokens per minute do not exceed its TPM limit.",
+		severity:    SeverityError,
+		sections:    []string{models.SectionLiteLLM},
+		run:         litellmTPM,
+	},
+	{
+		name:        LiteLLMRPM,
+		description: "A model's requests per minute do not exceed its RPM limit.",
+		severity:    SeverityError,
+		sections:    []string{models.SectionLiteLLM},
+		run:         litellmRPM,
+	},
+	{
+		name:        TaskQueuedAndRunning,
+		description: "No task is running on an agent while it is still waiting in a queue.",
+		severity:    SeverityWarning,
+		sections:    []string{models.SectionAgents, models.SectionQueues},
+		run:         taskQueuedAndRunning,
+	},
+}
+
+// Check runs every check on a snapshot.
+func Check(state *models.SystemState) *Report {
+	report := &Report{
+		CapturedAt: state.CapturedAt,
+		Healthy:    true,
+		Checks:     make([]CheckResult, 0, len(checks)),
+		Violations: []Violation{},
+	}
+	for _, c := range checks {
+		result := CheckResult{Name: c.name, Description: c.description, Severity: c.severity}
+		for _, section := range c.sections {
+			if status, ok := state.Status[section]; ok && status.Status == models.SectionError {
+				result.Skipped = true
+			}
+		}
+		if !result.Skipped {
+			for _, v := range c.run(state) {
+				v.Check, v.Severity = c.name, c.severity
+				report.Violations = append(report.Violations, v)
+				result.Violations++
+				if c.severity == SeverityError {
+					report.Healthy = false
+				}
+			}
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+func agentParallelism(state *models.SystemState) []Violation {
+	var violations []Violation
+	for _, agent := range state.Agents {
+		if agent.MaxParallelInvocations <= 0 {
+			continue
+		}
+		entities := []string{graph.NodeID(graph.KindAgent, agent.Name)}
+		for _, task := range agent.Activity.ActiveTaskIDs {
+			if task.Status == models.TaskRunning {
+				entities = append(entities, graph.NodeID(graph.KindTask, agent.Name+"/"+task.ID))
+			}
+		}
+		if running := len(entities) - 1; running > agent.MaxParallelInvocations {
+			violations = append(violations, Violation{
+				Message:  fmt.Sprintf("agent %s is running %d tasks for %d parallel invocations", agent.Name, running, agent.MaxParallelInvocations),
+				Entities: entities,
+				Observed: running,
+				Expected: agent.MaxParallelInvocations,
+			})
+		}
+	}
+	return violations
+}
+
+func workloadActivePods(state *models.SystemState) []Violation {
+	var violations []Violation
+	for _, workload := range state.Workload {
+		running := 0
+		for _, pod := range workload.Pods {
+			if pod.Status == "running" {
+				running++
+			}
+		}
+		if workload.Live.ActivePods != running {
+			violations = append(violations, Violation{
+				Message:  fmt.Sprintf("workload %s reports %d active pods but %d pods are running", workload.DeploymentName, workload.Live.ActivePods, running),
+				Entities: []string{graph.NodeID(graph.KindWorkload, workload.DeploymentName)},
+				Observed: workload.Live.ActivePods,
+				Expected: running,
+			})
+		}
+	}
+	return violations
+}
+
+func workloadMaxPods(state *models.SystemState) []Violation {
+	var violations []Violation
+	for _, workload := range state.Workload {
+		if workload.MaxPods > 0 && workload.Live.ActivePods > workload.MaxPods {
+			violations = append(violations, Violation{
+				Message:  fmt.Sprintf("workload %s has %d active pods for %d max pods", workload.DeploymentName, workload.Live.ActivePods, workload.MaxPods),
+				Entities: []string{graph.NodeID(graph.KindWorkload, workload.DeploymentName)},
+				Observed: workload.Live.ActivePods,
+				Expected: workload.MaxPods,
+			})
+		}
+	}
+	return violations
+}
+
+func litellmTPM(state *models.SystemState) []Violation {
+	var violations []Violation
+	for _, llm := range state.LiteLLM {
+		if llm.TPMMax > 0 && llm.TPM > llm.TPMMax {
+			violations = append(violations, Violation{
+				Message:  fmt.Sprintf("model %s via %s uses %d TPM of %d", llm.Model, llm.Provider, llm.TPM, llm.TPMMax),
+				Entities: []string{graph.NodeID(graph.KindModel, llm.Model)},
+				Observed: llm.TPM,
+				Expected: llm.TPMMax,
+			})
+		}
+	}
+	return violations
+}
+
+func litellmRPM(state *models.SystemState) []Violation {
+	var violations []Violation
+	for _, llm := range state.LiteLLM {
+		if llm.RPMMax > 0 && llm.RPM > llm.RPMMax {
+			violations = append(violations, Violation{
+				Message:  fmt.Sprintf("model %s via %s uses %d RPM of %d", llm.Model, llm.Provider, llm.RPM, llm.RPMMax),
+				Entities: []string{graph.NodeID(graph.KindModel, llm.Model)},
+				Observed: llm.RPM,
+				Expected: llm.RPMMax,
+			})
+		}
+	}
+	return violations
+}
+
+// taskQueuedAndRunning matches running agent tasks with queued tasks the
+// way the entity graph does: by the queue task they report, or else by ID.
+func taskQueuedAndRunning(state *models.SystemState) []Violation {
+	queued := make(map[string][]string)
+	for _, queue := range state.Queues {
+		for _, task := range queue.Tasks {
+			queued[task.ID] = append(queued[task.ID], queue.Name)
+		}
+	}
+
+	var violations []Violation
+	for _, agent := range state.Agents {
+		for _, task := range agent.Activity.ActiveTaskIDs {
+			if task.Status != models.TaskRunning {
+				continue
+			}
+			id := task.QueueTaskID
+			if id == "" {
+				id = task.ID
+			}
+			var queues []string
+			for _, queue := range queued[id] {
+				if task.Queue == "" || task.Queue == queue {
+					queues = append(queues, queue)
+				}
+			}
+			if len(queues) == 0 {
+				continue
+			}
+			sort.Strings(queues)
+			entities := []string{graph.NodeID(graph.KindAgent, agent.Name), graph.NodeID(graph.KindTask, agent.Name+"/"+task.ID)}
+			for _, queue := range queues {
+				entities = append(entities, graph.NodeID(graph.KindQueueTask, queue+"/"+id))
+			}
+			violations = append(violations, Violation{
+				Message:  fmt.Sprintf("task %s is running on agent %s but still queued in %v", task.ID, agent.Name, queues),
+				Entities: entities,
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,97 @@
+package consistency
+
+import (
+	"testing"
+
+	"telemetron/internal/models"
+)
+
+func TestCheck(t *testing.T) {
+	state := &models.SystemState{
+		Agents: []models.Agent{{
+			Name:                   "agent-1",
+			MaxParallelInvocations: 1,
+			Activity: models.Activity{ActiveTaskIDs: []models.TaskStatus{
+				{ID: "task-1", Status: models.TaskRunning},
+				{ID: "task-2", Status: models.TaskRunning, Queue: "default", QueueTaskID: "q-2"},
+				{ID: "task-3", Status: models.TaskPending},
+			}},
+		}},
+		Workload: []models.Workload{{
+			DeploymentName: "deploy-1",
+			MaxPods:        1,
+			Live:           models.LiveWorkload{ActivePods: 2},
+			Pods:           []models.Pod{{PodID: "pod-1", Status: "running"}, {PodID: "pod-2", Status: "pending"}},
+		}},
+		Queues: []models.Queue{{
+			Name:  "default",
+			Tasks: []models.QueueTask{{ID: "q-2"}, {ID: "task-3"}},
+		}},
+		LiteLLM: []models.LiteLLM{
+			{Model: "gpt-4", Provider: "openai", TPM: 1200, TPMMax: 1000, RPM: 10, RPMMax: 100},
+			{Model: "claude", Provider: "anthropic", TPM: 1200},
+		},
+	}
+
+	report := Check(state)
+	if report.Healthy {
+		t.Error("Expected errors to make the report unhealthy")
+	}
+
+	got := make(map[string]Violation)
+	for _, v := range report.Violations {
+		if _, ok := got[v.Check]; ok {
+			t.Errorf("Expected one violation of %s, got another %+v", v.Check, v)
+		}
+		got[v.Check] = v
+	}
+	if len(got) != 5 {
+		t.Errorf("Expected violations of 5 checks, got %+v", report.Violations)
+	}
+
+	if v := got[AgentParallelism]; v.Severity != SeverityError || v.Observed != 2 || v.Expected != 1 ||
+		len(v.Entities) != 3 || v.Entities[0] != "agent:agent-1" {
+		t.Errorf("Unexpected parallelism violation %+v", v)
+	}
+	if v := got[WorkloadActivePods]; v.Severity != SeverityWarning || v.Observed != 2 || v.Expected != 1 {
+		t.Errorf("Unexpected active pods violation %+v", v)
+	}
+	if v := got[WorkloadMaxPods]; v.Entities[0] != "workload:deploy-1" {
+		t.Errorf("Unexpected max pods violation %+v", v)
+	}
+	if v := got[LiteLLMTPM]; v.Observed != 1200 || v.Expected != 1000 || v.Entities[0] != "model:gpt-4" {
+		t.Errorf("Unexpected TPM violation %+v", v)
+	}
+	// task-3 is queued but only pending, so only task-2 is reported.
+	if v := got[TaskQueuedAndRunning]; len(v.Entities) != 3 || v.Entities[1] != "task:agent-1/task-2" || v.Entities[2] != "queue_task:default/q-2" {
+		t.Errorf("Unexpected queued and running violation %+v", v)
+	}
+
+	for _, result := range report.Checks {
+		want := 0
+		if _, ok := got[result.Name]; ok {
+			want = 1
+		}
+		if result.Violations != want || result.Skipped {
+			t.Errorf("Expected %s to have run and found %d violations, got %+v", result.Name, want, result)
+		}
+	}
+}
+
+func TestCheck_SkipsSectionsInError(t *testing.T) {
+	state := &models.SystemState{
+		Status: map[string]models.SectionStatus{
+			models.SectionQueues: {Status: models.SectionError, Error: "connection refused"},
+		},
+	}
+
+	report := Check(state)
+	if !report.Healthy || len(report.Violations) != 0 {
+		t.Errorf("Expected an empty snapshot to be healthy, got %+v", report)
+	}
+	for _, result := range report.Checks {
+		if result.Skipped != (result.Name == TaskQueuedAndRunning) {
+			t.Errorf("Expected only %s to be skipped, got %+v", TaskQueuedAndRunning, result)
+		}
+	}
+}
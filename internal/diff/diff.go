@@ -0,0 +1,297 @@
+// Package diff compares two SystemState snapshots, matching entities by
+// identity rather than by position.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+// Change operations.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Diff is the semantic difference between two snapshots.
+type Diff struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Changes []Change  `json:"changes"`
+}
+
+// Change describes one entity that was added, removed or changed.
+// Nested entities (pods, queue tasks, consumer groups, agent tasks) are
+// reported as their own changes, so a parent's Fields never include its
+// child collection.
+type Change struct {
+	Op      string        `json:"op"`
+	Section string        `json:"section"`
+	Kind    string        `json:"kind"`
+	Entity  string        `json:"entity"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+	Value   interface{}   `json:"value,omitempty"`
+}
+
+// FieldChange is a changed field within an entity, addressed by its dotted
+// JSON path.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// collection describes a keyed list of entities within the snapshot.
+type collection struct {
+	field    string
+	kind     string
+	key      []string
+	children []collection
+}
+
+var sections = []struct {
+	name string
+	collection
+}{
+	{models.SectionAgents, collection{field: "agents", kind: "agent", key: []string{"name"}, children: []collection{
+		{field: "activity.active_task_ids", kind: "task", key: []string{"id"}},
+	}}},
+	{models.SectionWorkload, collection{field: "workload", kind: "workload", key: []string{"deployment_name"}, children: []collection{
+		{field: "pods", kind: "pod", key: []string{"pod_id"}},
+	}}},
+	{models.SectionQueues, collection{field: "queues", kind: "queue", key: []string{"name"}, children: []collection{
+		{field: "tasks", kind: "queue_task", key: []string{"id"}},
+		{field: "consumer_groups", kind: "consumer_group", key: []string{"name"}},
+	}}},
+	{models.SectionLiteLLM, collection{field: "litellm", kind: "litellm", key: []string{"model", "provider"}}},
+}
+
+// derivedFields are computed from an entity's other fields when the
+// snapshot is taken. Durations change with every snapshot and utilization
+// only repeats the usage it is computed from, so Compute ignores them; the
+// fields they derive from are still compared.
+var derivedFields = map[string]bool{
+	"staleness_seconds": true,
+	"max_wait_seconds":  true,
+	"age_seconds":       true,
+	"duration_seconds":  true,
+	"utilization":       true,
+}
+
+// Compute returns the changes needed to go from one snapshot to the other.
+func Compute(from, to *models.SystemState) (*Diff, error) {
+	fromDoc, err := toDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	toDoc, err := toDocument(to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Diff{From: from.CapturedAt, To: to.CapturedAt, Changes: []Change{}}
+	for _, section := range sections {
+		d.Changes = append(d.Changes, diffCollection(section.name, "", section.collection,
+			lookup(fromDoc, section.field), lookup(toDoc, section.field))...)
+	}
+	return d, nil
+}
+
+func diffCollection(section, parent string, c collection, from, to interface{}) []Change {
+	fromItems, fromOrder := index(c, from)
+	toItems, toOrder := index(c, to)
+
+	var changes []Change
+	for _, key := range fromOrder {
+		if _, ok := toItems[key]; !ok {
+			old := fromItems[key]
+			changes = append(changes, Change{Op: Removed, Section: section, Kind: c.kind, Entity: parent + old.name, Value: old.value})
+		}
+	}
+
+	for _, key := range toOrder {
+		item := toItems[key]
+		old, ok := fromItems[key]
+		if !ok {
+			changes = append(changes, Change{Op: Added, Section: section, Kind: c.kind, Entity: parent + item.name, Value: item.value})
+			continue
+		}
+
+		fields := diffFields("", withoutChildren(c, old.value), withoutChildren(c, item.value))
+		if len(fields) > 0 {
+			changes = append(changes, Change{Op: Changed, Section: section, Kind: c.kind, Entity: parent + item.name, Fields: fields})
+		}
+
+		for _, child := range c.children {
+			prefix := parent + item.name + "/" + lastSegment(child.field) + "/"
+			changes = append(changes, diffCollection(section, prefix, child, lookup(old.value, child.field), lookup(item.value, child.field))...)
+		}
+	}
+	return changes
+}
+
+// entityKey matches an entity across snapshots by its identity, or by
+// position when its identity is missing or repeated.
+type entityKey struct {
+	id       [2]string
+	position int
+}
+
+// entity is an indexed entity and the name it is reported under.
+type entity struct {
+	name  string
+	value interface{}
+}
+
+// index maps each entity in a collection by its identity key, preserving
+// order. Entities with duplicate keys are disambiguated by position.
+func index(c collection, list interface{}) (map[entityKey]entity, []entityKey) {
+	items := map[entityKey]entity{}
+	var order []entityKey
+
+	values, _ := list.([]interface{})
+	for i, value := range values {
+		id, ok := identity(c.key, value)
+		key := entityKey{id: id, position: -1}
+		name := ""
+		if ok {
+			name = strings.Join(id[:len(c.key)], "/")
+		}
+		if _, dup := items[key]; dup || !ok {
+			key.position = i
+			name = fmt.Sprintf("%s#%d", name, i)
+		}
+		items[key] = entity{name: name, value: value}
+		order = append(order, key)
+	}
+	return items, order
+}
+
+// identity returns the values of an entity's key fields, of which there
+// are at most two. The values are kept apart rather than joined, since
+// names may contain any separator.
+func identity(fields []string, value interface{}) ([2]string, bool) {
+	var id [2]string
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return id, false
+	}
+
+	for i, field := range fields {
+		part, ok := object[field].(string)
+		if !ok {
+			return [2]string{}, false
+		}
+		id[i] = part
+	}
+	return id, true
+}
+
+func diffFields(prefix string, from, to interface{}) []FieldChange {
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if !fromIsObject || !toIsObject {
+		if reflect.DeepEqual(from, to) {
+			return nil
+		}
+		return []FieldChange{{Field: prefix, From: from, To: to}}
+	}
+
+	var changes []FieldChange
+	for _, key := range unionKeys(fromObject, toObject) {
+		if derivedFields[key] {
+			continue
+		}
+		changes = append(changes, diffFields(join(prefix, key), fromObject[key], toObject[key])...)
+	}
+	return changes
+}
+
+// withoutChildren returns a copy of an entity with its nested collections
+// removed, so they can be diffed as entities of their own.
+func withoutChildren(c collection, value interface{}) interface{} {
+	if len(c.children) == 0 {
+		return value
+	}
+
+	var strip func(value interface{}, path []string) interface{}
+	strip = func(value interface{}, path []string) interface{} {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		out := make(map[string]interface{}, len(object))
+		for k, v := range object {
+			out[k] = v
+		}
+		if len(path) == 1 {
+			delete(out, path[0])
+		} else if child, ok := out[path[0]]; ok {
+			out[path[0]] = strip(child, path[1:])
+		}
+		return out
+	}
+
+	for _, child := range c.children {
+		value = strip(value, strings.Split(child.field, "."))
+	}
+	return value
+}
+
+func lookup(value interface{}, path string) interface{} {
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[segment]
+	}
+	return value
+}
+
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toDocument converts a snapshot to its generic JSON form so entities are
+// compared exactly as clients see them.
+func toDocument(state *models.SystemState) (interface{}, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	return doc, nil
+}
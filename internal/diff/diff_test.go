@@ -0,0 +1,295 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+func fromState() *models.SystemState {
+	return &models.SystemState{
+		ID:         "system-1",
+		CapturedAt: time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC),
+		Agents: []models.Agent{
+			{Name: "agent-1", MaxParallelInvocations: 5, Activity: models.Activity{
+				ActiveTaskIDs: []models.TaskStatus{{ID: "task-1", Status: "running"}, {ID: "task-2", Status: "pending"}},
+			}},
+			{Name: "agent-2", MaxParallelInvocations: 3},
+		},
+		Workload: []models.Workload{
+			{DeploymentName: "deploy-1", MaxPods: 10, Pods: []models.Pod{
+				{PodID: "pod-1", CPU: 0.5, Status: "running"},
+				{PodID: "pod-2", CPU: 0.3, Status: "running"},
+			}},
+		},
+		Queues: []models.Queue{
+			{Name: "default", Tasks: []models.QueueTask{{ID: "task-4", Priority: models.Priority{Level: "high"}}}},
+		},
+		LiteLLM: []models.LiteLLM{
+			{Model: "gpt-4", Provider: "openai", TPM: 100},
+			{Model: "gpt-4", Provider: "azure", TPM: 50},
+		},
+	}
+}
+
+func toState() *models.SystemState {
+	return &models.SystemState{
+		ID:         "system-1",
+		CapturedAt: time.Date(2026, 2, 6, 10, 5, 0, 0, time.UTC),
+		Agents: []models.Agent{
+			{Name: "agent-3", MaxParallelInvocations: 1},
+			{Name: "agent-1", MaxParallelInvocations: 5, Activity: models.Activity{
+				ActiveTaskIDs: []models.TaskStatus{{ID: "task-2", Status: "running"}, {ID: "task-5", Status: "pending"}},
+			}},
+		},
+		Workload: []models.Workload{
+			{DeploymentName: "deploy-1", MaxPods: 12, Pods: []models.Pod{
+				{PodID: "pod-2", CPU: 0.9, Status: "running"},
+				{PodID: "pod-3", CPU: 0.1, Status: "pending"},
+			}},
+		},
+		Queues: []models.Queue{
+			{Name: "default", Tasks: []models.QueueTask{{ID: "task-4", Priority: models.Priority{Level: "low"}}}},
+		},
+		LiteLLM: []models.LiteLLM{
+			{Model: "gpt-4", Provider: "azure", TPM: 50},
+			{Model: "gpt-4", Provider: "openai", TPM: 200},
+		},
+	}
+}
+
+func findChange(changes []Change, op, kind, entity string) *Change {
+	for i := range changes {
+		if changes[i].Op == op && changes[i].Kind == kind && changes[i].Entity == entity {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestCompute(t *testing.T) {
+	d, err := Compute(fromState(), toState())
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	if !d.From.Equal(time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)) || !d.To.Equal(time.Date(2026, 2, 6, 10, 5, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected diff bounds %s..%s", d.From, d.To)
+	}
+
+	expected := []struct{ op, kind, entity string }{
+		{Removed, "agent", "agent-2"},
+		{Added, "agent", "agent-3"},
+		{Removed, "task", "agent-1/active_task_ids/task-1"},
+		{Changed, "task", "agent-1/active_task_ids/task-2"},
+		{Added, "task", "agent-1/active_task_ids/task-5"},
+		{Changed, "workload", "deploy-1"},
+		{Removed, "pod", "deploy-1/pods/pod-1"},
+		{Changed, "pod", "deploy-1/pods/pod-2"},
+		{Added, "pod", "deploy-1/pods/pod-3"},
+		{Changed, "queue_task", "default/tasks/task-4"},
+		{Changed, "litellm", "gpt-4/openai"},
+	}
+	for _, e := range expected {
+		if findChange(d.Changes, e.op, e.kind, e.entity) == nil {
+			t.Errorf("Missing %s %s %s in %+v", e.op, e.kind, e.entity, d.Changes)
+		}
+	}
+	if len(d.Changes) != len(expected) {
+		t.Errorf("Expected %d changes, got %d: %+v", len(expected), len(d.Changes), d.Changes)
+	}
+
+	workload := findChange(d.Changes, Changed, "workload", "deploy-1")
+	if len(workload.Fields) != 1 || workload.Fields[0].Field != "max_pods" {
+		t.Errorf("Expected only max_pods to change on the workload, got %+v", workload.Fields)
+	}
+
+	task := findChange(d.Changes, Changed, "queue_task", "default/tasks/task-4")
+	if task.Fields[0].Field != "priority.level" || task.Fields[0].From != "high" || task.Fields[0].To != "low" {
+		t.Errorf("Unexpected queue task change %+v", task.Fields)
+	}
+
+	if findChange(d.Changes, Changed, "litellm", "gpt-4/azure") != nil {
+		t.Error("Expected reordering alone not to be reported as a change")
+	}
+}
+
+func TestCompute_Identical(t *testing.T) {
+	d, err := Compute(fromState(), fromState())
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if len(d.Changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", d.Changes)
+	}
+}
+
+func TestPatch_RoundTrip(t *testing.T) {
+	from, to := fromState(), toState()
+
+	ops, err := Patch(from, to)
+	if err != nil {
+		t.Fatalf("Patch failed: %v", err)
+	}
+
+	fromDoc, _ := toDocument(from)
+	toDoc, _ := toDocument(to)
+
+	data, _ := json.Marshal(ops)
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Patch is not valid JSON: %v", err)
+	}
+
+	result := fromDoc
+	for _, op := range decoded {
+		result = applyOperation(t, result, op)
+	}
+
+	if !reflect.DeepEqual(result, toDoc) {
+		got, _ := json.Marshal(result)
+		want, _ := json.Marshal(toDoc)
+		t.Errorf("Applying the patch did not reproduce the target\n got: %s\nwant: %s\n ops: %s", got, want, data)
+	}
+}
+
+func TestPatch_PodChangeIsTargeted(t *testing.T) {
+	from := fromState()
+	to := fromState()
+	to.Workload[0].Pods[1].CPU = 0.8
+
+	ops, err := Patch(from, to)
+	if err != nil {
+		t.Fatalf("Patch failed: %v", err)
+	}
+
+	if len(ops) != 1 || ops[0].Op != "replace" || ops[0].Path != "/workload/0/pods/1/cpu" {
+		t.Errorf("Expected a single replace of pod-2 cpu, got %+v", ops)
+	}
+}
+
+// applyOperation is a minimal RFC 6902 applier covering the operations
+// Patch emits.
+func applyOperation(t *testing.T, doc interface{}, op map[string]interface{}) interface{} {
+	t.Helper()
+
+	switch op["op"] {
+	case "add":
+		return setPointer(t, doc, op["path"].(string), op["value"], true)
+	case "replace":
+		return setPointer(t, doc, op["path"].(string), op["value"], false)
+	case "remove":
+		doc, _ = removePointer(t, doc, op["path"].(string))
+		return doc
+	case "move":
+		var value interface{}
+		doc, value = removePointer(t, doc, op["from"].(string))
+		return setPointer(t, doc, op["path"].(string), value, true)
+	}
+	t.Fatalf("Unsupported op %v", op["op"])
+	return nil
+}
+
+func splitPointer(path string) (string, string) {
+	i := strings.LastIndex(path, "/")
+	last := strings.NewReplacer("~1", "/", "~0", "~").Replace(path[i+1:])
+	return path[:i], last
+}
+
+func getPointer(doc interface{}, path string) interface{} {
+	if path == "" {
+		return doc
+	}
+	parent, last := splitPointer(path)
+	container := getPointer(doc, parent)
+	switch c := container.(type) {
+	case map[string]interface{}:
+		return c[last]
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		return c[i]
+	}
+	return nil
+}
+
+func setPointer(t *testing.T, doc interface{}, path string, value interface{}, insert bool) interface{} {
+	if path == "" {
+		return value
+	}
+	parent, last := splitPointer(path)
+	switch c := getPointer(doc, parent).(type) {
+	case map[string]interface{}:
+		c[last] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		if insert {
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+		}
+		c[i] = value
+		doc = setPointer(t, doc, parent, c, false)
+	default:
+		t.Fatalf("Cannot set %s", path)
+	}
+	return doc
+}
+
+func removePointer(t *testing.T, doc interface{}, path string) (interface{}, interface{}) {
+	parent, last := splitPointer(path)
+	switch c := getPointer(doc, parent).(type) {
+	case map[string]interface{}:
+		value := c[last]
+		delete(c, last)
+		return doc, value
+	case []interface{}:
+		i, _ := strconv.Atoi(last)
+		value := c[i]
+		c = append(c[:i:i], c[i+1:]...)
+		return setPointer(t, doc, parent, c, false), value
+	}
+	t.Fatalf("Cannot remove %s", path)
+	return nil, nil
+}
+
+func TestCompute_IgnoresDerivedDurations(t *testing.T) {
+	from, to := fromState(), fromState()
+	to.Agents[0].Activity.Staleness = models.Duration(time.Minute)
+	to.Queues[0].MaxWait = models.Duration(time.Minute)
+	to.Queues[0].Tasks[0].Age = models.Duration(time.Minute)
+
+	d, err := Compute(from, to)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if len(d.Changes) != 0 {
+		t.Errorf("Expected derived durations not to be reported, got %+v", d.Changes)
+	}
+}
+
+func TestCompute_CompositeKeysDoNotCollide(t *testing.T) {
+	// Joined with "/", both entries would be identified as "a/b/c".
+	from, to := fromState(), fromState()
+	from.LiteLLM = []models.LiteLLM{{Model: "a/b", Provider: "c", TPM: 1}, {Model: "a", Provider: "b/c", TPM: 2}}
+	to.LiteLLM = []models.LiteLLM{{Model: "a", Provider: "b/c", TPM: 2}, {Model: "a/b", Provider: "c", TPM: 3}}
+
+	d, err := Compute(from, to)
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if len(d.Changes) != 1 || d.Changes[0].Entity != "a/b/c" || d.Changes[0].Fields[0].Field != "tpm" {
+		t.Fatalf("Expected only a/b on c to change, got %+v", d.Changes)
+	}
+
+	ops, err := Patch(from, to)
+	if err != nil {
+		t.Fatalf("Patch failed: %v", err)
+	}
+	if len(ops) != 2 || ops[0].Op != "move" || ops[1].Path != "/litellm/1/tpm" {
+		t.Errorf("Expected a move and a tpm replace, got %+v", ops)
+	}
+}
@@ -0,0 +1,176 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"telemetron/internal/models"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value interface{}
+}
+
+// MarshalJSON emits only the members each operation defines, so a replace
+// with a null value still carries "value".
+func (o Operation) MarshalJSON() ([]byte, error) {
+	out := map[string]interface{}{"op": o.Op, "path": o.Path}
+	switch o.Op {
+	case "add", "replace", "test":
+		out["value"] = o.Value
+	case "move", "copy":
+		out["from"] = o.From
+	}
+	return json.Marshal(out)
+}
+
+// keyedArrays maps array locations, with indices written as "*", to the
+// identity fields used to match their elements.
+var keyedArrays = map[string][]string{
+	"/agents":                            {"name"},
+	"/agents/*/activity/active_task_ids": {"id"},
+	"/workload":                          {"deployment_name"},
+	"/workload/*/pods":                   {"pod_id"},
+	"/queues":                            {"name"},
+	"/queues/*/tasks":                    {"id"},
+	"/queues/*/consumer_groups":          {"name"},
+	"/litellm":                           {"model", "provider"},
+}
+
+// Patch returns a JSON Patch that transforms the from snapshot into the to
+// snapshot. Keyed lists are patched element by element, so reordering or
+// inserting an entity does not rewrite its neighbours.
+func Patch(from, to *models.SystemState) ([]Operation, error) {
+	fromDoc, err := toDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	toDoc, err := toDocument(to)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := []Operation{}
+	patchValue(&ops, "", "", fromDoc, toDoc)
+	return ops, nil
+}
+
+func patchValue(ops *[]Operation, path, shape string, from, to interface{}) {
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		if toValue, ok := to.(map[string]interface{}); ok {
+			patchObject(ops, path, shape, fromValue, toValue)
+			return
+		}
+	case []interface{}:
+		if toValue, ok := to.([]interface{}); ok {
+			if key, ok := keyedArrays[shape]; ok && uniqueKeys(key, fromValue) && uniqueKeys(key, toValue) {
+				patchKeyedArray(ops, path, shape, key, fromValue, toValue)
+				return
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*ops = append(*ops, Operation{Op: "replace", Path: path, Value: to})
+	}
+}
+
+func patchObject(ops *[]Operation, path, shape string, from, to map[string]interface{}) {
+	for _, key := range unionKeys(from, to) {
+		childPath := path + "/" + escapePointer(key)
+		childShape := shape + "/" + escapePointer(key)
+
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		switch {
+		case !inTo:
+			*ops = append(*ops, Operation{Op: "remove", Path: childPath})
+		case !inFrom:
+			*ops = append(*ops, Operation{Op: "add", Path: childPath, Value: toValue})
+		default:
+			patchValue(ops, childPath, childShape, fromValue, toValue)
+		}
+	}
+}
+
+// patchKeyedArray removes vanished elements, then walks the target order
+// moving, adding and recursing so the result matches to exactly.
+func patchKeyedArray(ops *[]Operation, path, shape string, key []string, from, to []interface{}) {
+	wanted := make(map[[2]string]bool, len(to))
+	for _, item := range to {
+		id, _ := identity(key, item)
+		wanted[id] = true
+	}
+
+	byKey := make(map[[2]string]interface{}, len(from))
+	current := make([][2]string, 0, len(from))
+	for _, item := range from {
+		id, _ := identity(key, item)
+		byKey[id] = item
+		current = append(current, id)
+	}
+
+	for i := len(current) - 1; i >= 0; i-- {
+		if !wanted[current[i]] {
+			*ops = append(*ops, Operation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+			current = append(current[:i], current[i+1:]...)
+		}
+	}
+
+	for i, item := range to {
+		id, _ := identity(key, item)
+		elementPath := path + "/" + strconv.Itoa(i)
+
+		old, existed := byKey[id]
+		if !existed {
+			*ops = append(*ops, Operation{Op: "add", Path: elementPath, Value: item})
+			current = insertAt(current, i, id)
+			continue
+		}
+
+		if j := indexOf(current, id); j != i {
+			*ops = append(*ops, Operation{Op: "move", From: path + "/" + strconv.Itoa(j), Path: elementPath})
+			current = insertAt(append(current[:j], current[j+1:]...), i, id)
+		}
+		patchValue(ops, elementPath, shape+"/*", old, item)
+	}
+}
+
+func uniqueKeys(key []string, items []interface{}) bool {
+	seen := make(map[[2]string]bool, len(items))
+	for _, item := range items {
+		id, ok := identity(key, item)
+		if !ok || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+func indexOf(keys [][2]string, key [2]string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func insertAt(keys [][2]string, i int, key [2]string) [][2]string {
+	keys = append(keys, [2]string{})
+	copy(keys[i+1:], keys[i:])
+	keys[i] = key
+	return keys
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
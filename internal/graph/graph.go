@@ -0,0 +1,242 @@
+// Package graph correlates the independent sections of a system snapshot:
+// agents with the workloads they are deployed as and the models they call,
+// and agent tasks with the queues and queue tasks they came from.
+package graph
+
+import (
+	"time"
+
+	"telemetron/internal/models"
+)
+
+// Node kinds.
+const (
+	KindAgent     = "agent"
+	KindTask      = "task"
+	KindWorkload  = "workload"
+	KindPod       = "pod"
+	KindQueue     = "queue"
+	KindQueueTask = "queue_task"
+	KindModel     = "model"
+)
+
+// Edge kinds.
+const (
+	EdgeDeployedAs = "deployed_as" // agent -> workload
+	EdgeHasPod     = "has_pod"     // workload -> pod
+	EdgeUsesModel  = "uses_model"  // agent -> model
+	EdgeRuns       = "runs"        // agent -> task
+	EdgeFromQueue  = "from_queue"  // task -> queue
+	EdgeSpawnedBy  = "spawned_by"  // task -> queue_task
+	EdgeContains   = "contains"    // queue -> queue_task
+)
+
+// Graph is the entity graph of one snapshot. Entities that are referenced
+// but absent from their section are still nodes, marked missing, and the
+// references to them are listed in Dangling. References into a section that
+// failed to load are never dangling, as its entities are unknown.
+type Graph struct {
+	CapturedAt time.Time   `json:"captured_at"`
+	Nodes      []Node      `json:"nodes"`
+	Edges      []Edge      `json:"edges"`
+	Dangling   []Reference `json:"dangling"`
+}
+
+// Node is one entity. IDs are the kind and the entity's key, e.g.
+// "agent:agent-1" or "pod:agent-deployment-1/pod-1". Tasks are keyed by
+// their agent too, as in "task:agent-1/task-1", since task IDs are only
+// unique per agent.
+type Node struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Status  string `json:"status,omitempty"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Reference is a reference from one entity to another that the snapshot
+// does not contain, such as an agent's deployment missing from the
+// workload section.
+type Reference struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// NodeID returns the ID of the node for the entity of a kind with key.
+func NodeID(kind, key string) string {
+	return kind + ":" + key
+}
+
+// index looks up a snapshot's entities by the keys other sections use to
+// refer to them.
+type index struct {
+	workloads map[string]*models.Workload
+	models    map[string][]models.LiteLLM
+	queues    map[string]*models.Queue
+
+	// queueTasks holds each queued task by queue and ID; taskQueues
+	// the first queue holding a task ID.
+	queueTasks map[[2]string]queueTask
+	taskQueues map[string]string
+}
+
+// queueTask is a task waiting in a named queue.
+type queueTask struct {
+	queue string
+	task  models.QueueTask
+}
+
+func newIndex(state *models.SystemState) *index {
+	idx := &index{
+		workloads:  make(map[string]*models.Workload, len(state.Workload)),
+		models:     make(map[string][]models.LiteLLM, len(state.LiteLLM)),
+		queues:     make(map[string]*models.Queue, len(state.Queues)),
+		queueTasks: make(map[[2]string]queueTask),
+		taskQueues: make(map[string]string),
+	}
+	for i := range state.Workload {
+		idx.workloads[state.Workload[i].DeploymentName] = &state.Workload[i]
+	}
+	for _, entry := range state.LiteLLM {
+		idx.models[entry.Model] = append(idx.models[entry.Model], entry)
+	}
+	for i := range state.Queues {
+		queue := &state.Queues[i]
+		idx.queues[queue.Name] = queue
+		for _, task := range queue.Tasks {
+			idx.queueTasks[[2]string{queue.Name, task.ID}] = queueTask{queue: queue.Name, task: task}
+			if _, ok := idx.taskQueues[task.ID]; !ok {
+				idx.taskQueues[task.ID] = queue.Name
+			}
+		}
+	}
+	return idx
+}
+
+// spawnedBy returns the queued task an agent task came from: the one it
+// names, or else one with the same ID. Tasks already consumed from their
+// queue are not found.
+func (idx *index) spawnedBy(task models.TaskStatus) (queueTask, bool) {
+	id := task.QueueTaskID
+	if id == "" {
+		id = task.ID
+	}
+	queue := task.Queue
+	if queue == "" {
+		queue = idx.taskQueues[id]
+	}
+	qt, ok := idx.queueTasks[[2]string{queue, id}]
+	return qt, ok
+}
+
+func queueTaskKey(queue, id string) string {
+	return queue + "/" + id
+}
+
+// builder accumulates nodes and edges, adding each node once. Entities
+// present in their sections are added before the references to them.
+type builder struct {
+	graph *Graph
+	nodes map[string]bool
+}
+
+func (b *builder) node(n Node) string {
+	if !b.nodes[n.ID] {
+		b.nodes[n.ID] = true
+		b.graph.Nodes = append(b.graph.Nodes, n)
+	}
+	return n.ID
+}
+
+func (b *builder) edge(from, to, kind string) {
+	b.graph.Edges = append(b.graph.Edges, Edge{From: from, To: to, Kind: kind})
+}
+
+func (b *builder) dangling(from, to, reason string) {
+	b.graph.Dangling = append(b.graph.Dangling, Reference{From: from, To: to, Reason: reason})
+}
+
+// Build returns the entity graph of a snapshot.
+func Build(state *models.SystemState) *Graph {
+	// failed reports whether a section could not be loaded at all, so that
+	// its entities may exist without being listed.
+	failed := func(section string) bool {
+		status, ok := state.Status[section]
+		return ok && status.Status == models.SectionError
+	}
+	idx := newIndex(state)
+	b := &builder{
+		graph: &Graph{CapturedAt: state.CapturedAt, Nodes: []Node{}, Edges: []Edge{}, Dangling: []Reference{}},
+		nodes: make(map[string]bool),
+	}
+
+	for _, workload := range state.Workload {
+		id := b.node(Node{ID: NodeID(KindWorkload, workload.DeploymentName), Kind: KindWorkload, Name: workload.DeploymentName})
+		for _, pod := range workload.Pods {
+			podID := b.node(Node{ID: NodeID(KindPod, workload.DeploymentName+"/"+pod.PodID), Kind: KindPod, Name: pod.PodID, Status: pod.Status})
+			b.edge(id, podID, EdgeHasPod)
+		}
+	}
+	for _, queue := range state.Queues {
+		id := b.node(Node{ID: NodeID(KindQueue, queue.Name), Kind: KindQueue, Name: queue.Name})
+		for _, task := range queue.Tasks {
+			taskID := b.node(Node{ID: NodeID(KindQueueTask, queueTaskKey(queue.Name, task.ID)), Kind: KindQueueTask, Name: task.ID})
+			b.edge(id, taskID, EdgeContains)
+		}
+	}
+	for _, entry := range state.LiteLLM {
+		b.node(Node{ID: NodeID(KindModel, entry.Model), Kind: KindModel, Name: entry.Model})
+	}
+
+	for _, agent := range state.Agents {
+		id := b.node(Node{ID: NodeID(KindAgent, agent.Name), Kind: KindAgent, Name: agent.Name})
+
+		if agent.DeploymentName != "" {
+			_, ok := idx.workloads[agent.DeploymentName]
+			ok = ok || failed(models.SectionWorkload)
+			to := b.node(Node{ID: NodeID(KindWorkload, agent.DeploymentName), Kind: KindWorkload, Name: agent.DeploymentName, Missing: !ok})
+			b.edge(id, to, EdgeDeployedAs)
+			if !ok {
+				b.dangling(id, to, "deployment "+agent.DeploymentName+" is not in the workload section")
+			}
+		}
+
+		for _, model := range agent.Models {
+			_, ok := idx.models[model]
+			ok = ok || failed(models.SectionLiteLLM)
+			to := b.node(Node{ID: NodeID(KindModel, model), Kind: KindModel, Name: model, Missing: !ok})
+			b.edge(id, to, EdgeUsesModel)
+			if !ok {
+				b.dangling(id, to, "model "+model+" is not configured in LiteLLM")
+			}
+		}
+
+		for _, task := range agent.Activity.ActiveTaskIDs {
+			taskID := b.node(Node{ID: NodeID(KindTask, agent.Name+"/"+task.ID), Kind: KindTask, Name: task.ID, Status: string(task.Status)})
+			b.edge(id, taskID, EdgeRuns)
+
+			if qt, ok := idx.spawnedBy(task); ok {
+				b.edge(taskID, NodeID(KindQueue, qt.queue), EdgeFromQueue)
+				b.edge(taskID, NodeID(KindQueueTask, queueTaskKey(qt.queue, qt.task.ID)), EdgeSpawnedBy)
+			} else if task.Queue != "" {
+				// The queue task has been consumed; the queue itself must
+				// still exist.
+				_, ok := idx.queues[task.Queue]
+				ok = ok || failed(models.SectionQueues)
+				to := b.node(Node{ID: NodeID(KindQueue, task.Queue), Kind: KindQueue, Name: task.Queue, Missing: !ok})
+				b.edge(taskID, to, EdgeFromQueue)
+				if !ok {
+					b.dangling(taskID, to, "queue "+task.Queue+" is not in the queues section")
+				}
+			}
+		}
+	}
+	return b.graph
+}
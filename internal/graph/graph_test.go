@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"testing"
+
+	"telemetron/internal/models"
+)
+
+func testState() *models.SystemState {
+	return &models.SystemState{
+		Agents: []models.Agent{{
+			Name:           "agent-1",
+			DeploymentName: "deploy-1",
+			Models:         []string{"gpt-4", "unknown-model"},
+			Activity: models.Activity{ActiveTaskIDs: []models.TaskStatus{
+				{ID: "task-1", Status: models.TaskRunning},
+				{ID: "task-2", Status: models.TaskRunning, Queue: "default", QueueTaskID: "q-2"},
+				{ID: "task-3", Status: models.TaskCompleted, Queue: "gone"},
+			}},
+		}, {
+			Name:           "agent-2",
+			DeploymentName: "missing-deploy",
+		}},
+		Workload: []models.Workload{{
+			DeploymentName: "deploy-1",
+			Pods:           []models.Pod{{PodID: "pod-1", Status: "running"}},
+		}},
+		Queues: []models.Queue{{
+			Name: "default",
+			Tasks: []models.QueueTask{
+				{ID: "task-1", Priority: models.Priority{Level: "high"}},
+				{ID: "q-2"},
+			},
+		}},
+		LiteLLM: []models.LiteLLM{
+			{Model: "gpt-4", Provider: "openai", TPM: 750, TPMMax: 1000, RPM: 10},
+			{Model: "gpt-4", Provider: "azure", TPM: 100, TPMMax: 1000},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	graph := Build(testState())
+
+	nodes := make(map[string]Node)
+	for _, node := range graph.Nodes {
+		if _, ok := nodes[node.ID]; ok {
+			t.Errorf("Node %s added twice", node.ID)
+		}
+		nodes[node.ID] = node
+	}
+	for _, id := range []string{"agent:agent-1", "task:agent-1/task-1", "workload:deploy-1", "pod:deploy-1/pod-1",
+		"queue:default", "queue_task:default/task-1", "model:gpt-4"} {
+		if node, ok := nodes[id]; !ok || node.Missing {
+			t.Errorf("Expected node %s to be present, got %+v", id, node)
+		}
+	}
+	for _, id := range []string{"workload:missing-deploy", "model:unknown-model", "queue:gone"} {
+		if !nodes[id].Missing {
+			t.Errorf("Expected node %s to be missing, got %+v", id, nodes[id])
+		}
+	}
+
+	edges := make(map[Edge]bool)
+	for _, edge := range graph.Edges {
+		edges[edge] = true
+	}
+	for _, edge := range []Edge{
+		{"agent:agent-1", "workload:deploy-1", EdgeDeployedAs},
+		{"workload:deploy-1", "pod:deploy-1/pod-1", EdgeHasPod},
+		{"agent:agent-1", "model:gpt-4", EdgeUsesModel},
+		{"agent:agent-1", "task:agent-1/task-1", EdgeRuns},
+		{"task:agent-1/task-1", "queue:default", EdgeFromQueue},
+		{"task:agent-1/task-1", "queue_task:default/task-1", EdgeSpawnedBy},
+		{"task:agent-1/task-2", "queue_task:default/q-2", EdgeSpawnedBy},
+		{"task:agent-1/task-3", "queue:gone", EdgeFromQueue},
+		{"queue:default", "queue_task:default/q-2", EdgeContains},
+	} {
+		if !edges[edge] {
+			t.Errorf("Expected edge %+v", edge)
+		}
+	}
+
+	want := map[Reference]bool{
+		{From: "agent:agent-1", To: "model:unknown-model"}:     true,
+		{From: "task:agent-1/task-3", To: "queue:gone"}:        true,
+		{From: "agent:agent-2", To: "workload:missing-deploy"}: true,
+	}
+	if len(graph.Dangling) != len(want) {
+		t.Fatalf("Expected %d dangling references, got %+v", len(want), graph.Dangling)
+	}
+	for _, ref := range graph.Dangling {
+		if !want[Reference{From: ref.From, To: ref.To}] || ref.Reason == "" {
+			t.Errorf("Unexpected dangling reference %+v", ref)
+		}
+	}
+}
+
+func TestBuild_SectionErrors(t *testing.T) {
+	state := testState()
+	state.Agents[1].Activity.ActiveTaskIDs = []models.TaskStatus{{ID: "task-1", Status: models.TaskRunning}}
+	state.Queues, state.LiteLLM = nil, nil
+	state.Status = map[string]models.SectionStatus{
+		models.SectionQueues:  {Status: models.SectionError},
+		models.SectionLiteLLM: {Status: models.SectionError},
+	}
+	graph := Build(state)
+
+	nodes := make(map[string]Node)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	if node, ok := nodes["queue:gone"]; !ok || node.Missing {
+		t.Errorf("Expected a queue of a failed section not to be missing, got %+v", node)
+	}
+	if _, ok := nodes["task:agent-2/task-1"]; !ok {
+		t.Error("Expected tasks of different agents with one ID to be separate nodes")
+	}
+	if len(graph.Dangling) != 1 || graph.Dangling[0].To != "workload:missing-deploy" {
+		t.Errorf("Expected only references into loaded sections to dangle, got %+v", graph.Dangling)
+	}
+}
+
+func TestViewAgent(t *testing.T) {
+	view, ok := ViewAgent(testState(), "agent-1")
+	if !ok {
+		t.Fatal("Expected agent-1 to be found")
+	}
+
+	if view.Workload == nil || view.Workload.DeploymentName != "deploy-1" || len(view.Workload.Pods) != 1 {
+		t.Errorf("Expected the agent's workload and pods, got %+v", view.Workload)
+	}
+
+	if len(view.Models) != 2 || len(view.Models[0].Providers) != 2 || len(view.Models[1].Providers) != 0 {
+		t.Fatalf("Expected gpt-4 with two providers and an unconfigured model, got %+v", view.Models)
+	}
+	if tpm := view.Models[0].Providers[0].TPM; tpm.Headroom != 250 || tpm.Ratio != 0.75 {
+		t.Errorf("Expected 250 TPM of headroom, got %+v", tpm)
+	}
+
+	if queued := view.Tasks[0].QueueTask; queued == nil || queued.Priority.Level != "high" || view.Tasks[0].Queue != "default" {
+		t.Errorf("Expected task-1 to be correlated with its queued task, got %+v", view.Tasks[0])
+	}
+	if view.Tasks[1].QueueTask == nil || view.Tasks[1].QueueTask.ID != "q-2" {
+		t.Errorf("Expected task-2 to be correlated with q-2, got %+v", view.Tasks[1])
+	}
+	if view.Tasks[2].QueueTask != nil {
+		t.Errorf("Expected task-3 to have no queued task, got %+v", view.Tasks[2])
+	}
+
+	if len(view.Dangling) != 2 {
+		t.Errorf("Expected only agent-1's dangling references, got %+v", view.Dangling)
+	}
+
+	if _, ok := ViewAgent(testState(), "agent-9"); ok {
+		t.Error("Expected an unknown agent not to be found")
+	}
+}
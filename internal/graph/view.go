@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"time"
+
+	"telemetron/internal/models"
+)
+
+// AgentView follows an agent's references: the workload it is deployed as
+// with its pods' utilization, the rate limit headroom of its models, and
+// the queue tasks its tasks came from.
+type AgentView struct {
+	CapturedAt time.Time        `json:"captured_at"`
+	Agent      models.Agent     `json:"agent"`
+	Workload   *models.Workload `json:"workload,omitempty"`
+	Models     []ModelView      `json:"models"`
+	Tasks      []TaskView       `json:"tasks"`
+	Dangling   []Reference      `json:"dangling"`
+}
+
+// ModelView is a model an agent calls and its usage with every provider
+// that serves it; a model without providers is not configured in LiteLLM.
+type ModelView struct {
+	Model     string          `json:"model"`
+	Providers []ProviderUsage `json:"providers"`
+}
+
+// ProviderUsage is a model's tokens and requests per minute with one
+// provider against its rate limits.
+type ProviderUsage struct {
+	Provider string               `json:"provider"`
+	TPM      models.ResourceUsage `json:"tpm"`
+	RPM      models.ResourceUsage `json:"rpm"`
+}
+
+// TaskView is an agent task with the queued task it came from, when that
+// is still in its queue.
+type TaskView struct {
+	models.TaskStatus
+	QueueTask *models.QueueTask `json:"queue_task,omitempty"`
+}
+
+// ViewAgent returns the view of the named agent, or false when the
+// snapshot has no such agent.
+func ViewAgent(state *models.SystemState, name string) (*AgentView, bool) {
+	var agent *models.Agent
+	for i := range state.Agents {
+		if state.Agents[i].Name == name {
+			agent = &state.Agents[i]
+			break
+		}
+	}
+	if agent == nil {
+		return nil, false
+	}
+
+	idx := newIndex(state)
+	view := &AgentView{
+		CapturedAt: state.CapturedAt,
+		Agent:      *agent,
+		Models:     []ModelView{},
+		Tasks:      []TaskView{},
+		Dangling:   []Reference{},
+	}
+	if workload, ok := idx.workloads[agent.DeploymentName]; ok && agent.DeploymentName != "" {
+		view.Workload = workload
+	}
+
+	for _, model := range agent.Models {
+		entries := idx.models[model]
+		modelView := ModelView{Model: model, Providers: []ProviderUsage{}}
+		for _, entry := range entries {
+			modelView.Providers = append(modelView.Providers, ProviderUsage{
+				Provider: entry.Provider,
+				TPM:      models.NewResourceUsage(float64(entry.TPM), float64(entry.TPMMax)),
+				RPM:      models.NewResourceUsage(float64(entry.RPM), float64(entry.RPMMax)),
+			})
+		}
+		view.Models = append(view.Models, modelView)
+	}
+
+	own := map[string]bool{NodeID(KindAgent, agent.Name): true}
+	for _, task := range agent.Activity.ActiveTaskIDs {
+		taskView := TaskView{TaskStatus: task}
+		if qt, ok := idx.spawnedBy(task); ok {
+			queued := qt.task
+			taskView.QueueTask = &queued
+			taskView.Queue = qt.queue
+		}
+		view.Tasks = append(view.Tasks, taskView)
+		own[NodeID(KindTask, agent.Name+"/"+task.ID)] = true
+	}
+
+	for _, ref := range Build(state).Dangling {
+		if own[ref.From] {
+			view.Dangling = append(view.Dangling, ref)
+		}
+	}
+	return view, true
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/logger"
+)
+
+// StateSource produces the live system state to record.
+type StateSource interface {
+	GetSystemState(ctx context.Context) (*models.SystemState, error)
+}
+
+// Retention bounds how much history is kept. Zero values disable the
+// corresponding limit.
+type Retention struct {
+	MaxAge       time.Duration
+	MaxSnapshots int
+}
+
+// Snapshotter records the live state into a Store at a fixed interval.
+type Snapshotter struct {
+	source    StateSource
+	store     *Store
+	interval  time.Duration
+	retention Retention
+	now       func() time.Time
+}
+
+func NewSnapshotter(source StateSource, store *Store, interval time.Duration, retention Retention) *Snapshotter {
+	return &Snapshotter{
+		source:    source,
+		store:     store,
+		interval:  interval,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Run records a snapshot immediately and then every interval until ctx is
+// done.
+func (s *Snapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Capture(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Error("Failed to record system state snapshot", zap.Error(err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Capture records one snapshot and applies the retention policy.
+func (s *Snapshotter) Capture(ctx context.Context) error {
+	state, err := s.source.GetSystemState(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if err := s.store.Save(now, state); err != nil {
+		return err
+	}
+
+	cutoff := time.Time{}
+	if s.retention.MaxAge > 0 {
+		cutoff = now.Add(-s.retention.MaxAge)
+	}
+	_, err = s.store.Prune(cutoff, s.retention.MaxSnapshots)
+	return err
+}
@@ -0,0 +1,189 @@
+// Package history persists SystemState snapshots so past states can be
+// queried and compared.
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"telemetron/internal/models"
+)
+
+var snapshotsBucket = []byte("snapshots")
+
+// ErrNotFound is returned when no snapshot matches a query.
+var ErrNotFound = errors.New("no snapshot found")
+
+// Snapshot is a SystemState recorded at a point in time.
+type Snapshot struct {
+	CapturedAt time.Time          `json:"captured_at"`
+	State      models.SystemState `json:"state"`
+}
+
+// Store is an embedded BoltDB file of snapshots keyed by capture time.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the store at path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open history store: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initialize history store: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Save records state as captured at the given time.
+func (s *Store) Save(at time.Time, state *models.SystemState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).Put(timeKey(at), data)
+	})
+}
+
+// Nearest returns the snapshot captured closest to at, before or after.
+func (s *Store) Nearest(at time.Time) (*Snapshot, error) {
+	var snapshot *Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(snapshotsBucket).Cursor()
+
+		afterKey, afterValue := c.Seek(timeKey(at))
+		var beforeKey, beforeValue []byte
+		if afterKey != nil {
+			beforeKey, beforeValue = c.Prev()
+		} else {
+			beforeKey, beforeValue = c.Last()
+		}
+
+		key, value := afterKey, afterValue
+		switch {
+		case afterKey == nil && beforeKey == nil:
+			return ErrNotFound
+		case afterKey == nil:
+			key, value = beforeKey, beforeValue
+		case beforeKey != nil && at.Sub(keyTime(beforeKey)) <= keyTime(afterKey).Sub(at):
+			key, value = beforeKey, beforeValue
+		}
+
+		var err error
+		snapshot, err = decode(key, value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Range returns up to limit snapshots captured within [from, to], oldest
+// first. A zero limit returns all of them.
+func (s *Store) Range(from, to time.Time, limit int) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(snapshotsBucket).Cursor()
+		end := timeKey(to)
+
+		for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k, end) <= 0; k, v = c.Next() {
+			snapshot, err := decode(k, v)
+			if err != nil {
+				return err
+			}
+			snapshots = append(snapshots, *snapshot)
+			if limit > 0 && len(snapshots) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// Prune deletes snapshots captured before cutoff and, when keep is
+// positive, the oldest snapshots beyond the newest keep. It returns the
+// number of snapshots deleted.
+func (s *Store) Prune(cutoff time.Time, keep int) (int, error) {
+	deleted := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(snapshotsBucket)
+		excess := 0
+		if keep > 0 {
+			excess = bucket.Stats().KeyN - keep
+		}
+
+		c := bucket.Cursor()
+		limit := timeKey(cutoff)
+		for k, _ := c.First(); k != nil; k, _ = c.First() {
+			if bytes.Compare(k, limit) >= 0 && deleted >= excess {
+				break
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("prune history: %w", err)
+	}
+	return deleted, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// timeKey encodes t as a big-endian nanosecond timestamp so keys sort
+// chronologically. Times outside the representable range are clamped.
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	switch {
+	case t.Before(time.Unix(0, 0)):
+		// all zero bytes
+	case t.After(time.Unix(0, math.MaxInt64)):
+		binary.BigEndian.PutUint64(key, math.MaxInt64)
+	default:
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key))).UTC()
+}
+
+func decode(key, value []byte) (*Snapshot, error) {
+	snapshot := &Snapshot{CapturedAt: keyTime(key)}
+	if err := json.Unmarshal(value, &snapshot.State); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	return snapshot, nil
+}
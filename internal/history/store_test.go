@@ -0,0 +1,150 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+var base = time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func saveAt(t *testing.T, store *Store, minutes int) {
+	t.Helper()
+	state := &models.SystemState{ID: "system-1", Agents: []models.Agent{{Name: "agent-1", MaxParallelInvocations: minutes}}}
+	if err := store.Save(base.Add(time.Duration(minutes)*time.Minute), state); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+}
+
+func TestStoreNearest(t *testing.T) {
+	store := openTestStore(t)
+
+	if _, err := store.Nearest(base); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound on empty store, got %v", err)
+	}
+
+	saveAt(t, store, 0)
+	saveAt(t, store, 10)
+	saveAt(t, store, 20)
+
+	cases := []struct {
+		at   time.Duration
+		want int
+	}{
+		{-time.Hour, 0},
+		{4 * time.Minute, 0},
+		{6 * time.Minute, 10},
+		{10 * time.Minute, 10},
+		{5 * time.Minute, 0},
+		{time.Hour, 20},
+	}
+
+	for _, tc := range cases {
+		snapshot, err := store.Nearest(base.Add(tc.at))
+		if err != nil {
+			t.Fatalf("Nearest(%v) failed: %v", tc.at, err)
+		}
+		want := base.Add(time.Duration(tc.want) * time.Minute)
+		if !snapshot.CapturedAt.Equal(want) {
+			t.Errorf("Nearest(%v) = %v, want %v", tc.at, snapshot.CapturedAt, want)
+		}
+		if snapshot.State.Agents[0].MaxParallelInvocations != tc.want {
+			t.Errorf("Nearest(%v) returned the wrong state", tc.at)
+		}
+	}
+}
+
+func TestStoreRange(t *testing.T) {
+	store := openTestStore(t)
+	for _, m := range []int{0, 10, 20, 30} {
+		saveAt(t, store, m)
+	}
+
+	snapshots, err := store.Range(base.Add(5*time.Minute), base.Add(20*time.Minute), 0)
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+	if len(snapshots) != 2 || !snapshots[0].CapturedAt.Equal(base.Add(10*time.Minute)) {
+		t.Errorf("Expected snapshots at 10 and 20 minutes, got %+v", snapshots)
+	}
+
+	snapshots, _ = store.Range(time.Time{}, base.Add(time.Hour), 3)
+	if len(snapshots) != 3 {
+		t.Errorf("Expected limit to cap results at 3, got %d", len(snapshots))
+	}
+
+	snapshots, _ = store.Range(base.Add(time.Hour), base.Add(2*time.Hour), 0)
+	if snapshots == nil || len(snapshots) != 0 {
+		t.Errorf("Expected empty non-nil result, got %v", snapshots)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	store := openTestStore(t)
+	for _, m := range []int{0, 10, 20, 30, 40} {
+		saveAt(t, store, m)
+	}
+
+	deleted, err := store.Prune(base.Add(15*time.Minute), 0)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 snapshots older than the cutoff deleted, got %d", deleted)
+	}
+
+	deleted, _ = store.Prune(time.Time{}, 1)
+	if deleted != 2 {
+		t.Errorf("Expected 2 snapshots beyond the newest 1 deleted, got %d", deleted)
+	}
+
+	snapshots, _ := store.Range(time.Time{}, base.Add(time.Hour), 0)
+	if len(snapshots) != 1 || !snapshots[0].CapturedAt.Equal(base.Add(40*time.Minute)) {
+		t.Errorf("Expected only the newest snapshot to remain, got %+v", snapshots)
+	}
+}
+
+type staticSource struct {
+	state *models.SystemState
+}
+
+func (s staticSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	return s.state, nil
+}
+
+func TestSnapshotterCapture(t *testing.T) {
+	store := openTestStore(t)
+	snapshotter := NewSnapshotter(staticSource{&models.SystemState{ID: "system-1"}}, store, time.Minute, Retention{MaxSnapshots: 2})
+
+	now := base
+	snapshotter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := snapshotter.Capture(context.Background()); err != nil {
+			t.Fatalf("Capture failed: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	snapshots, _ := store.Range(time.Time{}, base.Add(time.Hour), 0)
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected retention to keep 2 snapshots, got %d", len(snapshots))
+	}
+	if snapshots[0].State.ID != "system-1" || !snapshots[0].CapturedAt.Equal(base.Add(time.Minute)) {
+		t.Errorf("Unexpected oldest snapshot %+v", snapshots[0])
+	}
+}
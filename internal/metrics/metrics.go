@@ -0,0 +1,62 @@
+// Package metrics exposes the system state and Telemetron's own runtime in
+// Prometheus exposition format.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns the registry behind /metrics and the HTTP instrumentation
+// for Telemetron's own handlers.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// New registers the state collector, Go runtime and process collectors,
+// and HTTP handler metrics. Each scrape reads the state from source,
+// bounded by timeout.
+func New(source StateSource, timeout time.Duration) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served by Telemetron.",
+		}, []string{"handler", "code", "method"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests served by Telemetron.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"handler", "code", "method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newStateCollector(source, timeout),
+		m.requests,
+		m.duration,
+	)
+	return m
+}
+
+// Handler serves the registry in Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument wraps h so its requests are counted and timed under the
+// given handler label.
+func (m *Metrics) Instrument(handler string, h http.Handler) http.Handler {
+	labels := prometheus.Labels{"handler": handler}
+	return promhttp.InstrumentHandlerCounter(m.requests.MustCurryWith(labels),
+		promhttp.InstrumentHandlerDuration(m.duration.MustCurryWith(labels), h))
+}
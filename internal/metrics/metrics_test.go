@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"telemetron/internal/models"
+)
+
+type staticSource struct {
+	state *models.SystemState
+	err   error
+}
+
+func (s staticSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	return s.state, s.err
+}
+
+func sampleState() *models.SystemState {
+	return &models.SystemState{
+		Agents: []models.Agent{{Name: "agent-1", MaxParallelInvocations: 4, Activity: models.Activity{
+			ActiveTaskIDs: []models.TaskStatus{{ID: "task-1", Status: "running"}, {ID: "task-2", Status: "pending"}},
+		}}},
+		Workload: []models.Workload{{DeploymentName: "deploy-1", MaxPods: 10, Live: models.LiveWorkload{ActivePods: 1},
+			Pods: []models.Pod{{PodID: "pod-1", CPU: 0.5, Memory: 256, Status: "running"}},
+		}},
+		Queues: []models.Queue{{Name: "default", Tasks: []models.QueueTask{
+			{ID: "task-3", Priority: models.Priority{Level: "high"}},
+			{ID: "task-4", Priority: models.Priority{Level: "high"}},
+		}}},
+		LiteLLM: []models.LiteLLM{{Model: "gpt-4", Provider: "openai", TPM: 500, TPMMax: 1000, RPM: 10, RPMMax: 100}},
+		Status: map[string]models.SectionStatus{
+			models.SectionAgents:   {Status: models.SectionOK},
+			models.SectionWorkload: {Status: models.SectionOK},
+			models.SectionQueues:   {Status: models.SectionOK},
+			models.SectionLiteLLM:  {Status: models.SectionError, Error: "unavailable"},
+		},
+	}
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rr.Body)
+	return string(body)
+}
+
+func TestHandler_ExportsStateMetrics(t *testing.T) {
+	body := scrape(t, New(staticSource{state: sampleState()}, 0))
+
+	expected := []string{
+		`telemetron_agent_active_tasks{agent="agent-1",status="running"} 1`,
+		`telemetron_agent_parallel_utilization_ratio{agent="agent-1"} 0.25`,
+		`telemetron_workload_active_pods{deployment="deploy-1"} 1`,
+		`telemetron_pod_memory_bytes{deployment="deploy-1",pod="pod-1",status="running"} 2.68435456e+08`,
+		`telemetron_queue_depth{priority="high",queue="default"} 2`,
+		`telemetron_litellm_tpm_ratio{model="gpt-4",provider="openai"} 0.5`,
+		`telemetron_section_up{section="litellm"} 0`,
+		`telemetron_section_up{section="agents"} 1`,
+		"go_goroutines",
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %q in scrape output", line)
+		}
+	}
+}
+
+func TestHandler_SourceError(t *testing.T) {
+	m := New(staticSource{err: errors.New("boom")}, 0)
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the state cannot be read, got %d", rr.Code)
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	m := New(staticSource{state: sampleState()}, 0)
+	handler := m.Instrument("/system/state", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/system/state", nil))
+	}
+
+	body := scrape(t, m)
+	if !strings.Contains(body, `telemetron_http_requests_total{code="418",handler="/system/state",method="get"} 2`) {
+		t.Errorf("Expected request counter in scrape output:\n%s", body)
+	}
+	if !strings.Contains(body, `telemetron_http_request_duration_seconds_count{code="418",handler="/system/state",method="get"} 2`) {
+		t.Error("Expected request duration histogram in scrape output")
+	}
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/logger"
+)
+
+const (
+	namespace   = "telemetron"
+	bytesPerMiB = 1024 * 1024
+)
+
+// StateSource produces the system state exported on each scrape.
+type StateSource interface {
+	GetSystemState(ctx context.Context) (*models.SystemState, error)
+}
+
+var (
+	sectionUpDesc = prometheus.NewDesc(namespace+"_section_up",
+		"Whether a section of the system state has data (1) or is in error (0).",
+		[]string{"section"}, nil)
+
+	agentTasksDesc = prometheus.NewDesc(namespace+"_agent_active_tasks",
+		"Active tasks per agent by status.",
+		[]string{"agent", "status"}, nil)
+	agentMaxParallelDesc = prometheus.NewDesc(namespace+"_agent_max_parallel_invocations",
+		"Configured maximum parallel invocations per agent.",
+		[]string{"agent"}, nil)
+	agentUtilizationDesc = prometheus.NewDesc(namespace+"_agent_parallel_utilization_ratio",
+		"Running tasks divided by max parallel invocations.",
+		[]string{"agent"}, nil)
+
+	workloadActivePodsDesc = prometheus.NewDesc(namespace+"_workload_active_pods",
+		"Active pods per deployment.",
+		[]string{"deployment"}, nil)
+	workloadMaxPodsDesc = prometheus.NewDesc(namespace+"_workload_max_pods",
+		"Maximum pods per deployment.",
+		[]string{"deployment"}, nil)
+	podCPUDesc = prometheus.NewDesc(namespace+"_pod_cpu_cores",
+		"CPU used by a pod in cores.",
+		[]string{"deployment", "pod", "status"}, nil)
+	podMemoryDesc = prometheus.NewDesc(namespace+"_pod_memory_bytes",
+		"Memory used by a pod in bytes.",
+		[]string{"deployment", "pod", "status"}, nil)
+
+	queueDepthDesc = prometheus.NewDesc(namespace+"_queue_depth",
+		"Pending tasks per queue by priority level.",
+		[]string{"queue", "priority"}, nil)
+
+	litellmTPMDesc = prometheus.NewDesc(namespace+"_litellm_tpm",
+		"Current tokens per minute per model.",
+		[]string{"model", "provider"}, nil)
+	litellmTPMMaxDesc = prometheus.NewDesc(namespace+"_litellm_tpm_max",
+		"Tokens per minute limit per model.",
+		[]string{"model", "provider"}, nil)
+	litellmTPMRatioDesc = prometheus.NewDesc(namespace+"_litellm_tpm_ratio",
+		"Current tokens per minute divided by the limit.",
+		[]string{"model", "provider"}, nil)
+	litellmRPMDesc = prometheus.NewDesc(namespace+"_litellm_rpm",
+		"Current requests per minute per model.",
+		[]string{"model", "provider"}, nil)
+	litellmRPMMaxDesc = prometheus.NewDesc(namespace+"_litellm_rpm_max",
+		"Requests per minute limit per model.",
+		[]string{"model", "provider"}, nil)
+	litellmRPMRatioDesc = prometheus.NewDesc(namespace+"_litellm_rpm_ratio",
+		"Current requests per minute divided by the limit.",
+		[]string{"model", "provider"}, nil)
+)
+
+// stateCollector derives gauges from a fresh SystemState on every scrape.
+type stateCollector struct {
+	source  StateSource
+	timeout time.Duration
+}
+
+func newStateCollector(source StateSource, timeout time.Duration) *stateCollector {
+	return &stateCollector{source: source, timeout: timeout}
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		sectionUpDesc,
+		agentTasksDesc, agentMaxParallelDesc, agentUtilizationDesc,
+		workloadActivePodsDesc, workloadMaxPodsDesc, podCPUDesc, podMemoryDesc,
+		queueDepthDesc,
+		litellmTPMDesc, litellmTPMMaxDesc, litellmTPMRatioDesc,
+		litellmRPMDesc, litellmRPMMaxDesc, litellmRPMRatioDesc,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	state, err := c.source.GetSystemState(ctx)
+	if err != nil {
+		logger.Log.Error("Failed to collect system state metrics", zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(sectionUpDesc, err)
+		return
+	}
+
+	for section, status := range state.Status {
+		gauge(ch, sectionUpDesc, boolValue(status.Status != models.SectionError), section)
+	}
+
+	for _, agent := range state.Agents {
+		byStatus := map[models.TaskState]int{}
+		for _, task := range agent.Activity.ActiveTaskIDs {
+			byStatus[task.Status]++
+		}
+		for status, count := range byStatus {
+			gauge(ch, agentTasksDesc, float64(count), agent.Name, string(status))
+		}
+		gauge(ch, agentMaxParallelDesc, float64(agent.MaxParallelInvocations), agent.Name)
+		if agent.MaxParallelInvocations > 0 {
+			gauge(ch, agentUtilizationDesc, float64(byStatus[models.TaskRunning])/float64(agent.MaxParallelInvocations), agent.Name)
+		}
+	}
+
+	for _, workload := range state.Workload {
+		gauge(ch, workloadActivePodsDesc, float64(workload.Live.ActivePods), workload.DeploymentName)
+		gauge(ch, workloadMaxPodsDesc, float64(workload.MaxPods), workload.DeploymentName)
+		for _, pod := range workload.Pods {
+			gauge(ch, podCPUDesc, pod.CPU, workload.DeploymentName, pod.PodID, pod.Status)
+			gauge(ch, podMemoryDesc, float64(pod.Memory)*bytesPerMiB, workload.DeploymentName, pod.PodID, pod.Status)
+		}
+	}
+
+	for _, queue := range state.Queues {
+		byPriority := map[string]int{}
+		for _, task := range queue.Tasks {
+			byPriority[task.Priority.Level]++
+		}
+		for priority, count := range byPriority {
+			gauge(ch, queueDepthDesc, float64(count), queue.Name, priority)
+		}
+	}
+
+	for _, llm := range state.LiteLLM {
+		gauge(ch, litellmTPMDesc, float64(llm.TPM), llm.Model, llm.Provider)
+		gauge(ch, litellmTPMMaxDesc, float64(llm.TPMMax), llm.Model, llm.Provider)
+		gauge(ch, litellmRPMDesc, float64(llm.RPM), llm.Model, llm.Provider)
+		gauge(ch, litellmRPMMaxDesc, float64(llm.RPMMax), llm.Model, llm.Provider)
+		if llm.TPMMax > 0 {
+			gauge(ch, litellmTPMRatioDesc, float64(llm.TPM)/float64(llm.TPMMax), llm.Model, llm.Provider)
+		}
+		if llm.RPMMax > 0 {
+			gauge(ch, litellmRPMRatioDesc, float64(llm.RPM)/float64(llm.RPMMax), llm.Model, llm.Provider)
+		}
+	}
+}
+
+func gauge(ch chan<- prometheus.Metric, desc *prometheus.Desc, value float64, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
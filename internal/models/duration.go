@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration that is encoded in JSON as a number of
+// seconds, so that clients need not parse Go duration strings. Fields of
+// this type carry a _seconds suffix in their JSON name.
+type Duration time.Duration
+
+// Since returns the time elapsed from t to now, or zero when t is unset.
+func Since(t, now time.Time) Duration {
+	if t.IsZero() {
+		return 0
+	}
+	return Duration(now.Sub(t))
+}
+
+// Seconds returns the duration as a floating point number of seconds.
+func (d Duration) Seconds() float64 {
+	return time.Duration(d).Seconds()
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Seconds())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return fmt.Errorf("duration must be a number of seconds: %w", err)
+	}
+	*d = Duration(seconds * float64(time.Second))
+	return nil
+}
@@ -0,0 +1,17 @@
+package models
+
+// AgentHeartbeat is the body of POST /agents/{name}/heartbeat. It registers
+// the agent on first use; empty fields keep the registered values.
+type AgentHeartbeat struct {
+	Description            string   `json:"description,omitempty"`
+	MaxParallelInvocations int      `json:"max_parallel_invocations,omitempty"`
+	DeploymentName         string   `json:"deployment_name,omitempty"`
+	Models                 []string `json:"models,omitempty"`
+}
+
+// TaskReport is the body of POST /agents/{name}/tasks: the current status
+// of the tasks that changed. Tasks not listed keep their status, and
+// details a task is reported without keep their previous values.
+type TaskReport struct {
+	Tasks []TaskStatus `json:"tasks"`
+}
@@ -0,0 +1,227 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// QuantityFormat is how a Quantity is written back out: with binary
+// suffixes (Ki, Mi, Gi, ...) where possible, or decimal ones (m, k, M, ...).
+type QuantityFormat string
+
+const (
+	DecimalSI QuantityFormat = "DecimalSI"
+	BinarySI  QuantityFormat = "BinarySI"
+)
+
+// ErrInvalidQuantity is returned for strings that are not resource
+// quantities.
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
+// Quantity is a Kubernetes resource quantity such as "500m" CPU or "2Gi"
+// memory, held with milli-unit precision. Finer values are rounded up as
+// Kubernetes does. It is encoded in JSON as its canonical string, so
+// "1000m" is written as "1" and "2048Mi" as "2Gi".
+type Quantity struct {
+	milli  int64
+	format QuantityFormat
+}
+
+var binarySuffixes = []struct {
+	suffix string
+	shift  uint
+}{
+	{"Ei", 60}, {"Pi", 50}, {"Ti", 40}, {"Gi", 30}, {"Mi", 20}, {"Ki", 10},
+}
+
+var decimalSuffixes = []struct {
+	suffix string
+	exp    int
+}{
+	{"E", 18}, {"P", 15}, {"T", 12}, {"G", 9}, {"M", 6}, {"k", 3}, {"", 0},
+}
+
+// NewQuantity returns a quantity of value whole units.
+func NewQuantity(value int64, format QuantityFormat) Quantity {
+	return Quantity{milli: value * 1000, format: format}
+}
+
+// NewMilliQuantity returns a quantity of milli thousandths of a unit.
+func NewMilliQuantity(milli int64, format QuantityFormat) Quantity {
+	return Quantity{milli: milli, format: format}
+}
+
+// MustParseQuantity is ParseQuantity for known-good literals; it panics on
+// error.
+func MustParseQuantity(s string) Quantity {
+	q, err := ParseQuantity(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// ParseQuantity parses a number with an optional binary suffix (Ki to Ei),
+// decimal suffix (n, u, m, k, M, G, T, P, E) or decimal exponent (e3).
+func ParseQuantity(s string) (Quantity, error) {
+	number, suffix := splitQuantity(strings.TrimSpace(s))
+	value, ok := new(big.Rat).SetString(number)
+	if number == "" || !ok {
+		return Quantity{}, fmt.Errorf("%w %q", ErrInvalidQuantity, s)
+	}
+
+	format := DecimalSI
+	scale, err := suffixScale(suffix)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("%w %q: %v", ErrInvalidQuantity, s, err)
+	}
+	if strings.HasSuffix(suffix, "i") {
+		format = BinarySI
+	}
+
+	milli := value.Mul(value, scale)
+	milli.Mul(milli, big.NewRat(1000, 1))
+	rounded := new(big.Int).Quo(milli.Num(), milli.Denom())
+	if new(big.Rat).SetInt(rounded).Cmp(milli) < 0 {
+		rounded.Add(rounded, big.NewInt(1))
+	}
+	if !rounded.IsInt64() {
+		return Quantity{}, fmt.Errorf("%w %q: out of range", ErrInvalidQuantity, s)
+	}
+	return Quantity{milli: rounded.Int64(), format: format}, nil
+}
+
+// splitQuantity splits s into its signed decimal number and its suffix.
+func splitQuantity(s string) (number, suffix string) {
+	end := 0
+	if end < len(s) && (s[end] == '+' || s[end] == '-') {
+		end++
+	}
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+// suffixScale returns the multiplier a suffix stands for. An e or E
+// followed by a number is an exponent; E alone is the exa suffix.
+func suffixScale(suffix string) (*big.Rat, error) {
+	for _, binary := range binarySuffixes {
+		if suffix == binary.suffix {
+			return new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), binary.shift)), nil
+		}
+	}
+	exp := 0
+	switch {
+	case suffix == "n":
+		exp = -9
+	case suffix == "u":
+		exp = -6
+	case suffix == "m":
+		exp = -3
+	case len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E'):
+		n, err := strconv.Atoi(suffix[1:])
+		if err != nil || n < -18 || n > 18 {
+			return nil, fmt.Errorf("invalid exponent %q", suffix)
+		}
+		exp = n
+	default:
+		found := false
+		for _, decimal := range decimalSuffixes {
+			if suffix == decimal.suffix {
+				exp, found = decimal.exp, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown suffix %q", suffix)
+		}
+	}
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(exp, -exp))), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), power), nil
+	}
+	return new(big.Rat).SetInt(power), nil
+}
+
+// IsZero reports whether the quantity is zero, which also stands for an
+// unset limit.
+func (q Quantity) IsZero() bool {
+	return q.milli == 0
+}
+
+// Value returns the quantity in whole units, rounded up.
+func (q Quantity) Value() int64 {
+	value := q.milli / 1000
+	if q.milli%1000 > 0 {
+		value++
+	}
+	return value
+}
+
+// MilliValue returns the quantity in thousandths of a unit.
+func (q Quantity) MilliValue() int64 {
+	return q.milli
+}
+
+// Float64 returns the quantity in units, such as CPU cores or bytes.
+func (q Quantity) Float64() float64 {
+	return float64(q.milli) / 1000
+}
+
+// String returns the canonical form of the quantity: the largest suffix of
+// its format that writes it as a whole number, with milli-units for
+// fractions. Binary quantities that are not whole multiples of 1024 are
+// written with decimal suffixes.
+func (q Quantity) String() string {
+	if q.milli%1000 != 0 {
+		return strconv.FormatInt(q.milli, 10) + "m"
+	}
+	value := q.milli / 1000
+	if value == 0 {
+		return "0"
+	}
+	if q.format == BinarySI {
+		for _, binary := range binarySuffixes {
+			if unit := int64(1) << binary.shift; value%unit == 0 {
+				return strconv.FormatInt(value/unit, 10) + binary.suffix
+			}
+		}
+	}
+	for _, decimal := range decimalSuffixes {
+		if unit := int64(math.Pow10(decimal.exp)); value%unit == 0 {
+			return strconv.FormatInt(value/unit, 10) + decimal.suffix
+		}
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.String())
+}
+
+// UnmarshalJSON accepts a quantity string or a plain number of units.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	if s == "" {
+		*q = Quantity{}
+		return nil
+	}
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
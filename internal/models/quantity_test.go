@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in        string
+		milli     int64
+		canonical string
+	}{
+		{"1000m", 1000, "1"},
+		{"500m", 500, "500m"},
+		{"0.5", 500, "500m"},
+		{"1.5", 1500, "1500m"},
+		{"2Gi", 2 << 30 * 1000, "2Gi"},
+		{"2048Mi", 2 << 30 * 1000, "2Gi"},
+		{"1.5Gi", 1536 << 20 * 1000, "1536Mi"},
+		{"1000", 1000 * 1000, "1k"},
+		{"128974848", 128974848 * 1000, "128974848"},
+		{"129M", 129e6 * 1000, "129M"},
+		{"1e3", 1000 * 1000, "1k"},
+		{"100n", 1, "1m"},
+		{"0", 0, "0"},
+		{"-250m", -250, "-250m"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuantity(tt.in)
+		if err != nil {
+			t.Errorf("ParseQuantity(%q): %v", tt.in, err)
+			continue
+		}
+		if q.MilliValue() != tt.milli || q.String() != tt.canonical {
+			t.Errorf("ParseQuantity(%q) = %d milli %q, expected %d milli %q", tt.in, q.MilliValue(), q, tt.milli, tt.canonical)
+		}
+	}
+
+	for _, in := range []string{"", "Gi", "1.2.3", "2GB", "1e", "1e100", "8Ei"} {
+		if _, err := ParseQuantity(in); !errors.Is(err, ErrInvalidQuantity) {
+			t.Errorf("ParseQuantity(%q): expected ErrInvalidQuantity, got %v", in, err)
+		}
+	}
+}
+
+func TestQuantity_Values(t *testing.T) {
+	cpu := MustParseQuantity("1500m")
+	if cpu.Value() != 2 || cpu.Float64() != 1.5 {
+		t.Errorf("Expected 1500m to round up to 2 and be 1.5 cores, got %d and %v", cpu.Value(), cpu.Float64())
+	}
+	if memory := MustParseQuantity("1Ki"); memory.Value() != 1024 {
+		t.Errorf("Expected 1Ki to be 1024 bytes, got %d", memory.Value())
+	}
+	if q := NewQuantity(3<<20, BinarySI); q.String() != "3Mi" {
+		t.Errorf("Expected 3Mi, got %s", q)
+	}
+}
+
+func TestQuantity_JSON(t *testing.T) {
+	var workload Workload
+	if err := json.Unmarshal([]byte(`{"pod_max_cpu": "1000m", "pod_max_ram": 1073741824}`), &workload); err != nil {
+		t.Fatal(err)
+	}
+	if workload.PodMaxCPU.MilliValue() != 1000 || workload.PodMaxRAM.Value() != 1<<30 {
+		t.Errorf("Expected quantities from a string and a number, got %s and %s", workload.PodMaxCPU, workload.PodMaxRAM)
+	}
+
+	data, err := json.Marshal(Workload{PodMaxRAM: MustParseQuantity("2048Mi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	if out["pod_max_ram"] != "2Gi" {
+		t.Errorf("Expected the canonical 2Gi, got %v", out["pod_max_ram"])
+	}
+	if _, ok := out["pod_max_cpu"]; ok {
+		t.Error("Expected an unset limit to be omitted")
+	}
+
+	if err := json.Unmarshal([]byte(`{"pod_max_cpu": "lots"}`), &workload); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("Expected ErrInvalidQuantity, got %v", err)
+	}
+}
@@ -1,12 +1,52 @@
 // internal/models/system_state.go
 package models
 
+import "time"
+
+// SystemState is one snapshot of the system. Timestamps are encoded as
+// RFC 3339 strings; durations derived from them when the snapshot is
+// taken, such as task ages, are encoded as seconds.
 type SystemState struct {
-	ID       string     `json:"id"`
-	Agents   []Agent    `json:"agents"`
-	Workload []Workload `json:"workload"`
-	Queues   []Queue    `json:"queues"`
-	LiteLLM  []LiteLLM  `json:"litellm"`
+	ID         string     `json:"id"`
+	CapturedAt time.Time  `json:"captured_at"`
+	Agents     []Agent    `json:"agents"`
+	Workload   []Workload `json:"workload"`
+	Queues     []Queue    `json:"queues"`
+	LiteLLM    []LiteLLM  `json:"litellm"`
+
+	Status map[string]SectionStatus `json:"status"`
+	Cache  map[string]CacheInfo     `json:"cache,omitempty"`
+}
+
+// Section names match the SystemState JSON keys.
+const (
+	SectionAgents   = "agents"
+	SectionWorkload = "workload"
+	SectionQueues   = "queues"
+	SectionLiteLLM  = "litellm"
+)
+
+// Section health values reported in SectionStatus.Status.
+const (
+	SectionOK       = "ok"
+	SectionDegraded = "degraded"
+	SectionError    = "error"
+)
+
+// SectionStatus reports whether a section of the snapshot is current.
+// A degraded section carries the last successfully fetched data; a section
+// in error has none.
+type SectionStatus struct {
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+}
+
+// CacheInfo reports how old a cached section of the snapshot is.
+type CacheInfo struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Age       Duration  `json:"age_seconds" swaggertype:"number"`
+	Stale     bool      `json:"stale"`
 }
 
 type Agent struct {
@@ -18,47 +58,145 @@ type Agent struct {
 	Activity               Activity `json:"activity"`
 }
 
+// Activity is what an agent is working on. Agents that report themselves
+// also carry the time of their last heartbeat, and are stale once
+// heartbeats stop arriving. Staleness is the time since UpdatedAt.
 type Activity struct {
 	ActiveTaskIDs []TaskStatus `json:"active_task_ids"`
-	UpdatedAt     string       `json:"updated_at"`
-}
-
-type TaskStatus struct {
-	ID     string `json:"id"`
-	Status string `json:"status"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Staleness     Duration     `json:"staleness_seconds" swaggertype:"number"`
+	LastHeartbeat time.Time    `json:"last_heartbeat,omitzero"`
+	Stale         bool         `json:"stale,omitempty"`
 }
 
+// Workload is a deployment running agents. PodMaxRAM and PodMaxCPU are
+// the per-pod limits, unset when the pods have none; Utilization totals
+// the usage of its running pods against those limits.
 type Workload struct {
-	DeploymentName string       `json:"deployment_name"`
-	MaxPods        int          `json:"max_pods"`
-	PodMaxRAM      string       `json:"pod_max_ram"`
-	PodMaxCPU      string       `json:"pod_max_cpu"`
-	Live           LiveWorkload `json:"live"`
-	Pods           []Pod        `json:"pods"`
+	DeploymentName string              `json:"deployment_name"`
+	MaxPods        int                 `json:"max_pods"`
+	PodMaxRAM      Quantity            `json:"pod_max_ram,omitzero" swaggertype:"string" example:"2Gi"`
+	PodMaxCPU      Quantity            `json:"pod_max_cpu,omitzero" swaggertype:"string" example:"500m"`
+	Live           LiveWorkload        `json:"live"`
+	Pods           []Pod               `json:"pods"`
+	Utilization    WorkloadUtilization `json:"utilization"`
 }
 
+// LiveWorkload is a workload's current scale. Staleness is the time since
+// UpdatedAt.
 type LiveWorkload struct {
-	ActivePods int    `json:"active_pods"`
-	UpdatedAt  string `json:"updated_at"`
+	ActivePods int       `json:"active_pods"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Staleness  Duration  `json:"staleness_seconds" swaggertype:"number"`
 }
 
+// Pod is one pod of a workload and its current usage: CPU in cores and
+// Memory in MiB.
 type Pod struct {
-	PodID  string  `json:"pod_id"`
-	CPU    float64 `json:"cpu"`
-	Memory int     `json:"memory"`
-	Status string  `json:"status"`
+	PodID       string      `json:"pod_id"`
+	CPU         float64     `json:"cpu"`
+	Memory      int         `json:"memory"`
+	Status      string      `json:"status"`
+	Utilization Utilization `json:"utilization"`
+}
+
+// NearLimitRatio is the share of a limit above which a pod counts as near
+// it, where CPU is throttled and memory is close to an OOM kill.
+const NearLimitRatio = 0.9
+
+// Utilization is the usage of CPU, in cores, and memory, in bytes,
+// against their limits.
+type Utilization struct {
+	CPU    ResourceUsage `json:"cpu"`
+	Memory ResourceUsage `json:"memory"`
+}
+
+// WorkloadUtilization totals a workload's running pods, and counts those
+// above NearLimitRatio of either limit.
+type WorkloadUtilization struct {
+	Utilization
+	PodsNearLimit int `json:"pods_near_limit"`
+}
+
+// ResourceUsage compares the use of a resource with its limit. Without a
+// limit, Limit, Ratio and Headroom are zero.
+type ResourceUsage struct {
+	Used     float64 `json:"used"`
+	Limit    float64 `json:"limit"`
+	Ratio    float64 `json:"ratio"`
+	Headroom float64 `json:"headroom"`
+}
+
+// NewResourceUsage returns the usage of used out of limit, where a limit
+// of zero is unset. Headroom is negative when the use exceeds the limit.
+func NewResourceUsage(used, limit float64) ResourceUsage {
+	usage := ResourceUsage{Used: used}
+	if limit > 0 {
+		usage.Limit = limit
+		usage.Ratio = used / limit
+		usage.Headroom = limit - used
+	}
+	return usage
+}
+
+// Add returns the sum of two usages, as of pods sharing a workload.
+func (u ResourceUsage) Add(other ResourceUsage) ResourceUsage {
+	return NewResourceUsage(u.Used+other.Used, u.Limit+other.Limit)
 }
 
+// Queue is a source of pending tasks. Staleness is the time since
+// UpdatedAt, and MaxWait the age of the oldest task with a known
+// submission time.
 type Queue struct {
-	Name      string      `json:"name"`
-	UpdatedAt string      `json:"updated_at"`
-	Tasks     []QueueTask `json:"tasks"`
+	Name           string          `json:"name"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Staleness      Duration        `json:"staleness_seconds" swaggertype:"number"`
+	MaxWait        Duration        `json:"max_wait_seconds,omitzero" swaggertype:"number"`
+	Tasks          []QueueTask     `json:"tasks"`
+	ConsumerGroups []ConsumerGroup `json:"consumer_groups,omitempty"`
+	Partitions     []Partition     `json:"partitions,omitempty"`
+}
+
+// Partition reports the depth of one partition of a log-based queue such
+// as a Kafka topic: the records retained between its start and end
+// offsets.
+type Partition struct {
+	Partition   int32 `json:"partition"`
+	StartOffset int64 `json:"start_offset"`
+	EndOffset   int64 `json:"end_offset"`
+	Depth       int64 `json:"depth"`
+}
+
+// ConsumerGroup reports how far a group of consumers is behind a queue.
+// Pending entries were delivered but not acknowledged; Lag counts entries
+// not yet delivered to the group.
+type ConsumerGroup struct {
+	Name            string `json:"name"`
+	Consumers       int    `json:"consumers"`
+	Pending         int64  `json:"pending"`
+	Lag             int64  `json:"lag"`
+	LastDeliveredID string `json:"last_delivered_id,omitempty"`
+
+	Partitions []PartitionLag `json:"partitions,omitempty"`
+}
+
+// PartitionLag is a consumer group's position in one partition. A
+// CommittedOffset of -1 means the group has not committed one, in which
+// case the whole partition counts as lag.
+type PartitionLag struct {
+	Partition       int32 `json:"partition"`
+	CommittedOffset int64 `json:"committed_offset"`
+	EndOffset       int64 `json:"end_offset"`
+	Lag             int64 `json:"lag"`
 }
 
+// QueueTask is a task waiting in a queue. SubmittedAt is unset when the
+// source does not record it; Age is the time since then.
 type QueueTask struct {
-	ID          string   `json:"id"`
-	Priority    Priority `json:"priority"`
-	SubmittedAt string   `json:"submitted_at"`
+	ID          string    `json:"id"`
+	Priority    Priority  `json:"priority"`
+	SubmittedAt time.Time `json:"submitted_at,omitzero"`
+	Age         Duration  `json:"age_seconds,omitzero" swaggertype:"number"`
 }
 
 type Priority struct {
@@ -8,7 +8,7 @@ import (
 
 func TestSystemStateJSONSerialization(t *testing.T) {
 	// Create test data
-	now := time.Now().Format(time.RFC3339)
+	now := time.Now()
 	state := SystemState{
 		ID: "system-1",
 		Agents: []Agent{
@@ -25,8 +25,8 @@ func TestSystemStateJSONSerialization(t *testing.T) {
 			{
 				DeploymentName: "test-deployment",
 				MaxPods:        5,
-				PodMaxRAM:      "1Gi",
-				PodMaxCPU:      "500m",
+				PodMaxRAM:      MustParseQuantity("1Gi"),
+				PodMaxCPU:      MustParseQuantity("500m"),
 				Live: LiveWorkload{
 					ActivePods: 2,
 					UpdatedAt:  now,
@@ -98,3 +98,38 @@ func TestEmptySystemState(t *testing.T) {
 		t.Error("Expected 0 agents")
 	}
 }
+
+func TestTimestampsAndDurationsJSON(t *testing.T) {
+	submitted := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	queue := Queue{
+		Name:      "default",
+		UpdatedAt: submitted.Add(time.Minute),
+		MaxWait:   Duration(90 * time.Second),
+		Tasks: []QueueTask{
+			{ID: "task-1", SubmittedAt: submitted, Age: Duration(1500 * time.Millisecond)},
+			{ID: "task-2"},
+		},
+	}
+
+	data, err := json.Marshal(queue)
+	if err != nil {
+		t.Fatalf("Failed to marshal Queue: %v", err)
+	}
+	want := `{"name":"default","updated_at":"2026-02-06T10:01:00Z","staleness_seconds":0,"max_wait_seconds":90,` +
+		`"tasks":[{"id":"task-1","priority":{"level":""},"submitted_at":"2026-02-06T10:00:00Z","age_seconds":1.5},` +
+		`{"id":"task-2","priority":{"level":""}}]}`
+	if string(data) != want {
+		t.Errorf("Unexpected encoding\n got: %s\nwant: %s", data, want)
+	}
+
+	var decoded Queue
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal Queue: %v", err)
+	}
+	if !decoded.Tasks[0].SubmittedAt.Equal(submitted) || decoded.Tasks[0].Age != queue.Tasks[0].Age || decoded.MaxWait != queue.MaxWait {
+		t.Errorf("Round trip changed the queue: %+v", decoded)
+	}
+	if !decoded.Tasks[1].SubmittedAt.IsZero() {
+		t.Errorf("Expected an unknown submission time to stay unset, got %v", decoded.Tasks[1].SubmittedAt)
+	}
+}
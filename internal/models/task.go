@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// TaskState is the lifecycle state of an agent task. Tasks start pending
+// or running and end completed, failed or cancelled; the end states are
+// final.
+type TaskState string
+
+// Task states.
+const (
+	TaskPending   TaskState = "pending"
+	TaskRunning   TaskState = "running"
+	TaskCompleted TaskState = "completed"
+	TaskFailed    TaskState = "failed"
+	TaskCancelled TaskState = "cancelled"
+)
+
+// TaskStates lists every task state in lifecycle order.
+var TaskStates = []TaskState{TaskPending, TaskRunning, TaskCompleted, TaskFailed, TaskCancelled}
+
+// transitions lists the states each non-final state may move to.
+var transitions = map[TaskState][]TaskState{
+	TaskPending: {TaskRunning, TaskCompleted, TaskFailed, TaskCancelled},
+	TaskRunning: {TaskCompleted, TaskFailed, TaskCancelled},
+}
+
+// Valid reports whether s is a known state.
+func (s TaskState) Valid() bool {
+	for _, state := range TaskStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Final reports whether s ends the task's lifecycle.
+func (s TaskState) Final() bool {
+	return s == TaskCompleted || s == TaskFailed || s == TaskCancelled
+}
+
+// CanTransition reports whether a task may move from s to next. Staying
+// in the same state is always allowed.
+func (s TaskState) CanTransition(next TaskState) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range transitions[s] {
+		if next == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskStatus is one task of an agent. StartedAt is set once the task runs
+// and FinishedAt once it reaches a final state; Duration is the time
+// between them, or up to the snapshot while the task runs. Error explains
+// a failure. Queue and QueueTaskID name the queue task the agent picked
+// the task up from, if any.
+type TaskStatus struct {
+	ID           string    `json:"id"`
+	Status       TaskState `json:"status" enums:"pending,running,completed,failed,cancelled"`
+	StartedAt    time.Time `json:"started_at,omitzero"`
+	FinishedAt   time.Time `json:"finished_at,omitzero"`
+	Duration     Duration  `json:"duration_seconds,omitzero" swaggertype:"number"`
+	Error        string    `json:"error,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	InputTokens  int       `json:"input_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	Queue        string    `json:"queue,omitempty"`
+	QueueTaskID  string    `json:"queue_task_id,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "testing"
+
+func TestTaskState_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to TaskState
+		allowed  bool
+	}{
+		{TaskPending, TaskRunning, true},
+		{TaskPending, TaskCancelled, true},
+		{TaskPending, TaskCompleted, true},
+		{TaskRunning, TaskFailed, true},
+		{TaskRunning, TaskRunning, true},
+		{TaskRunning, TaskPending, false},
+		{TaskCompleted, TaskCompleted, true},
+		{TaskCompleted, TaskRunning, false},
+		{TaskFailed, TaskCompleted, false},
+		{TaskCancelled, TaskPending, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransition(tt.to); got != tt.allowed {
+			t.Errorf("%s -> %s: expected allowed=%v, got %v", tt.from, tt.to, tt.allowed, got)
+		}
+	}
+
+	for _, state := range TaskStates {
+		if !state.Valid() {
+			t.Errorf("Expected %s to be valid", state)
+		}
+	}
+	if TaskState("queued").Valid() || TaskState("").Valid() {
+		t.Error("Expected unknown states to be invalid")
+	}
+	if TaskRunning.Final() || !TaskCancelled.Final() {
+		t.Error("Expected only completed, failed and cancelled to be final")
+	}
+}
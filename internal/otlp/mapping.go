@@ -0,0 +1,102 @@
+package otlp
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mapping tells the receiver which spans describe agent work and which
+// attributes carry the values it needs. Each key list is tried in order,
+// first on the span and then on its resource. The defaults follow the
+// OpenTelemetry semantic conventions for generative AI.
+type Mapping struct {
+	// AgentName identifies the agent a span belongs to. Spans without
+	// one are ignored.
+	AgentName      []string `yaml:"agent_name"`
+	DeploymentName []string `yaml:"deployment_name"`
+
+	// TaskSpans select the spans that each cover one task, by attribute
+	// value. A task span that ends with an error status failed, any
+	// other one completed. Other spans of the agent mark their task
+	// running.
+	TaskSpans Matcher `yaml:"task_spans"`
+	// TaskID names a span's task. Without it, the trace ID is used, so
+	// that every span of one trace belongs to the same task.
+	TaskID []string `yaml:"task_id"`
+
+	// LLMSpans select the spans that each cover one model call.
+	LLMSpans     Matcher  `yaml:"llm_spans"`
+	Model        []string `yaml:"model"`
+	Provider     []string `yaml:"provider"`
+	InputTokens  []string `yaml:"input_tokens"`
+	OutputTokens []string `yaml:"output_tokens"`
+}
+
+// Matcher maps attribute keys to accepted values. A span matches when any
+// key has one of its values; a key without values matches any value.
+type Matcher map[string][]string
+
+// DefaultMapping recognizes spans instrumented per the GenAI semantic
+// conventions: invoke_agent spans are tasks and chat, completion and
+// embedding spans are model calls.
+func DefaultMapping() Mapping {
+	return Mapping{
+		AgentName:      []string{"gen_ai.agent.name"},
+		DeploymentName: []string{"k8s.deployment.name"},
+		TaskSpans:      Matcher{"gen_ai.operation.name": {"invoke_agent"}},
+		TaskID:         []string{"task.id"},
+		LLMSpans: Matcher{"gen_ai.operation.name": {
+			"chat", "text_completion", "generate_content", "embeddings",
+		}},
+		Model:        []string{"gen_ai.response.model", "gen_ai.request.model"},
+		Provider:     []string{"gen_ai.provider.name", "gen_ai.system"},
+		InputTokens:  []string{"gen_ai.usage.input_tokens"},
+		OutputTokens: []string{"gen_ai.usage.output_tokens"},
+	}
+}
+
+// LoadMapping reads a YAML mapping. Fields the file leaves out keep their
+// defaults.
+func LoadMapping(path string) (Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Mapping{}, err
+	}
+	return ParseMapping(data)
+}
+
+// ParseMapping decodes a YAML mapping over the defaults.
+func ParseMapping(data []byte) (Mapping, error) {
+	var file Mapping
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Mapping{}, fmt.Errorf("parse otlp mapping: %w", err)
+	}
+
+	mapping := DefaultMapping()
+	for _, keys := range []struct{ field, value *[]string }{
+		{&mapping.AgentName, &file.AgentName},
+		{&mapping.DeploymentName, &file.DeploymentName},
+		{&mapping.TaskID, &file.TaskID},
+		{&mapping.Model, &file.Model},
+		{&mapping.Provider, &file.Provider},
+		{&mapping.InputTokens, &file.InputTokens},
+		{&mapping.OutputTokens, &file.OutputTokens},
+	} {
+		if *keys.value != nil {
+			*keys.field = *keys.value
+		}
+	}
+	if file.TaskSpans != nil {
+		mapping.TaskSpans = file.TaskSpans
+	}
+	if file.LLMSpans != nil {
+		mapping.LLMSpans = file.LLMSpans
+	}
+
+	if len(mapping.AgentName) == 0 {
+		return Mapping{}, fmt.Errorf("otlp mapping: agent_name needs at least one attribute")
+	}
+	return mapping, nil
+}
@@ -0,0 +1,472 @@
+// Package otlp derives agent activity and model usage from OpenTelemetry
+// traces exported to Telemetron over OTLP/HTTP.
+package otlp
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+)
+
+// usageWindow is the span of model calls TPM and RPM are computed over.
+const usageWindow = time.Minute
+
+// Receiver keeps the agent activity and model usage seen in exported
+// spans. Agents are stale once none of their spans arrived for staleAfter,
+// and finished tasks stay listed for taskRetention. Traces may end without
+// a task span, so unfinished tasks and agents without spans for evictAfter
+// are forgotten.
+type Receiver struct {
+	mapping       Mapping
+	staleAfter    time.Duration
+	taskRetention time.Duration
+	evictAfter    time.Duration
+	now           func() time.Time
+
+	mu     sync.RWMutex
+	agents map[string]*tracedAgent
+	calls  []modelCall
+}
+
+type tracedAgent struct {
+	agent      models.Agent
+	lastSeen   time.Time
+	models     map[string]bool
+	finishedAt map[string]time.Time
+	// seenAt is when each task last had a span.
+	seenAt map[string]time.Time
+}
+
+// modelCall is one model call span.
+type modelCall struct {
+	at       time.Time
+	model    string
+	provider string
+	tokens   int
+}
+
+// NewReceiver recognizes spans using mapping. A zero evictAfter keeps
+// agents and unfinished tasks indefinitely.
+func NewReceiver(mapping Mapping, staleAfter, taskRetention, evictAfter time.Duration) *Receiver {
+	return &Receiver{
+		mapping:       mapping,
+		staleAfter:    staleAfter,
+		taskRetention: taskRetention,
+		evictAfter:    evictAfter,
+		now:           time.Now,
+		agents:        make(map[string]*tracedAgent),
+	}
+}
+
+// Export consumes one OTLP trace export. Spans that belong to no agent
+// and are no model call are ignored.
+func (r *Receiver) Export(req *coltracepb.ExportTraceServiceRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for _, resourceSpans := range req.GetResourceSpans() {
+		resource := resourceSpans.GetResource().GetAttributes()
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				r.consume(span, resource, now)
+			}
+		}
+	}
+
+	cutoff := now.Add(-usageWindow)
+	kept := r.calls[:0]
+	for _, call := range r.calls {
+		if call.at.After(cutoff) {
+			kept = append(kept, call)
+		}
+	}
+	r.calls = kept
+	for name, agent := range r.agents {
+		if r.evictedLocked(agent, now) {
+			delete(r.agents, name)
+			continue
+		}
+		agent.prune(now, r.taskRetention, r.evictAfter)
+	}
+}
+
+// evictedLocked reports whether an agent has had no spans for evictAfter.
+func (r *Receiver) evictedLocked(traced *tracedAgent, now time.Time) bool {
+	return r.evictAfter > 0 && now.Sub(traced.lastSeen) > r.evictAfter
+}
+
+func (r *Receiver) consume(span *tracepb.Span, resource []*commonpb.KeyValue, now time.Time) {
+	attrs := attributes{span.GetAttributes(), resource}
+
+	isCall := matches(r.mapping.LLMSpans, attrs)
+	model := attrs.string(r.mapping.Model)
+	inputTokens := int(attrs.int(r.mapping.InputTokens))
+	outputTokens := int(attrs.int(r.mapping.OutputTokens))
+	if isCall {
+		r.calls = append(r.calls, modelCall{
+			at:       spanTime(span.GetEndTimeUnixNano()),
+			model:    model,
+			provider: attrs.string(r.mapping.Provider),
+			tokens:   inputTokens + outputTokens,
+		})
+	}
+
+	name := attrs.string(r.mapping.AgentName)
+	if name == "" {
+		return
+	}
+	agent := r.agent(name)
+	agent.lastSeen = now
+	agent.agent.Activity.UpdatedAt = now.UTC()
+	if deployment := attrs.string(r.mapping.DeploymentName); deployment != "" {
+		agent.agent.DeploymentName = deployment
+	}
+	if isCall && model != "" && !agent.models[model] {
+		agent.models[model] = true
+		agent.agent.Models = append(agent.agent.Models, model)
+		sort.Strings(agent.agent.Models)
+	}
+
+	taskID := attrs.string(r.mapping.TaskID)
+	if taskID == "" {
+		taskID = hex.EncodeToString(span.GetTraceId())
+	}
+	if taskID == "" {
+		return
+	}
+
+	update := models.TaskStatus{
+		ID:        taskID,
+		Status:    models.TaskRunning,
+		StartedAt: spanTime(span.GetStartTimeUnixNano()),
+	}
+	if matches(r.mapping.TaskSpans, attrs) {
+		update.Status = models.TaskCompleted
+		update.FinishedAt = spanTime(span.GetEndTimeUnixNano())
+		if span.GetStatus().GetCode() == tracepb.Status_STATUS_CODE_ERROR {
+			update.Status = models.TaskFailed
+			update.Error = span.GetStatus().GetMessage()
+		}
+	}
+	if isCall {
+		update.Model = model
+		update.InputTokens = inputTokens
+		update.OutputTokens = outputTokens
+	}
+	agent.updateTask(update, now)
+}
+
+// spanTime converts an OTLP timestamp, leaving unset ones zero.
+func spanTime(unixNano uint64) time.Time {
+	if unixNano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(unixNano)).UTC()
+}
+
+func (r *Receiver) agent(name string) *tracedAgent {
+	agent, ok := r.agents[name]
+	if !ok {
+		agent = &tracedAgent{
+			agent: models.Agent{
+				Name:     name,
+				Models:   []string{},
+				Activity: models.Activity{ActiveTaskIDs: []models.TaskStatus{}},
+			},
+			models:     make(map[string]bool),
+			finishedAt: make(map[string]time.Time),
+			seenAt:     make(map[string]time.Time),
+		}
+		r.agents[name] = agent
+	}
+	return agent
+}
+
+// Agents returns the agents seen in traces, ordered by name.
+func (r *Receiver) Agents() []models.Agent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	agents := make([]models.Agent, 0, len(r.agents))
+	for _, traced := range r.agents {
+		if !r.evictedLocked(traced, now) {
+			agents = append(agents, r.viewLocked(traced, now))
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	return agents
+}
+
+// Agent returns the named agent, or false when no trace has named it.
+func (r *Receiver) Agent(name string) (models.Agent, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	traced, ok := r.agents[name]
+	if !ok || r.evictedLocked(traced, now) {
+		return models.Agent{}, false
+	}
+	return r.viewLocked(traced, now), true
+}
+
+// viewLocked returns a copy of a traced agent as it is served: without
+// tasks past their retention or eviction and marked stale without recent
+// spans.
+func (r *Receiver) viewLocked(traced *tracedAgent, now time.Time) models.Agent {
+	agent := traced.agent
+	agent.Models = append([]string{}, agent.Models...)
+	agent.Activity.ActiveTaskIDs = traced.currentTasks(now, r.taskRetention, r.evictAfter)
+	agent.Activity.Stale = r.staleAfter > 0 && now.Sub(traced.lastSeen) > r.staleAfter
+	return agent
+}
+
+// LiteLLM returns the tokens and requests per minute of every model and
+// provider called within the last minute. Traces carry no limits, so the
+// maximums are left at zero.
+func (r *Receiver) LiteLLM() []models.LiteLLM {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cutoff := r.now().Add(-usageWindow)
+	byKey := make(map[[2]string]*models.LiteLLM)
+	for _, call := range r.calls {
+		if !call.at.After(cutoff) || call.model == "" {
+			continue
+		}
+		key := [2]string{call.model, call.provider}
+		usage, ok := byKey[key]
+		if !ok {
+			usage = &models.LiteLLM{Model: call.model, Provider: call.provider}
+			byKey[key] = usage
+		}
+		usage.TPM += call.tokens
+		usage.RPM++
+	}
+
+	result := make([]models.LiteLLM, 0, len(byKey))
+	for _, usage := range byKey {
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Model != result[j].Model {
+			return result[i].Model < result[j].Model
+		}
+		return result[i].Provider < result[j].Provider
+	})
+	return result
+}
+
+// AgentRepository serves the receiver's agents as the agents section.
+func (r *Receiver) AgentRepository() *AgentRepository {
+	return &AgentRepository{receiver: r}
+}
+
+// LiteLLMRepository serves the receiver's model usage as the LiteLLM
+// section.
+func (r *Receiver) LiteLLMRepository() *LiteLLMRepository {
+	return &LiteLLMRepository{receiver: r}
+}
+
+// AgentRepository adapts a Receiver to repositories.AgentRepository.
+type AgentRepository struct {
+	receiver *Receiver
+}
+
+func (a *AgentRepository) GetAll(ctx context.Context) ([]models.Agent, error) {
+	return a.receiver.Agents(), nil
+}
+
+func (a *AgentRepository) Get(ctx context.Context, name string) (*models.Agent, error) {
+	agent, ok := a.receiver.Agent(name)
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &agent, nil
+}
+
+func (a *AgentRepository) Close() {}
+
+// LiteLLMRepository adapts a Receiver to repositories.LiteLLMRepository.
+type LiteLLMRepository struct {
+	receiver *Receiver
+}
+
+func (l *LiteLLMRepository) GetAll(ctx context.Context) ([]models.LiteLLM, error) {
+	return l.receiver.LiteLLM(), nil
+}
+
+func (l *LiteLLMRepository) Get(ctx context.Context, model string) ([]models.LiteLLM, error) {
+	var entries []models.LiteLLM
+	for _, entry := range l.receiver.LiteLLM() {
+		if entry.Model == model {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, repositories.ErrNotFound
+	}
+	return entries, nil
+}
+
+func (l *LiteLLMRepository) Close() {}
+
+// updateTask merges what one span tells about its task. The task started
+// with its earliest span, and a task span finishes it; model calls add
+// their tokens. Spans arriving after the task span, such as those of
+// children exported late, still add tokens but keep the final status.
+func (a *tracedAgent) updateTask(update models.TaskStatus, now time.Time) {
+	tasks := a.agent.Activity.ActiveTaskIDs
+	i := 0
+	for i < len(tasks) && tasks[i].ID != update.ID {
+		i++
+	}
+	if i == len(tasks) {
+		a.agent.Activity.ActiveTaskIDs = append(tasks, models.TaskStatus{ID: update.ID, Status: models.TaskRunning})
+		tasks = a.agent.Activity.ActiveTaskIDs
+	}
+	task := &tasks[i]
+	a.seenAt[task.ID] = now
+
+	if !update.StartedAt.IsZero() && (task.StartedAt.IsZero() || update.StartedAt.Before(task.StartedAt)) {
+		task.StartedAt = update.StartedAt
+	}
+	if update.Model != "" {
+		task.Model = update.Model
+	}
+	task.InputTokens += update.InputTokens
+	task.OutputTokens += update.OutputTokens
+
+	if task.Status.Final() || !update.Status.Final() {
+		return
+	}
+	task.Status = update.Status
+	task.FinishedAt = update.FinishedAt
+	if task.FinishedAt.IsZero() {
+		task.FinishedAt = now.UTC()
+	}
+	if !task.StartedAt.IsZero() {
+		task.Duration = models.Duration(task.FinishedAt.Sub(task.StartedAt))
+	}
+	task.Error = update.Error
+	a.finishedAt[task.ID] = now
+}
+
+// currentTasks returns a copy of the tasks without those that finished
+// more than retention ago and unfinished ones without spans for evictAfter.
+func (a *tracedAgent) currentTasks(now time.Time, retention, evictAfter time.Duration) []models.TaskStatus {
+	tasks := make([]models.TaskStatus, 0, len(a.agent.Activity.ActiveTaskIDs))
+	for _, task := range a.agent.Activity.ActiveTaskIDs {
+		if finishedAt, ok := a.finishedAt[task.ID]; ok && now.Sub(finishedAt) > retention {
+			continue
+		}
+		if !task.Status.Final() && evictAfter > 0 && now.Sub(a.seenAt[task.ID]) > evictAfter {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func (a *tracedAgent) prune(now time.Time, retention, evictAfter time.Duration) {
+	a.agent.Activity.ActiveTaskIDs = a.currentTasks(now, retention, evictAfter)
+	current := make(map[string]bool, len(a.agent.Activity.ActiveTaskIDs))
+	for _, task := range a.agent.Activity.ActiveTaskIDs {
+		current[task.ID] = true
+	}
+	for id := range a.seenAt {
+		if !current[id] {
+			delete(a.seenAt, id)
+		}
+	}
+	for id, finishedAt := range a.finishedAt {
+		if now.Sub(finishedAt) > retention {
+			delete(a.finishedAt, id)
+		}
+	}
+}
+
+// attributes looks keys up on a span, then on its resource.
+type attributes [2][]*commonpb.KeyValue
+
+func (a attributes) lookup(keys []string) (*commonpb.AnyValue, bool) {
+	for _, key := range keys {
+		for _, set := range a {
+			for _, kv := range set {
+				if kv.GetKey() == key {
+					return kv.GetValue(), true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+func (a attributes) string(keys []string) string {
+	value, ok := a.lookup(keys)
+	if !ok {
+		return ""
+	}
+	return stringValue(value)
+}
+
+func (a attributes) int(keys []string) int64 {
+	value, ok := a.lookup(keys)
+	if !ok {
+		return 0
+	}
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return int64(v.DoubleValue)
+	case *commonpb.AnyValue_StringValue:
+		n, _ := strconv.ParseInt(v.StringValue, 10, 64)
+		return n
+	}
+	return 0
+}
+
+func stringValue(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	}
+	return ""
+}
+
+func matches(matcher Matcher, attrs attributes) bool {
+	for key, values := range matcher {
+		value, ok := attrs.lookup([]string{key})
+		if !ok {
+			continue
+		}
+		if len(values) == 0 {
+			return true
+		}
+		for _, accepted := range values {
+			if stringValue(value) == accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
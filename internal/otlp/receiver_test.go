@@ -0,0 +1,223 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"telemetron/internal/models"
+	"telemetron/internal/repositories"
+)
+
+func attr(key string, value interface{}) *commonpb.KeyValue {
+	kv := &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{}}
+	switch v := value.(type) {
+	case string:
+		kv.Value.Value = &commonpb.AnyValue_StringValue{StringValue: v}
+	case int:
+		kv.Value.Value = &commonpb.AnyValue_IntValue{IntValue: int64(v)}
+	}
+	return kv
+}
+
+func export(resource []*commonpb.KeyValue, spans ...*tracepb.Span) *coltracepb.ExportTraceServiceRequest {
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource:   &resourcepb.Resource{Attributes: resource},
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans}},
+		}},
+	}
+}
+
+func span(traceID byte, end time.Time, attrs ...*commonpb.KeyValue) *tracepb.Span {
+	id := make([]byte, 16)
+	id[15] = traceID
+	return &tracepb.Span{TraceId: id, EndTimeUnixNano: uint64(end.UnixNano()), Attributes: attrs}
+}
+
+func TestReceiver_DerivesAgentsAndUsage(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	receiver := NewReceiver(DefaultMapping(), time.Minute, time.Minute, time.Hour)
+	receiver.now = func() time.Time { return now }
+
+	resource := []*commonpb.KeyValue{
+		attr("gen_ai.agent.name", "summarizer"),
+		attr("k8s.deployment.name", "summarizer-deploy"),
+	}
+	failed := span(2, now, attr("gen_ai.operation.name", "invoke_agent"))
+	failed.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "tool call failed"}
+	failed.StartTimeUnixNano = uint64(now.Add(-30 * time.Second).UnixNano())
+	receiver.Export(export(resource,
+		span(1, now, attr("gen_ai.operation.name", "chat"),
+			attr("gen_ai.request.model", "gpt-4"), attr("gen_ai.system", "openai"),
+			attr("gen_ai.usage.input_tokens", 100), attr("gen_ai.usage.output_tokens", 20)),
+		span(1, now, attr("gen_ai.operation.name", "chat"),
+			attr("gen_ai.response.model", "gpt-4"), attr("gen_ai.provider.name", "openai"),
+			attr("gen_ai.usage.input_tokens", 30)),
+		span(2, now, attr("gen_ai.operation.name", "execute_tool")),
+		failed,
+		span(3, now, attr("gen_ai.operation.name", "invoke_agent"), attr("task.id", "task-3")),
+	))
+	// Calls without an agent still count towards model usage, within the
+	// last minute.
+	receiver.Export(export(nil,
+		span(4, now.Add(-2*time.Minute), attr("gen_ai.operation.name", "chat"), attr("gen_ai.request.model", "claude")),
+		span(5, now, attr("gen_ai.operation.name", "chat"),
+			attr("gen_ai.request.model", "claude"), attr("gen_ai.system", "anthropic")),
+	))
+
+	agents, err := receiver.AgentRepository().GetAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("Expected 1 agent, got %+v", agents)
+	}
+	agent := agents[0]
+	if agent.Name != "summarizer" || agent.DeploymentName != "summarizer-deploy" || agent.Activity.Stale {
+		t.Errorf("Unexpected agent %+v", agent)
+	}
+	if len(agent.Models) != 1 || agent.Models[0] != "gpt-4" {
+		t.Errorf("Expected models [gpt-4], got %v", agent.Models)
+	}
+	want := map[string]models.TaskState{
+		"00000000000000000000000000000001": models.TaskRunning,
+		"00000000000000000000000000000002": models.TaskFailed,
+		"task-3":                           models.TaskCompleted,
+	}
+	if len(agent.Activity.ActiveTaskIDs) != len(want) {
+		t.Fatalf("Expected tasks %v, got %+v", want, agent.Activity.ActiveTaskIDs)
+	}
+	for _, task := range agent.Activity.ActiveTaskIDs {
+		if want[task.ID] != task.Status {
+			t.Errorf("Expected task %s to be %q, got %q", task.ID, want[task.ID], task.Status)
+		}
+		switch task.ID {
+		case "00000000000000000000000000000001":
+			if task.Model != "gpt-4" || task.InputTokens != 130 || task.OutputTokens != 20 {
+				t.Errorf("Expected the model calls' usage on task 1, got %+v", task)
+			}
+		case "00000000000000000000000000000002":
+			if task.Error != "tool call failed" || !task.FinishedAt.Equal(now) || task.Duration != models.Duration(30*time.Second) {
+				t.Errorf("Expected task 2 to record its failure and duration, got %+v", task)
+			}
+		}
+	}
+
+	usage, err := receiver.LiteLLMRepository().GetAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantUsage := []models.LiteLLM{
+		{Model: "claude", Provider: "anthropic", RPM: 1},
+		{Model: "gpt-4", Provider: "openai", TPM: 150, RPM: 2},
+	}
+	if len(usage) != len(wantUsage) {
+		t.Fatalf("Expected usage %+v, got %+v", wantUsage, usage)
+	}
+	for i := range wantUsage {
+		if usage[i] != wantUsage[i] {
+			t.Errorf("Expected usage %+v, got %+v", wantUsage[i], usage[i])
+		}
+	}
+	if entries, err := receiver.LiteLLMRepository().Get(context.Background(), "claude"); err != nil || len(entries) != 1 {
+		t.Errorf("Expected claude's usage, got %+v, %v", entries, err)
+	}
+
+	if agent, err := receiver.AgentRepository().Get(context.Background(), "summarizer"); err != nil || agent.DeploymentName != "summarizer-deploy" {
+		t.Errorf("Expected the summarizer agent, got %+v, %v", agent, err)
+	}
+	if _, err := receiver.AgentRepository().Get(context.Background(), "unknown"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown agent, got %v", err)
+	}
+}
+
+func TestReceiver_FinishedTasksAreFinalAndExpire(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	receiver := NewReceiver(DefaultMapping(), time.Minute, 5*time.Minute, time.Hour)
+	receiver.now = func() time.Time { return now }
+
+	resource := []*commonpb.KeyValue{attr("gen_ai.agent.name", "planner")}
+	receiver.Export(export(resource, span(1, now, attr("gen_ai.operation.name", "invoke_agent"))))
+	// A late child span must not reopen the completed task.
+	receiver.Export(export(resource, span(1, now, attr("gen_ai.operation.name", "chat"))))
+
+	agents := receiver.Agents()
+	if tasks := agents[0].Activity.ActiveTaskIDs; len(tasks) != 1 || tasks[0].Status != models.TaskCompleted {
+		t.Fatalf("Expected the task to stay completed, got %+v", tasks)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if agent := receiver.Agents()[0]; !agent.Activity.Stale || len(agent.Activity.ActiveTaskIDs) != 1 {
+		t.Errorf("Expected a stale agent still listing its task, got %+v", agent)
+	}
+
+	now = now.Add(4 * time.Minute)
+	if tasks := receiver.Agents()[0].Activity.ActiveTaskIDs; len(tasks) != 0 {
+		t.Errorf("Expected the finished task to expire, got %+v", tasks)
+	}
+}
+
+func TestReceiver_EvictsIdleTasksAndAgents(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	receiver := NewReceiver(DefaultMapping(), time.Minute, time.Minute, 10*time.Minute)
+	receiver.now = func() time.Time { return now }
+
+	planner := []*commonpb.KeyValue{attr("gen_ai.agent.name", "planner")}
+	writer := []*commonpb.KeyValue{attr("gen_ai.agent.name", "writer")}
+	// The task span never arrives, so task 1 stays running.
+	receiver.Export(export(planner, span(1, now, attr("task.id", "task-1"))))
+	receiver.Export(export(writer, span(2, now, attr("gen_ai.operation.name", "chat"))))
+
+	now = now.Add(8 * time.Minute)
+	receiver.Export(export(planner, span(3, now, attr("task.id", "task-3"))))
+
+	now = now.Add(4 * time.Minute)
+	agents := receiver.Agents()
+	if len(agents) != 1 || agents[0].Name != "planner" {
+		t.Fatalf("Expected the idle writer to be evicted, got %+v", agents)
+	}
+	if tasks := agents[0].Activity.ActiveTaskIDs; len(tasks) != 1 || tasks[0].ID != "task-3" {
+		t.Errorf("Expected the unfinished idle task to be evicted, got %+v", tasks)
+	}
+
+	receiver.Export(export(planner, span(3, now, attr("task.id", "task-3"))))
+	if _, ok := receiver.agents["writer"]; ok || len(receiver.agents["planner"].seenAt) != 1 {
+		t.Errorf("Expected evicted agents and tasks to be dropped from memory")
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping([]byte(`
+agent_name: [service.name]
+task_spans:
+  span.kind: []
+model: [llm.model]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapping.AgentName) != 1 || mapping.AgentName[0] != "service.name" {
+		t.Errorf("Expected agent_name to be replaced, got %v", mapping.AgentName)
+	}
+	if _, ok := mapping.TaskSpans["span.kind"]; !ok || len(mapping.TaskSpans) != 1 {
+		t.Errorf("Expected task_spans to be replaced, got %v", mapping.TaskSpans)
+	}
+	if len(mapping.Model) != 1 || mapping.Model[0] != "llm.model" {
+		t.Errorf("Expected model to be replaced, got %v", mapping.Model)
+	}
+	if defaults := DefaultMapping(); len(mapping.LLMSpans["gen_ai.operation.name"]) != len(defaults.LLMSpans["gen_ai.operation.name"]) ||
+		mapping.InputTokens[0] != defaults.InputTokens[0] {
+		t.Errorf("Expected omitted fields to keep their defaults, got %+v", mapping)
+	}
+
+	if _, err := ParseMapping([]byte("agent_name: []")); err == nil {
+		t.Error("Expected an error for an empty agent_name")
+	}
+}
@@ -0,0 +1,213 @@
+// Package query narrows a system snapshot to what a client asks for: some
+// of its sections, the entities matching filters, and the result of a
+// JMESPath expression over what remains.
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+
+	"telemetron/internal/models"
+)
+
+// ErrInvalid is returned for query parameters that cannot be applied.
+var ErrInvalid = errors.New("invalid query")
+
+// Sections lists the sections include may name.
+var Sections = []string{models.SectionAgents, models.SectionWorkload, models.SectionQueues, models.SectionLiteLLM}
+
+// Query selects part of a snapshot. Every filter holds the values it
+// accepts; an empty filter accepts everything.
+type Query struct {
+	// Include lists the sections to return; the others are dropped along
+	// with their status and cache entries.
+	Include []string
+	// Agents keeps the agents with these names.
+	Agents []string
+	// Deployments keeps the workloads, and the agents, deployed as these.
+	Deployments []string
+	// TaskStatuses keeps the agent tasks in these states and drops agents
+	// left without tasks.
+	TaskStatuses []models.TaskState
+	// Providers keeps the LiteLLM entries served by these providers.
+	Providers []string
+	// Expression is a JMESPath expression evaluated on the filtered
+	// snapshot, e.g. "agents[].name".
+	Expression string
+
+	compiled *jmespath.JMESPath
+}
+
+// Parse reads a query from the include, agent, deployment, task_status,
+// provider and query URL parameters. List parameters take comma separated
+// values and may be repeated.
+func Parse(values url.Values) (Query, error) {
+	q := Query{
+		Include:     list(values["include"]),
+		Agents:      list(values["agent"]),
+		Deployments: list(values["deployment"]),
+		Providers:   list(values["provider"]),
+		Expression:  strings.TrimSpace(values.Get("query")),
+	}
+	for _, section := range q.Include {
+		if !validSection(section) {
+			return Query{}, fmt.Errorf("%w: unknown section %q, expected one of %s", ErrInvalid, section, strings.Join(Sections, ", "))
+		}
+	}
+	for _, raw := range list(values["task_status"]) {
+		state := models.TaskState(raw)
+		if !state.Valid() {
+			return Query{}, fmt.Errorf("%w: unknown task status %q", ErrInvalid, raw)
+		}
+		q.TaskStatuses = append(q.TaskStatuses, state)
+	}
+	if q.Expression != "" {
+		compiled, err := jmespath.Compile(q.Expression)
+		if err != nil {
+			return Query{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		q.compiled = compiled
+	}
+	return q, nil
+}
+
+// list splits comma separated parameter values, dropping empty ones.
+func list(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+func validSection(section string) bool {
+	for _, s := range Sections {
+		if section == s {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether the query selects the whole snapshot.
+func (q Query) IsZero() bool {
+	return len(q.Include) == 0 && len(q.Agents) == 0 && len(q.Deployments) == 0 &&
+		len(q.TaskStatuses) == 0 && len(q.Providers) == 0 && q.Expression == ""
+}
+
+// Filter returns a copy of state holding only the entities that match the
+// query's filters. Unfiltered sections and everything they contain are
+// shared with state, which is never modified.
+func (q Query) Filter(state *models.SystemState) *models.SystemState {
+	filtered := *state
+	if len(q.Agents) > 0 || len(q.Deployments) > 0 || len(q.TaskStatuses) > 0 {
+		filtered.Agents = make([]models.Agent, 0, len(state.Agents))
+		for _, agent := range state.Agents {
+			if !accepts(q.Agents, agent.Name) || !accepts(q.Deployments, agent.DeploymentName) {
+				continue
+			}
+			if len(q.TaskStatuses) > 0 {
+				tasks := make([]models.TaskStatus, 0, len(agent.Activity.ActiveTaskIDs))
+				for _, task := range agent.Activity.ActiveTaskIDs {
+					if acceptsState(q.TaskStatuses, task.Status) {
+						tasks = append(tasks, task)
+					}
+				}
+				if len(tasks) == 0 {
+					continue
+				}
+				agent.Activity.ActiveTaskIDs = tasks
+			}
+			filtered.Agents = append(filtered.Agents, agent)
+		}
+	}
+	if len(q.Deployments) > 0 {
+		filtered.Workload = make([]models.Workload, 0, len(state.Workload))
+		for _, workload := range state.Workload {
+			if accepts(q.Deployments, workload.DeploymentName) {
+				filtered.Workload = append(filtered.Workload, workload)
+			}
+		}
+	}
+	if len(q.Providers) > 0 {
+		filtered.LiteLLM = make([]models.LiteLLM, 0, len(state.LiteLLM))
+		for _, entry := range state.LiteLLM {
+			if accepts(q.Providers, entry.Provider) {
+				filtered.LiteLLM = append(filtered.LiteLLM, entry)
+			}
+		}
+	}
+	return &filtered
+}
+
+func accepts(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func acceptsState(states []models.TaskState, state models.TaskState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply filters state and returns what the query selects: the snapshot
+// itself when no sections are excluded and there is no expression, else
+// its JSON document with the excluded sections removed, or the result of
+// the expression on that document.
+func (q Query) Apply(state *models.SystemState) (interface{}, error) {
+	filtered := q.Filter(state)
+	if len(q.Include) == 0 && q.compiled == nil {
+		return filtered, nil
+	}
+
+	data, err := json.Marshal(filtered)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(q.Include) > 0 {
+		status, _ := doc["status"].(map[string]interface{})
+		cache, _ := doc["cache"].(map[string]interface{})
+		for _, section := range Sections {
+			if accepts(q.Include, section) {
+				continue
+			}
+			delete(doc, section)
+			delete(status, section)
+			delete(cache, section)
+		}
+	}
+
+	if q.compiled == nil {
+		return doc, nil
+	}
+	result, err := q.compiled.Search(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"telemetron/internal/models"
+)
+
+func testState() *models.SystemState {
+	return &models.SystemState{
+		Agents: []models.Agent{{
+			Name:           "agent-1",
+			DeploymentName: "deploy-1",
+			Activity: models.Activity{ActiveTaskIDs: []models.TaskStatus{
+				{ID: "task-1", Status: models.TaskRunning},
+				{ID: "task-2", Status: models.TaskFailed},
+			}},
+		}, {
+			Name:           "agent-2",
+			DeploymentName: "deploy-2",
+			Activity: models.Activity{ActiveTaskIDs: []models.TaskStatus{
+				{ID: "task-3", Status: models.TaskRunning},
+			}},
+		}},
+		Workload: []models.Workload{{DeploymentName: "deploy-1"}, {DeploymentName: "deploy-2"}},
+		Queues:   []models.Queue{{Name: "default"}},
+		LiteLLM: []models.LiteLLM{
+			{Model: "gpt-4", Provider: "openai"},
+			{Model: "claude-3-opus", Provider: "anthropic"},
+		},
+		Status: map[string]models.SectionStatus{
+			models.SectionAgents:   {Status: models.SectionOK},
+			models.SectionWorkload: {Status: models.SectionOK},
+			models.SectionQueues:   {Status: models.SectionOK},
+			models.SectionLiteLLM:  {Status: models.SectionOK},
+		},
+	}
+}
+
+func mustParse(t *testing.T, raw string) Query {
+	t.Helper()
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Parse(values)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", raw, err)
+	}
+	return q
+}
+
+func TestParse(t *testing.T) {
+	q := mustParse(t, "include=agents,+queues&agent=agent-1&agent=agent-2&task_status=failed")
+	if len(q.Include) != 2 || q.Include[1] != "queues" || len(q.Agents) != 2 || q.TaskStatuses[0] != models.TaskFailed {
+		t.Errorf("Expected comma separated and repeated values, got %+v", q)
+	}
+	if !mustParse(t, "at=2024-01-01T00:00:00Z").IsZero() {
+		t.Error("Expected a query without its parameters to be zero")
+	}
+
+	for _, raw := range []string{"include=pods", "task_status=done", "query=agents[", "query=agents[?name=="} {
+		values, _ := url.ParseQuery(raw)
+		if _, err := Parse(values); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q): expected ErrInvalid, got %v", raw, err)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	state := testState()
+
+	filtered := mustParse(t, "task_status=failed&provider=anthropic").Filter(state)
+	if len(filtered.Agents) != 1 || len(filtered.Agents[0].Activity.ActiveTaskIDs) != 1 ||
+		filtered.Agents[0].Activity.ActiveTaskIDs[0].ID != "task-2" {
+		t.Errorf("Expected only agent-1 with its failed task, got %+v", filtered.Agents)
+	}
+	if len(filtered.LiteLLM) != 1 || filtered.LiteLLM[0].Model != "claude-3-opus" {
+		t.Errorf("Expected only the anthropic model, got %+v", filtered.LiteLLM)
+	}
+	if len(filtered.Workload) != 2 || len(filtered.Queues) != 1 {
+		t.Error("Expected unfiltered sections to be kept whole")
+	}
+	if len(state.Agents) != 2 || len(state.Agents[0].Activity.ActiveTaskIDs) != 2 || len(state.LiteLLM) != 2 {
+		t.Error("Expected the original state to be left unchanged")
+	}
+
+	filtered = mustParse(t, "deployment=deploy-2").Filter(state)
+	if len(filtered.Agents) != 1 || filtered.Agents[0].Name != "agent-2" ||
+		len(filtered.Workload) != 1 || filtered.Workload[0].DeploymentName != "deploy-2" {
+		t.Errorf("Expected the deploy-2 workload and agent, got %+v and %+v", filtered.Workload, filtered.Agents)
+	}
+
+	if filtered = mustParse(t, "agent=agent-1&deployment=deploy-2").Filter(state); len(filtered.Agents) != 0 {
+		t.Errorf("Expected filters to combine, got %+v", filtered.Agents)
+	}
+}
+
+func TestApply(t *testing.T) {
+	out, err := mustParse(t, "include=queues").Apply(testState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := out.(map[string]interface{})
+	if _, ok := doc["agents"]; ok {
+		t.Error("Expected the agents section to be dropped")
+	}
+	if _, ok := doc["queues"]; !ok {
+		t.Error("Expected the queues section to be kept")
+	}
+	if status := doc["status"].(map[string]interface{}); len(status) != 1 || status["queues"] == nil {
+		t.Errorf("Expected only the queues status, got %v", status)
+	}
+
+	out, err = mustParse(t, "agent=agent-2&query=agents[].activity.active_task_ids[].id").Apply(testState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids, ok := out.([]interface{}); !ok || len(ids) != 1 || ids[0] != "task-3" {
+		t.Errorf("Expected the expression to run on the filtered state, got %v", out)
+	}
+
+	if _, err := mustParse(t, "query=abs(agents)").Apply(testState()); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a function given the wrong type, got %v", err)
+	}
+
+	if out, _ := (Query{}).Apply(testState()); len(out.(*models.SystemState).Agents) != 2 {
+		t.Errorf("Expected the zero query to return the whole snapshot, got %v", out)
+	}
+}
@@ -1,24 +1,69 @@
 // internal/repositories/interfaces.go
 package repositories
 
-import "telemetron/internal/models"
+import (
+	"context"
+	"errors"
+
+	"telemetron/internal/models"
+)
+
+// ErrNotFound is returned by Get for keys the source does not have.
+var ErrNotFound = errors.New("not found")
 
 type AgentRepository interface {
-	GetAll() ([]models.Agent, error)
+	GetAll(ctx context.Context) ([]models.Agent, error)
+	// Get returns the agent with name.
+	Get(ctx context.Context, name string) (*models.Agent, error)
 	Close()
 }
 
 type WorkloadRepository interface {
-	GetAll() ([]models.Workload, error)
+	GetAll(ctx context.Context) ([]models.Workload, error)
+	// Get returns the workload of deployment.
+	Get(ctx context.Context, deployment string) (*models.Workload, error)
 	Close()
 }
 
 type QueueRepository interface {
-	GetAll() ([]models.Queue, error)
+	GetAll(ctx context.Context) ([]models.Queue, error)
+	// Get returns the queue with name.
+	Get(ctx context.Context, name string) (*models.Queue, error)
 	Close()
 }
 
 type LiteLLMRepository interface {
-	GetAll() ([]models.LiteLLM, error)
+	GetAll(ctx context.Context) ([]models.LiteLLM, error)
+	// Get returns the entries of model, one per provider serving it.
+	Get(ctx context.Context, model string) ([]models.LiteLLM, error)
 	Close()
 }
+
+// find returns the first item whose key is want, or ErrNotFound, for
+// sources that hold their entities in memory.
+func find[T any](items []T, want string, key func(T) string) (*T, error) {
+	for i := range items {
+		if key(items[i]) == want {
+			return &items[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func workloadDeployment(w models.Workload) string { return w.DeploymentName }
+func queueName(queue models.Queue) string         { return queue.Name }
+
+// filterModel returns the entries of model, or ErrNotFound when there are
+// none.
+func filterModel(entries []models.LiteLLM, model string) ([]models.LiteLLM, error) {
+	var found []models.LiteLLM
+	for _, entry := range entries {
+		if entry.Model == model {
+			found = append(found, entry)
+		}
+	}
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
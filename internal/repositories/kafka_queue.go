@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"telemetron/internal/models"
+)
+
+// KafkaQueueRepository treats Kafka topics as task queues. Each topic
+// reports the depth of its partitions and, for every consumer group that
+// has members assigned to it or has committed offsets in it, the group's
+// per-partition lag behind the end offsets.
+//
+// Records are not read, so Kafka queues carry no tasks.
+type KafkaQueueRepository struct {
+	client *kgo.Client
+	admin  *kadm.Client
+	topics []string
+	now    func() time.Time
+}
+
+// NewKafkaQueueRepository reports the given topics, or every non-internal
+// topic when none are given.
+func NewKafkaQueueRepository(client *kgo.Client, topics []string) *KafkaQueueRepository {
+	return &KafkaQueueRepository{
+		client: client,
+		admin:  kadm.NewClient(client),
+		topics: topics,
+		now:    time.Now,
+	}
+}
+
+// NewKafkaQueueRepositoryFromBrokers connects to the given seed brokers.
+func NewKafkaQueueRepositoryFromBrokers(brokers, topics []string) (*KafkaQueueRepository, error) {
+	client, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	if err != nil {
+		return nil, fmt.Errorf("create kafka client: %w", err)
+	}
+	return NewKafkaQueueRepository(client, topics), nil
+}
+
+func (r *KafkaQueueRepository) GetAll(ctx context.Context) ([]models.Queue, error) {
+	details, err := r.admin.ListTopics(ctx, r.topics...)
+	if err != nil {
+		return nil, fmt.Errorf("list topics: %w", err)
+	}
+	var topics []string
+	for _, detail := range details.Sorted() {
+		if detail.Err != nil {
+			return nil, fmt.Errorf("describe topic %s: %w", detail.Topic, detail.Err)
+		}
+		topics = append(topics, detail.Topic)
+	}
+	if len(topics) == 0 {
+		return []models.Queue{}, nil
+	}
+	return r.describe(ctx, topics)
+}
+
+// Get lists only the named topic, which must be one of the configured
+// topics or, without any, not internal.
+func (r *KafkaQueueRepository) Get(ctx context.Context, name string) (*models.Queue, error) {
+	if len(r.topics) > 0 && !slices.Contains(r.topics, name) {
+		return nil, ErrNotFound
+	}
+	details, err := r.admin.ListTopics(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("list topics: %w", err)
+	}
+	detail, ok := details[name]
+	if !ok || errors.Is(detail.Err, kerr.UnknownTopicOrPartition) || len(r.topics) == 0 && detail.IsInternal {
+		return nil, ErrNotFound
+	}
+	if detail.Err != nil {
+		return nil, fmt.Errorf("describe topic %s: %w", name, detail.Err)
+	}
+
+	queues, err := r.describe(ctx, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	return &queues[0], nil
+}
+
+// describe reads the partition offsets and consumer group lag of topics.
+func (r *KafkaQueueRepository) describe(ctx context.Context, topics []string) ([]models.Queue, error) {
+	start, err := r.admin.ListStartOffsets(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("list start offsets: %w", err)
+	}
+	end, err := r.admin.ListEndOffsets(ctx, topics...)
+	if err != nil {
+		return nil, fmt.Errorf("list end offsets: %w", err)
+	}
+	groups, err := r.consumerGroups(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt := r.now().UTC()
+	queues := make([]models.Queue, 0, len(topics))
+	for _, topic := range topics {
+		queue := models.Queue{
+			Name:           topic,
+			UpdatedAt:      updatedAt,
+			Tasks:          []models.QueueTask{},
+			ConsumerGroups: groups[topic],
+		}
+		for partition, offset := range end[topic] {
+			if offset.Err != nil {
+				return nil, fmt.Errorf("end offset of %s/%d: %w", topic, partition, offset.Err)
+			}
+			startOffset := start[topic][partition].Offset
+			queue.Partitions = append(queue.Partitions, models.Partition{
+				Partition:   partition,
+				StartOffset: startOffset,
+				EndOffset:   offset.Offset,
+				Depth:       offset.Offset - startOffset,
+			})
+		}
+		sort.Slice(queue.Partitions, func(i, j int) bool {
+			return queue.Partitions[i].Partition < queue.Partitions[j].Partition
+		})
+		queues = append(queues, queue)
+	}
+	return queues, nil
+}
+
+func (r *KafkaQueueRepository) Close() {
+	r.client.Close()
+}
+
+// consumerGroups computes the lag of every consumer group against the
+// listed offsets, keyed by topic and ordered by group name.
+func (r *KafkaQueueRepository) consumerGroups(ctx context.Context, start, end kadm.ListedOffsets) (map[string][]models.ConsumerGroup, error) {
+	listed, err := r.admin.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list consumer groups: %w", err)
+	}
+	names := listed.Groups()
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	described, err := r.admin.DescribeGroups(ctx, names...)
+	if err != nil {
+		return nil, fmt.Errorf("describe consumer groups: %w", err)
+	}
+	committed := r.admin.FetchManyOffsets(ctx, names...)
+
+	byTopic := make(map[string][]models.ConsumerGroup)
+	for _, name := range names {
+		group, ok := described[name]
+		if !ok || group.ProtocolType != "consumer" && group.ProtocolType != "" {
+			continue
+		}
+		if group.Err != nil {
+			return nil, fmt.Errorf("describe consumer group %s: %w", name, group.Err)
+		}
+		if fetched := committed[name]; fetched.Err != nil {
+			return nil, fmt.Errorf("fetch offsets of %s: %w", name, fetched.Err)
+		}
+
+		lag := kadm.CalculateGroupLagWithStartOffsets(group, committed[name].Fetched, start, end)
+		for topic, partitions := range lag {
+			if _, ok := end[topic]; !ok {
+				continue
+			}
+
+			consumerGroup := models.ConsumerGroup{Name: name}
+			members := make(map[string]bool)
+			for _, partition := range partitions {
+				if partition.Err != nil {
+					continue
+				}
+				if partition.Member != nil {
+					members[partition.Member.MemberID] = true
+				}
+				consumerGroup.Lag += partition.Lag
+				consumerGroup.Partitions = append(consumerGroup.Partitions, models.PartitionLag{
+					Partition:       partition.Partition,
+					CommittedOffset: partition.Commit.At,
+					EndOffset:       partition.End.Offset,
+					Lag:             partition.Lag,
+				})
+			}
+			sort.Slice(consumerGroup.Partitions, func(i, j int) bool {
+				return consumerGroup.Partitions[i].Partition < consumerGroup.Partitions[j].Partition
+			})
+			consumerGroup.Consumers = len(members)
+			byTopic[topic] = append(byTopic[topic], consumerGroup)
+		}
+	}
+	return byTopic, nil
+}
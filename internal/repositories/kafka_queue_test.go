@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/config"
+)
+
+// newTestKafka starts an in-process cluster with a two-partition "tasks"
+// topic holding five records in partition 0 and three in partition 1, and
+// an empty "idle" topic.
+func newTestKafka(t *testing.T) (*kfake.Cluster, *kgo.Client) {
+	t.Helper()
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(2, "tasks"), kfake.SeedTopics(1, "idle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cluster.Close)
+
+	client, err := kgo.NewClient(kgo.SeedBrokers(cluster.ListenAddrs()...), kgo.RecordPartitioner(kgo.ManualPartitioner()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	var records []*kgo.Record
+	for i := 0; i < 8; i++ {
+		records = append(records, &kgo.Record{Topic: "tasks", Partition: int32(i / 5), Value: []byte("task")})
+	}
+	if err := client.ProduceSync(context.Background(), records...).FirstErr(); err != nil {
+		t.Fatal(err)
+	}
+	return cluster, client
+}
+
+func TestKafkaQueueRepository_GetAll(t *testing.T) {
+	cluster, client := newTestKafka(t)
+	ctx := context.Background()
+
+	// The "billing" group has processed three records of partition 0 and
+	// none of partition 1.
+	var offsets kadm.Offsets
+	offsets.AddOffset("tasks", 0, 3, -1)
+	if _, err := kadm.NewClient(client).CommitOffsets(ctx, "billing", offsets); err != nil {
+		t.Fatal(err)
+	}
+
+	repoClient, err := kgo.NewClient(kgo.SeedBrokers(cluster.ListenAddrs()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewKafkaQueueRepository(repoClient, nil)
+	defer repo.Close()
+
+	queues, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(queues) != 2 || queues[0].Name != "idle" || queues[1].Name != "tasks" {
+		t.Fatalf("Expected the idle and tasks topics, got %+v", queues)
+	}
+
+	tasks := queues[1]
+	if len(tasks.Tasks) != 0 {
+		t.Errorf("Expected no tasks to be read, got %+v", tasks.Tasks)
+	}
+	want := []models.Partition{
+		{Partition: 0, StartOffset: 0, EndOffset: 5, Depth: 5},
+		{Partition: 1, StartOffset: 0, EndOffset: 3, Depth: 3},
+	}
+	if len(tasks.Partitions) != 2 || tasks.Partitions[0] != want[0] || tasks.Partitions[1] != want[1] {
+		t.Errorf("Expected partitions %+v, got %+v", want, tasks.Partitions)
+	}
+
+	if len(tasks.ConsumerGroups) != 1 {
+		t.Fatalf("Expected one consumer group, got %+v", tasks.ConsumerGroups)
+	}
+	group := tasks.ConsumerGroups[0]
+	if group.Name != "billing" || group.Consumers != 0 || group.Lag != 5 {
+		t.Errorf("Unexpected consumer group %+v", group)
+	}
+	wantLag := []models.PartitionLag{
+		{Partition: 0, CommittedOffset: 3, EndOffset: 5, Lag: 2},
+		{Partition: 1, CommittedOffset: -1, EndOffset: 3, Lag: 3},
+	}
+	if len(group.Partitions) != 2 || group.Partitions[0] != wantLag[0] || group.Partitions[1] != wantLag[1] {
+		t.Errorf("Expected an uncommitted partition to lag entirely, got %+v", group.Partitions)
+	}
+	if queues[0].ConsumerGroups != nil {
+		t.Errorf("Expected no consumer groups on the idle topic, got %+v", queues[0].ConsumerGroups)
+	}
+
+	queue, err := repo.Get(ctx, "tasks")
+	if err != nil || len(queue.Partitions) != 2 || len(queue.ConsumerGroups) != 1 || queue.ConsumerGroups[0].Lag != 5 {
+		t.Errorf("Expected Get to describe the topic like GetAll, got %+v, %v", queue, err)
+	}
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown topic, got %v", err)
+	}
+	if _, err := NewKafkaQueueRepository(repoClient, []string{"tasks"}).Get(ctx, "idle"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a topic that is not configured, got %v", err)
+	}
+}
+
+func TestKafkaQueueRepository_ActiveConsumer(t *testing.T) {
+	cluster, _ := newTestKafka(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumerGroup("workers"),
+		kgo.ConsumeTopics("tasks"),
+		kgo.DisableAutoCommit(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+	if fetches := consumer.PollRecords(ctx, 1); fetches.Err() != nil {
+		t.Fatal(fetches.Err())
+	}
+
+	repo, err := NewKafkaQueueRepositoryFromBrokers(cluster.ListenAddrs(), []string{"tasks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	queues, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(queues) != 1 || len(queues[0].ConsumerGroups) != 1 {
+		t.Fatalf("Expected the tasks topic with one consumer group, got %+v", queues)
+	}
+
+	// Nothing is committed, so the assigned member lags by the whole topic.
+	group := queues[0].ConsumerGroups[0]
+	if group.Name != "workers" || group.Consumers != 1 || group.Lag != 8 || len(group.Partitions) != 2 {
+		t.Errorf("Unexpected consumer group %+v", group)
+	}
+	if group.Partitions[1].CommittedOffset != -1 {
+		t.Errorf("Expected no committed offset, got %+v", group.Partitions[1])
+	}
+}
+
+func TestRegistryBuild_Kafka(t *testing.T) {
+	registry := NewRegistry()
+	if _, err := registry.Build(&config.Config{EnableMockData: true, QueueBackend: "kafka"}); err == nil {
+		t.Error("Expected an error without KAFKA_BROKERS")
+	}
+
+	repos, err := registry.Build(&config.Config{EnableMockData: true, QueueBackend: "kafka", KafkaBrokers: "localhost:9092", KafkaTopics: "tasks, jobs"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer repos.Close()
+	repo, ok := repos.Queues.(*KafkaQueueRepository)
+	if !ok {
+		t.Fatalf("Expected Kafka queue repository, got %T", repos.Queues)
+	}
+	if len(repo.topics) != 2 || repo.topics[1] != "jobs" {
+		t.Errorf("Expected the topic list to be split, got %q", repo.topics)
+	}
+}
@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/logger"
+)
+
+const bytesPerMiB = 1024 * 1024
+
+// KubernetesWorkloadRepository reads Deployments and their pods from the
+// Kubernetes API server and live pod usage from the metrics API.
+type KubernetesWorkloadRepository struct {
+	client    kubernetes.Interface
+	metrics   metricsclient.Interface
+	namespace string
+	selector  string
+}
+
+// NewKubernetesWorkloadRepository wraps existing clientsets. The metrics
+// client may be nil, in which case pods are reported without usage.
+func NewKubernetesWorkloadRepository(client kubernetes.Interface, metrics metricsclient.Interface, namespace, selector string) *KubernetesWorkloadRepository {
+	return &KubernetesWorkloadRepository{
+		client:    client,
+		metrics:   metrics,
+		namespace: namespace,
+		selector:  selector,
+	}
+}
+
+// NewKubernetesWorkloadRepositoryFromKubeconfig builds clientsets from the
+// kubeconfig at path, falling back to in-cluster config when path is empty.
+func NewKubernetesWorkloadRepositoryFromKubeconfig(path, namespace, selector string) (*KubernetesWorkloadRepository, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", path)
+	if err != nil {
+		return nil, fmt.Errorf("load kubernetes config: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create kubernetes client: %w", err)
+	}
+
+	metrics, err := metricsclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create metrics client: %w", err)
+	}
+
+	return NewKubernetesWorkloadRepository(client, metrics, namespace, selector), nil
+}
+
+func (r *KubernetesWorkloadRepository) GetAll(ctx context.Context) ([]models.Workload, error) {
+	deployments, err := r.client.AppsV1().Deployments(r.namespace).List(ctx, metav1.ListOptions{LabelSelector: r.selector})
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+
+	maxReplicas := r.autoscalerLimits(ctx, r.namespace)
+	usage := r.podUsage(ctx, r.namespace, "")
+
+	workloads := make([]models.Workload, 0, len(deployments.Items))
+	for _, deployment := range deployments.Items {
+		workload, err := r.buildWorkload(ctx, deployment, maxReplicas, usage)
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, workload)
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		return workloads[i].DeploymentName < workloads[j].DeploymentName
+	})
+	return workloads, nil
+}
+
+// Get lists only the named Deployment, still subject to the label
+// selector, and the pods, usage and autoscalers of its namespace. Without
+// a namespace, the first match by namespace is returned.
+func (r *KubernetesWorkloadRepository) Get(ctx context.Context, deployment string) (*models.Workload, error) {
+	deployments, err := r.client.AppsV1().Deployments(r.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: r.selector,
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", deployment).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get deployment %s: %w", deployment, err)
+	}
+
+	var found *appsv1.Deployment
+	for i := range deployments.Items {
+		item := &deployments.Items[i]
+		if item.Name == deployment && (found == nil || item.Namespace < found.Namespace) {
+			found = item
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+
+	maxReplicas := r.autoscalerLimits(ctx, found.Namespace)
+	selector, err := metav1.LabelSelectorAsSelector(found.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("deployment %s selector: %w", found.Name, err)
+	}
+	usage := r.podUsage(ctx, found.Namespace, selector.String())
+
+	workload, err := r.buildWorkload(ctx, *found, maxReplicas, usage)
+	if err != nil {
+		return nil, err
+	}
+	return &workload, nil
+}
+
+func (r *KubernetesWorkloadRepository) Close() {}
+
+func (r *KubernetesWorkloadRepository) buildWorkload(
+	ctx context.Context,
+	deployment appsv1.Deployment,
+	maxReplicas map[string]int,
+	usage map[string]corev1.ResourceList,
+) (models.Workload, error) {
+	workload := models.Workload{
+		DeploymentName: deployment.Name,
+		MaxPods:        desiredReplicas(deployment),
+		Pods:           []models.Pod{},
+	}
+	if max, ok := maxReplicas[deployment.Namespace+"/"+deployment.Name]; ok {
+		workload.MaxPods = max
+	}
+
+	limits := podTemplateLimits(deployment.Spec.Template.Spec)
+	if cpu, ok := limits[corev1.ResourceCPU]; ok {
+		workload.PodMaxCPU = toQuantity(cpu)
+	}
+	if memory, ok := limits[corev1.ResourceMemory]; ok {
+		workload.PodMaxRAM = toQuantity(memory)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return models.Workload{}, fmt.Errorf("deployment %s selector: %w", deployment.Name, err)
+	}
+
+	pods, err := r.client.CoreV1().Pods(deployment.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return models.Workload{}, fmt.Errorf("list pods for deployment %s: %w", deployment.Name, err)
+	}
+
+	for _, pod := range pods.Items {
+		entry := models.Pod{
+			PodID:  pod.Name,
+			Status: strings.ToLower(string(pod.Status.Phase)),
+		}
+		if used, ok := usage[pod.Namespace+"/"+pod.Name]; ok {
+			cpu := used[corev1.ResourceCPU]
+			memory := used[corev1.ResourceMemory]
+			entry.CPU = float64(cpu.MilliValue()) / 1000
+			entry.Memory = int(memory.Value() / bytesPerMiB)
+		}
+		if pod.Status.Phase == corev1.PodRunning {
+			workload.Live.ActivePods++
+		}
+		workload.Pods = append(workload.Pods, entry)
+	}
+
+	sort.Slice(workload.Pods, func(i, j int) bool {
+		return workload.Pods[i].PodID < workload.Pods[j].PodID
+	})
+	workload.Live.UpdatedAt = time.Now().UTC()
+	return workload, nil
+}
+
+// toQuantity converts a Kubernetes quantity, keeping whether it was written
+// with binary suffixes.
+func toQuantity(q resource.Quantity) models.Quantity {
+	format := models.DecimalSI
+	if q.Format == resource.BinarySI {
+		format = models.BinarySI
+	}
+	return models.NewMilliQuantity(q.MilliValue(), format)
+}
+
+// autoscalerLimits maps namespace/deployment to the maxReplicas of the
+// HorizontalPodAutoscaler targeting it, for the deployments in namespace.
+// Reading autoscalers may not be permitted, so a failure here is logged and
+// leaves MaxPods at the deployment's desired replicas.
+func (r *KubernetesWorkloadRepository) autoscalerLimits(ctx context.Context, namespace string) map[string]int {
+	hpas, err := r.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Log.Warn("Failed to list horizontal pod autoscalers, using desired replicas as max pods",
+			zap.String("namespace", namespace), zap.Error(err))
+		return map[string]int{}
+	}
+
+	limits := make(map[string]int, len(hpas.Items))
+	for _, hpa := range hpas.Items {
+		if hpa.Spec.ScaleTargetRef.Kind != "Deployment" {
+			continue
+		}
+		limits[hpa.Namespace+"/"+hpa.Spec.ScaleTargetRef.Name] = int(hpa.Spec.MaxReplicas)
+	}
+	return limits
+}
+
+// podUsage maps namespace/pod to summed container usage, for the pods in
+// namespace matching selector. The metrics API is an optional add-on, so a
+// failure here yields empty usage rather than an error; it is logged so
+// that missing metrics can be told from idle pods.
+func (r *KubernetesWorkloadRepository) podUsage(ctx context.Context, namespace, selector string) map[string]corev1.ResourceList {
+	usage := make(map[string]corev1.ResourceList)
+	if r.metrics == nil {
+		return usage
+	}
+
+	podMetrics, err := r.metrics.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		logger.Log.Warn("Failed to list pod metrics, reporting no usage",
+			zap.String("namespace", namespace), zap.Error(err))
+		return usage
+	}
+
+	for _, item := range podMetrics.Items {
+		usage[item.Namespace+"/"+item.Name] = sumContainerUsage(item.Containers)
+	}
+	return usage
+}
+
+func desiredReplicas(deployment appsv1.Deployment) int {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return int(*deployment.Spec.Replicas)
+}
+
+func podTemplateLimits(spec corev1.PodSpec) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		addResources(total, container.Resources.Limits)
+	}
+	return total
+}
+
+func sumContainerUsage(containers []metricsv1beta1.ContainerMetrics) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, container := range containers {
+		addResources(total, container.Usage)
+	}
+	return total
+}
+
+func addResources(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
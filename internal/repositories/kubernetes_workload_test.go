@@ -0,0 +1,231 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func newKubeFixtures() (*fake.Clientset, *metricsfake.Clientset) {
+	labels := map[string]string{"app": "agent-1"}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-deployment-1", Namespace: "agents"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(2),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "agent",
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("750m"),
+									corev1.ResourceMemory: resource.MustParse("1536Mi"),
+								},
+							},
+						},
+						{
+							Name: "sidecar",
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("250m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-1-hpa", Namespace: "agents"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "agent-deployment-1"},
+			MaxReplicas:    10,
+		},
+	}
+
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-1-a", Namespace: "agents", Labels: labels},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-1-b", Namespace: "agents", Labels: labels},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	unrelated := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "agents", Labels: map[string]string{"app": "other"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	client := fake.NewSimpleClientset(deployment, hpa, running, pending, unrelated)
+
+	metrics := metricsfake.NewSimpleClientset()
+	podMetrics := &metricsv1beta1.PodMetrics{
+		// The metrics server copies the pod's labels.
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-1-a", Namespace: "agents", Labels: labels},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "agent", Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("400m"),
+				corev1.ResourceMemory: resource.MustParse("768Mi"),
+			}},
+			{Name: "sidecar", Usage: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			}},
+		},
+	}
+	// The generated fake cannot infer the "pods" resource for PodMetrics,
+	// so the object is added to the tracker explicitly.
+	gvr := schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	if err := metrics.Tracker().Create(gvr, runtime.Object(podMetrics), "agents"); err != nil {
+		panic(err)
+	}
+
+	return client, metrics
+}
+
+func TestKubernetesWorkloadRepository(t *testing.T) {
+	client, metrics := newKubeFixtures()
+	repo := NewKubernetesWorkloadRepository(client, metrics, "agents", "")
+	defer repo.Close()
+
+	workloads, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(workloads) != 1 {
+		t.Fatalf("Expected 1 workload, got %d", len(workloads))
+	}
+
+	workload := workloads[0]
+	if workload.DeploymentName != "agent-deployment-1" {
+		t.Errorf("Expected deployment 'agent-deployment-1', got %s", workload.DeploymentName)
+	}
+
+	if workload.MaxPods != 10 {
+		t.Errorf("Expected MaxPods from HPA to be 10, got %d", workload.MaxPods)
+	}
+
+	if workload.PodMaxCPU.String() != "1" {
+		t.Errorf("Expected summed CPU limit '1', got %s", workload.PodMaxCPU)
+	}
+
+	if workload.PodMaxRAM.String() != "2Gi" {
+		t.Errorf("Expected summed memory limit '2Gi', got %s", workload.PodMaxRAM)
+	}
+
+	if workload.Live.ActivePods != 1 {
+		t.Errorf("Expected 1 active pod, got %d", workload.Live.ActivePods)
+	}
+
+	if len(workload.Pods) != 2 {
+		t.Fatalf("Expected 2 pods matching the selector, got %d", len(workload.Pods))
+	}
+
+	pod := workload.Pods[0]
+	if pod.PodID != "agent-1-a" || pod.Status != "running" {
+		t.Errorf("Unexpected pod %+v", pod)
+	}
+
+	if pod.CPU != 0.5 {
+		t.Errorf("Expected CPU 0.5 cores, got %v", pod.CPU)
+	}
+
+	if pod.Memory != 1024 {
+		t.Errorf("Expected memory 1024 MiB, got %d", pod.Memory)
+	}
+
+	if workload.Pods[1].Status != "pending" || workload.Pods[1].CPU != 0 {
+		t.Errorf("Expected pending pod without usage, got %+v", workload.Pods[1])
+	}
+
+	found, err := repo.Get(context.Background(), "agent-deployment-1")
+	if err != nil || len(found.Pods) != 2 || found.MaxPods != 10 || found.Pods[0].CPU != workload.Pods[0].CPU {
+		t.Errorf("Expected Get to build the deployment like GetAll, got %+v, %v", found, err)
+	}
+	if _, err := repo.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown deployment, got %v", err)
+	}
+}
+
+func TestKubernetesWorkloadRepository_WithoutMetrics(t *testing.T) {
+	client, _ := newKubeFixtures()
+	repo := NewKubernetesWorkloadRepository(client, nil, "agents", "")
+
+	workloads, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(workloads) != 1 || len(workloads[0].Pods) != 2 {
+		t.Fatalf("Expected pods without metrics, got %+v", workloads)
+	}
+}
+
+func TestKubernetesWorkloadRepository_AutoscalersForbidden(t *testing.T) {
+	client, _ := newKubeFixtures()
+	client.PrependReactor("list", "horizontalpodautoscalers", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("horizontalpodautoscalers is forbidden")
+	})
+	repo := NewKubernetesWorkloadRepository(client, nil, "agents", "")
+
+	workloads, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(workloads) != 1 || workloads[0].MaxPods != 2 {
+		t.Fatalf("Expected MaxPods to fall back to replicas, got %+v", workloads)
+	}
+
+	workload, err := repo.Get(context.Background(), "agent-deployment-1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if workload.MaxPods != 2 {
+		t.Errorf("Expected MaxPods to fall back to replicas, got %d", workload.MaxPods)
+	}
+}
+
+func TestKubernetesWorkloadRepository_DefaultsToReplicas(t *testing.T) {
+	client := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-hpa", Namespace: "agents"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(3),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "no-hpa"}},
+		},
+	})
+	repo := NewKubernetesWorkloadRepository(client, nil, "agents", "")
+
+	workloads, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if workloads[0].MaxPods != 3 {
+		t.Errorf("Expected MaxPods to fall back to replicas, got %d", workloads[0].MaxPods)
+	}
+
+	if !workloads[0].PodMaxCPU.IsZero() || !workloads[0].PodMaxRAM.IsZero() {
+		t.Errorf("Expected no limits, got cpu=%s ram=%s", workloads[0].PodMaxCPU, workloads[0].PodMaxRAM)
+	}
+}
@@ -0,0 +1,279 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/logger"
+)
+
+const (
+	// litellmUsageWindow is the span of spend logs TPM and RPM are
+	// computed over.
+	litellmUsageWindow = time.Minute
+	// litellmMaxPages bounds how many spend log pages one fetch reads.
+	// Usage beyond it is not counted, and a warning says so.
+	litellmMaxPages = 50
+	litellmPageSize = 100
+)
+
+// LiteLLMProxyRepository reads model deployments and their limits from a
+// LiteLLM proxy's /model/info endpoint and derives current usage from the
+// last minute of /spend/logs/v2.
+type LiteLLMProxyRepository struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewLiteLLMProxyRepository queries the proxy at baseURL, authenticating
+// with apiKey (a master or admin virtual key). A nil client uses one with
+// a 30 second timeout.
+func NewLiteLLMProxyRepository(baseURL, apiKey string, client *http.Client) *LiteLLMProxyRepository {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &LiteLLMProxyRepository{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  client,
+		now:     time.Now,
+	}
+}
+
+type litellmModelInfoResponse struct {
+	Data []struct {
+		ModelName     string `json:"model_name"`
+		LiteLLMParams struct {
+			Model             string `json:"model"`
+			CustomLLMProvider string `json:"custom_llm_provider"`
+			TPM               *int   `json:"tpm"`
+			RPM               *int   `json:"rpm"`
+		} `json:"litellm_params"`
+		ModelInfo struct {
+			LiteLLMProvider     string  `json:"litellm_provider"`
+			TPM                 *int    `json:"tpm"`
+			RPM                 *int    `json:"rpm"`
+			InputCostPerToken   float64 `json:"input_cost_per_token"`
+			OutputCostPerToken  float64 `json:"output_cost_per_token"`
+			InputCostPerSecond  float64 `json:"input_cost_per_second"`
+			OutputCostPerSecond float64 `json:"output_cost_per_second"`
+			PaymentType         string  `json:"payment_type"`
+		} `json:"model_info"`
+	} `json:"data"`
+}
+
+type litellmSpendLogsResponse struct {
+	Data []struct {
+		Model             string `json:"model"`
+		ModelGroup        string `json:"model_group"`
+		CustomLLMProvider string `json:"custom_llm_provider"`
+		TotalTokens       int    `json:"total_tokens"`
+		StartTime         string `json:"startTime"`
+	} `json:"data"`
+	TotalPages int `json:"total_pages"`
+}
+
+func (r *LiteLLMProxyRepository) GetAll(ctx context.Context) ([]models.LiteLLM, error) {
+	return r.fetch(ctx, "")
+}
+
+// Get reads the spend logs only when the model is configured. The spend
+// logs cannot be filtered by model group, so a hit reads as many pages as
+// GetAll does.
+func (r *LiteLLMProxyRepository) Get(ctx context.Context, model string) ([]models.LiteLLM, error) {
+	entries, err := r.fetch(ctx, model)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+	return entries, nil
+}
+
+func (r *LiteLLMProxyRepository) Close() {}
+
+// fetch returns the entries of model, or of every model when it is empty.
+func (r *LiteLLMProxyRepository) fetch(ctx context.Context, model string) ([]models.LiteLLM, error) {
+	var info litellmModelInfoResponse
+	if err := r.get(ctx, "/model/info", nil, &info); err != nil {
+		return nil, fmt.Errorf("get model info: %w", err)
+	}
+
+	// Deployments sharing a model name and provider are load balanced by
+	// the proxy, so their limits add up, unless one of them is unlimited.
+	byKey := make(map[[2]string]*models.LiteLLM)
+	unlimitedTPM := make(map[[2]string]bool)
+	unlimitedRPM := make(map[[2]string]bool)
+	for _, deployment := range info.Data {
+		if model != "" && deployment.ModelName != model {
+			continue
+		}
+		provider := deployment.ModelInfo.LiteLLMProvider
+		if provider == "" {
+			provider = deployment.LiteLLMParams.CustomLLMProvider
+		}
+		if provider == "" {
+			if prefix, _, ok := strings.Cut(deployment.LiteLLMParams.Model, "/"); ok {
+				provider = prefix
+			}
+		}
+
+		key := [2]string{deployment.ModelName, provider}
+		llm, ok := byKey[key]
+		if !ok {
+			llm = &models.LiteLLM{Model: deployment.ModelName, Provider: provider}
+			byKey[key] = llm
+		}
+
+		tpm := firstLimit(deployment.LiteLLMParams.TPM, deployment.ModelInfo.TPM)
+		rpm := firstLimit(deployment.LiteLLMParams.RPM, deployment.ModelInfo.RPM)
+		unlimitedTPM[key] = unlimitedTPM[key] || tpm == 0
+		unlimitedRPM[key] = unlimitedRPM[key] || rpm == 0
+		llm.TPMMax += tpm
+		llm.RPMMax += rpm
+		if llm.PaymentType == "" {
+			switch {
+			case deployment.ModelInfo.PaymentType != "":
+				llm.PaymentType = deployment.ModelInfo.PaymentType
+			case deployment.ModelInfo.InputCostPerToken > 0 || deployment.ModelInfo.OutputCostPerToken > 0:
+				llm.PaymentType = "pay-per-token"
+			case deployment.ModelInfo.InputCostPerSecond > 0 || deployment.ModelInfo.OutputCostPerSecond > 0:
+				llm.PaymentType = "pay-per-second"
+			}
+		}
+	}
+
+	if len(byKey) == 0 {
+		return []models.LiteLLM{}, nil
+	}
+	for key, llm := range byKey {
+		if unlimitedTPM[key] {
+			llm.TPMMax = 0
+		}
+		if unlimitedRPM[key] {
+			llm.RPMMax = 0
+		}
+	}
+	if err := r.addUsage(ctx, byKey); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.LiteLLM, 0, len(byKey))
+	for _, llm := range byKey {
+		result = append(result, *llm)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Model != result[j].Model {
+			return result[i].Model < result[j].Model
+		}
+		return result[i].Provider < result[j].Provider
+	})
+	return result, nil
+}
+
+// addUsage counts the tokens and requests logged in the last minute per
+// model group and provider.
+func (r *LiteLLMProxyRepository) addUsage(ctx context.Context, byKey map[[2]string]*models.LiteLLM) error {
+	end := r.now().UTC()
+	start := end.Add(-litellmUsageWindow)
+	query := url.Values{
+		"start_date": {start.Format(time.DateTime)},
+		"end_date":   {end.Format(time.DateTime)},
+		"page_size":  {strconv.Itoa(litellmPageSize)},
+	}
+
+	for page := 1; page <= litellmMaxPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		var logs litellmSpendLogsResponse
+		if err := r.get(ctx, "/spend/logs/v2", query, &logs); err != nil {
+			return fmt.Errorf("get spend logs: %w", err)
+		}
+
+		for _, entry := range logs.Data {
+			startedAt, ok := parseLiteLLMTime(entry.StartTime)
+			if !ok || startedAt.Before(start) || startedAt.After(end) {
+				continue
+			}
+			group := entry.ModelGroup
+			if group == "" {
+				group = entry.Model
+			}
+			if llm, ok := byKey[[2]string{group, entry.CustomLLMProvider}]; ok {
+				llm.TPM += entry.TotalTokens
+				llm.RPM++
+			}
+		}
+
+		if page >= logs.TotalPages || len(logs.Data) == 0 {
+			return nil
+		}
+		if page == litellmMaxPages {
+			logger.Log.Warn("Too many spend logs in the usage window, TPM and RPM are undercounted",
+				zap.Int("pages_read", page), zap.Int("total_pages", logs.TotalPages))
+		}
+	}
+	return nil
+}
+
+func (r *LiteLLMProxyRepository) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	endpoint := r.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s responded %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func firstLimit(limits ...*int) int {
+	for _, limit := range limits {
+		if limit != nil {
+			return *limit
+		}
+	}
+	return 0
+}
+
+// parseLiteLLMTime accepts the timestamp layouts the proxy emits, which
+// omit the zone when the database column has none; those are UTC.
+func parseLiteLLMTime(raw string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
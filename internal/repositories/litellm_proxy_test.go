@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"telemetron/pkg/config"
+)
+
+const fakeModelInfo = `{"data": [
+	{"model_name": "gpt-4", "litellm_params": {"model": "openai/gpt-4", "tpm": 90000, "rpm": 3500},
+	 "model_info": {"litellm_provider": "openai", "input_cost_per_token": 0.00003, "output_cost_per_token": 0.00006}},
+	{"model_name": "gpt-4", "litellm_params": {"model": "azure/gpt-4-eu", "tpm": 40000},
+	 "model_info": {"litellm_provider": "azure", "rpm": 600, "payment_type": "provisioned"}},
+	{"model_name": "gpt-4", "litellm_params": {"model": "azure/gpt-4-us", "tpm": 60000},
+	 "model_info": {"litellm_provider": "azure", "rpm": 900}},
+	{"model_name": "llama-3", "litellm_params": {"model": "ollama/llama3"}, "model_info": {}},
+	{"model_name": "mistral-large", "litellm_params": {"model": "mistral/mistral-large-latest", "tpm": 50000},
+	 "model_info": {"litellm_provider": "mistral"}},
+	{"model_name": "mistral-large", "litellm_params": {"model": "mistral/mistral-large-2411", "rpm": 300},
+	 "model_info": {"litellm_provider": "mistral"}}
+]}`
+
+// fakeLiteLLM serves /model/info and a paginated /spend/logs/v2 the way a
+// LiteLLM proxy does.
+func fakeLiteLLM(t *testing.T, now time.Time) *httptest.Server {
+	logs := []map[string]interface{}{
+		{"model": "gpt-4", "model_group": "gpt-4", "custom_llm_provider": "openai", "total_tokens": 1200, "startTime": now.Add(-10 * time.Second).Format(time.RFC3339Nano)},
+		{"model": "gpt-4", "model_group": "gpt-4", "custom_llm_provider": "openai", "total_tokens": 800, "startTime": now.Add(-50 * time.Second).UTC().Format("2006-01-02T15:04:05.000000")},
+		{"model": "azure/gpt-4-eu", "model_group": "gpt-4", "custom_llm_provider": "azure", "total_tokens": 500, "startTime": now.Add(-5 * time.Second).Format(time.RFC3339)},
+		{"model": "gpt-4", "model_group": "gpt-4", "custom_llm_provider": "openai", "total_tokens": 9999, "startTime": now.Add(-2 * time.Minute).Format(time.RFC3339)},
+		{"model": "unknown", "model_group": "unknown", "custom_llm_provider": "openai", "total_tokens": 1, "startTime": now.Format(time.RFC3339)},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-master" {
+			http.Error(w, `{"error": "Authentication Error"}`, http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/model/info":
+			w.Write([]byte(fakeModelInfo))
+		case "/spend/logs/v2":
+			query := r.URL.Query()
+			if _, err := time.Parse(time.DateTime, query.Get("start_date")); err != nil {
+				t.Errorf("Unexpected start_date %q", query.Get("start_date"))
+			}
+			// Serve two entries per page regardless of page_size to
+			// exercise pagination.
+			page, _ := strconv.Atoi(query.Get("page"))
+			from, to := (page-1)*2, page*2
+			if to > len(logs) {
+				to = len(logs)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":        logs[from:to],
+				"page":        page,
+				"total_pages": (len(logs) + 1) / 2,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestLiteLLMProxyRepository_GetAll(t *testing.T) {
+	now := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	server := fakeLiteLLM(t, now)
+	defer server.Close()
+
+	repo := NewLiteLLMProxyRepository(server.URL+"/", "sk-master", server.Client())
+	repo.now = func() time.Time { return now }
+
+	llms, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(llms) != 4 {
+		t.Fatalf("Expected 4 model/provider pairs, got %+v", llms)
+	}
+
+	azure, openai, llama, mistral := llms[0], llms[1], llms[2], llms[3]
+	if azure.Model != "gpt-4" || azure.Provider != "azure" {
+		t.Fatalf("Expected gpt-4/azure first, got %+v", azure)
+	}
+	if azure.TPMMax != 100000 || azure.RPMMax != 1500 || azure.PaymentType != "provisioned" {
+		t.Errorf("Expected azure deployments to be aggregated, got %+v", azure)
+	}
+	if azure.TPM != 500 || azure.RPM != 1 {
+		t.Errorf("Unexpected azure usage %+v", azure)
+	}
+
+	if openai.TPMMax != 90000 || openai.RPMMax != 3500 || openai.PaymentType != "pay-per-token" {
+		t.Errorf("Unexpected openai limits %+v", openai)
+	}
+	if openai.TPM != 2000 || openai.RPM != 2 {
+		t.Errorf("Expected only the last minute of openai usage, got %+v", openai)
+	}
+
+	if llama.Model != "llama-3" || llama.Provider != "ollama" || llama.TPMMax != 0 || llama.PaymentType != "" {
+		t.Errorf("Unexpected llama entry %+v", llama)
+	}
+
+	// One mistral deployment has no TPM limit and the other no RPM limit,
+	// so the group is unlimited in both.
+	if mistral.Model != "mistral-large" || mistral.TPMMax != 0 || mistral.RPMMax != 0 {
+		t.Errorf("Expected a partly unlimited group to be unlimited, got %+v", mistral)
+	}
+}
+
+func TestLiteLLMProxyRepository_Get(t *testing.T) {
+	now := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	server := fakeLiteLLM(t, now)
+	defer server.Close()
+
+	repo := NewLiteLLMProxyRepository(server.URL, "sk-master", server.Client())
+	repo.now = func() time.Time { return now }
+
+	entries, err := repo.Get(context.Background(), "gpt-4")
+	if err != nil || len(entries) != 2 || entries[1].Provider != "openai" || entries[1].TPM != 2000 {
+		t.Errorf("Expected gpt-4's providers with their usage, got %+v, %v", entries, err)
+	}
+	if _, err := repo.Get(context.Background(), "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a model that is not configured, got %v", err)
+	}
+}
+
+func TestLiteLLMProxyRepository_Unauthorized(t *testing.T) {
+	server := fakeLiteLLM(t, time.Now())
+	defer server.Close()
+
+	_, err := NewLiteLLMProxyRepository(server.URL, "sk-wrong", nil).GetAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+}
+
+func TestRegistryBuild_LiteLLMProxy(t *testing.T) {
+	registry := NewRegistry()
+
+	if _, err := registry.Build(&config.Config{EnableMockData: true, LiteLLMBackend: "proxy"}); err == nil {
+		t.Error("Expected an error without LITELLM_URL")
+	}
+
+	repos, err := registry.Build(&config.Config{EnableMockData: true, LiteLLMBackend: "proxy", LiteLLMURL: "http://litellm:4000"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer repos.Close()
+	if _, ok := repos.LiteLLM.(*LiteLLMProxyRepository); !ok {
+		t.Errorf("Expected LiteLLM proxy repository, got %T", repos.LiteLLM)
+	}
+}
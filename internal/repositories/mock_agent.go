@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -10,12 +12,15 @@ import (
 type MockAgentRepository struct {
 	mu     sync.RWMutex
 	agents []models.Agent
+	tasks  int
 	stop   chan struct{}
 }
 
 func NewMockAgentRepository() *MockAgentRepository {
+	now := time.Now().UTC()
 	repo := &MockAgentRepository{
-		stop: make(chan struct{}),
+		tasks: 3,
+		stop:  make(chan struct{}),
 		agents: []models.Agent{
 			{
 				Name:                   "agent-1",
@@ -25,10 +30,11 @@ func NewMockAgentRepository() *MockAgentRepository {
 				Models:                 []string{"gpt-4", "gpt-3.5-turbo"},
 				Activity: models.Activity{
 					ActiveTaskIDs: []models.TaskStatus{
-						{ID: "task-1", Status: "running"},
-						{ID: "task-2", Status: "pending"},
+						{ID: "task-1", Status: models.TaskRunning, StartedAt: now.Add(-time.Minute), Model: "gpt-4",
+							Queue: "default", QueueTaskID: "task-1"},
+						{ID: "task-2", Status: models.TaskPending, Queue: "default", QueueTaskID: "task-2"},
 					},
-					UpdatedAt: time.Now().Format(time.RFC3339),
+					UpdatedAt: now,
 				},
 			},
 			{
@@ -39,9 +45,9 @@ func NewMockAgentRepository() *MockAgentRepository {
 				Models:                 []string{"gpt-4"},
 				Activity: models.Activity{
 					ActiveTaskIDs: []models.TaskStatus{
-						{ID: "task-3", Status: "running"},
+						{ID: "task-3", Status: models.TaskRunning, StartedAt: now.Add(-30 * time.Second), Model: "gpt-4"},
 					},
-					UpdatedAt: time.Now().Format(time.RFC3339),
+					UpdatedAt: now,
 				},
 			},
 		},
@@ -51,44 +57,54 @@ func NewMockAgentRepository() *MockAgentRepository {
 	return repo
 }
 
-func (r *MockAgentRepository) GetAll() ([]models.Agent, error) {
+func (r *MockAgentRepository) GetAll(ctx context.Context) ([]models.Agent, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	agents := make([]models.Agent, len(r.agents))
-	copy(agents, r.agents)
+	for i, agent := range r.agents {
+		agents[i] = cloneAgent(agent)
+	}
 	return agents, nil
 }
 
-func (r *MockAgentRepository) GetByName(name string) (*models.Agent, bool) {
+func (r *MockAgentRepository) Get(ctx context.Context, name string) (*models.Agent, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	for _, agent := range r.agents {
 		if agent.Name == name {
-			agentCopy := agent
-			return &agentCopy, true
+			agentCopy := cloneAgent(agent)
+			return &agentCopy, nil
 		}
 	}
-	return nil, false
+	return nil, ErrNotFound
+}
+
+// cloneAgent copies an agent with its own models and tasks, which
+// simulateActivity updates in place.
+func cloneAgent(agent models.Agent) models.Agent {
+	agent.Models = append([]string(nil), agent.Models...)
+	agent.Activity.ActiveTaskIDs = append([]models.TaskStatus(nil), agent.Activity.ActiveTaskIDs...)
+	return agent
 }
 
 func (r *MockAgentRepository) simulateActivity() {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
-	statuses := []string{"running", "pending", "completed", "failed"}
-
 	for {
 		select {
 		case <-ticker.C:
 			r.mu.Lock()
+			now := time.Now().UTC()
 			for i := range r.agents {
-				// Simulate task status changes
-				for j := range r.agents[i].Activity.ActiveTaskIDs {
-					r.agents[i].Activity.ActiveTaskIDs[j].Status = statuses[time.Now().Unix()%int64(len(statuses))]
+				// Move every task one step through its lifecycle
+				tasks := r.agents[i].Activity.ActiveTaskIDs
+				for j := range tasks {
+					r.advance(&tasks[j], now)
 				}
-				r.agents[i].Activity.UpdatedAt = time.Now().Format(time.RFC3339)
+				r.agents[i].Activity.UpdatedAt = now
 			}
 			r.mu.Unlock()
 		case <-r.stop:
@@ -97,6 +113,30 @@ func (r *MockAgentRepository) simulateActivity() {
 	}
 }
 
+// advance moves a pending task to running, a running one to completed or,
+// every third time, failed, and replaces a finished one with a new pending
+// task.
+func (r *MockAgentRepository) advance(task *models.TaskStatus, now time.Time) {
+	switch task.Status {
+	case models.TaskPending:
+		task.Status = models.TaskRunning
+		task.StartedAt = now
+		task.Model = "gpt-4"
+	case models.TaskRunning:
+		task.Status = models.TaskCompleted
+		if now.Unix()%3 == 0 {
+			task.Status = models.TaskFailed
+			task.Error = "model request timed out"
+		}
+		task.FinishedAt = now
+		task.Duration = models.Duration(now.Sub(task.StartedAt))
+		task.InputTokens, task.OutputTokens = 1200, 300
+	default:
+		r.tasks++
+		*task = models.TaskStatus{ID: fmt.Sprintf("task-%d", r.tasks), Status: models.TaskPending}
+	}
+}
+
 func (r *MockAgentRepository) Close() {
 	close(r.stop)
 }
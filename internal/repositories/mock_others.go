@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -19,11 +20,11 @@ func NewMockWorkloadRepository() *MockWorkloadRepository {
 			{
 				DeploymentName: "agent-deployment-1",
 				MaxPods:        10,
-				PodMaxRAM:      "2Gi",
-				PodMaxCPU:      "1000m",
+				PodMaxRAM:      models.MustParseQuantity("2Gi"),
+				PodMaxCPU:      models.MustParseQuantity("1000m"),
 				Live: models.LiveWorkload{
 					ActivePods: 3,
-					UpdatedAt:  time.Now().Format(time.RFC3339),
+					UpdatedAt:  time.Now().UTC(),
 				},
 				Pods: []models.Pod{
 					{PodID: "pod-1", CPU: 0.5, Memory: 1024, Status: "running"},
@@ -35,7 +36,7 @@ func NewMockWorkloadRepository() *MockWorkloadRepository {
 	}
 }
 
-func (r *MockWorkloadRepository) GetAll() ([]models.Workload, error) {
+func (r *MockWorkloadRepository) GetAll(ctx context.Context) ([]models.Workload, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -44,6 +45,18 @@ func (r *MockWorkloadRepository) GetAll() ([]models.Workload, error) {
 	return workloads, nil
 }
 
+func (r *MockWorkloadRepository) Get(ctx context.Context, deployment string) (*models.Workload, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found, err := find(r.workloads, deployment, workloadDeployment)
+	if err != nil {
+		return nil, err
+	}
+	workload := *found
+	return &workload, nil
+}
+
 func (r *MockWorkloadRepository) Close() {}
 
 // MockQueueRepository implementation
@@ -57,24 +70,24 @@ func NewMockQueueRepository() *MockQueueRepository {
 		queues: []models.Queue{
 			{
 				Name:      "default",
-				UpdatedAt: time.Now().Format(time.RFC3339),
+				UpdatedAt: time.Now().UTC(),
 				Tasks: []models.QueueTask{
-					{ID: "task-1", Priority: models.Priority{Level: "high"}, SubmittedAt: time.Now().Add(-5 * time.Minute).Format(time.RFC3339)},
-					{ID: "task-2", Priority: models.Priority{Level: "medium"}, SubmittedAt: time.Now().Add(-2 * time.Minute).Format(time.RFC3339)},
+					{ID: "task-1", Priority: models.Priority{Level: "high"}, SubmittedAt: time.Now().Add(-5 * time.Minute).UTC()},
+					{ID: "task-2", Priority: models.Priority{Level: "medium"}, SubmittedAt: time.Now().Add(-2 * time.Minute).UTC()},
 				},
 			},
 			{
 				Name:      "priority",
-				UpdatedAt: time.Now().Format(time.RFC3339),
+				UpdatedAt: time.Now().UTC(),
 				Tasks: []models.QueueTask{
-					{ID: "task-3", Priority: models.Priority{Level: "high"}, SubmittedAt: time.Now().Add(-1 * time.Minute).Format(time.RFC3339)},
+					{ID: "task-3", Priority: models.Priority{Level: "high"}, SubmittedAt: time.Now().Add(-1 * time.Minute).UTC()},
 				},
 			},
 		},
 	}
 }
 
-func (r *MockQueueRepository) GetAll() ([]models.Queue, error) {
+func (r *MockQueueRepository) GetAll(ctx context.Context) ([]models.Queue, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -83,6 +96,18 @@ func (r *MockQueueRepository) GetAll() ([]models.Queue, error) {
 	return queues, nil
 }
 
+func (r *MockQueueRepository) Get(ctx context.Context, name string) (*models.Queue, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found, err := find(r.queues, name, queueName)
+	if err != nil {
+		return nil, err
+	}
+	queue := *found
+	return &queue, nil
+}
+
 func (r *MockQueueRepository) Close() {}
 
 // MockLiteLLMRepository implementation
@@ -101,7 +126,7 @@ func NewMockLiteLLMRepository() *MockLiteLLMRepository {
 	}
 }
 
-func (r *MockLiteLLMRepository) GetAll() ([]models.LiteLLM, error) {
+func (r *MockLiteLLMRepository) GetAll(ctx context.Context) ([]models.LiteLLM, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -110,4 +135,11 @@ func (r *MockLiteLLMRepository) GetAll() ([]models.LiteLLM, error) {
 	return litellm, nil
 }
 
+func (r *MockLiteLLMRepository) Get(ctx context.Context, model string) ([]models.LiteLLM, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return filterModel(r.litellm, model)
+}
+
 func (r *MockLiteLLMRepository) Close() {}
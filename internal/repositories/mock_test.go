@@ -1,13 +1,15 @@
 package repositories
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
 func TestMockAgentRepository(t *testing.T) {
 	repo := NewMockAgentRepository()
 
-	agents, err := repo.GetAll()
+	agents, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -26,15 +28,23 @@ func TestMockAgentRepository(t *testing.T) {
 		t.Error("Expected deployment name, got empty string")
 	}
 
-	if agent.Activity.UpdatedAt == "" {
+	if agent.Activity.UpdatedAt.IsZero() {
 		t.Error("Expected activity updated timestamp")
 	}
+
+	// Callers get their own tasks, which the simulation must not change
+	// under them and they must not change under the simulation.
+	agent.Activity.ActiveTaskIDs[0].Status = "changed"
+	again, _ := repo.GetAll(context.Background())
+	if again[0].Activity.ActiveTaskIDs[0].Status == "changed" {
+		t.Error("Expected GetAll to copy the agents' tasks")
+	}
 }
 
 func TestMockWorkloadRepository(t *testing.T) {
 	repo := NewMockWorkloadRepository()
 
-	workloads, err := repo.GetAll()
+	workloads, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -56,7 +66,7 @@ func TestMockWorkloadRepository(t *testing.T) {
 
 func TestMockQueueRepository(t *testing.T) {
 	repo := NewMockQueueRepository()
-	queues, err := repo.GetAll()
+	queues, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -72,7 +82,7 @@ func TestMockQueueRepository(t *testing.T) {
 
 func TestMockLiteLLMRepository(t *testing.T) {
 	repo := NewMockLiteLLMRepository()
-	llms, err := repo.GetAll()
+	llms, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -85,3 +95,31 @@ func TestMockLiteLLMRepository(t *testing.T) {
 		t.Error("Expected model name")
 	}
 }
+
+func TestMockRepositories_Get(t *testing.T) {
+	ctx := context.Background()
+
+	if agent, err := NewMockAgentRepository().Get(ctx, "agent-1"); err != nil || agent.Name != "agent-1" {
+		t.Errorf("Expected agent-1, got %+v, %v", agent, err)
+	}
+	if workload, err := NewMockWorkloadRepository().Get(ctx, "agent-deployment-1"); err != nil || len(workload.Pods) != 3 {
+		t.Errorf("Expected agent-deployment-1 with its pods, got %+v, %v", workload, err)
+	}
+	if queue, err := NewMockQueueRepository().Get(ctx, "priority"); err != nil || queue.Name != "priority" {
+		t.Errorf("Expected the priority queue, got %+v, %v", queue, err)
+	}
+	if entries, err := NewMockLiteLLMRepository().Get(ctx, "gpt-4"); err != nil || len(entries) != 1 || entries[0].Provider != "openai" {
+		t.Errorf("Expected gpt-4 via openai, got %+v, %v", entries, err)
+	}
+
+	for name, get := range map[string]func() error{
+		"agent":    func() error { _, err := NewMockAgentRepository().Get(ctx, "missing"); return err },
+		"workload": func() error { _, err := NewMockWorkloadRepository().Get(ctx, "missing"); return err },
+		"queue":    func() error { _, err := NewMockQueueRepository().Get(ctx, "missing"); return err },
+		"model":    func() error { _, err := NewMockLiteLLMRepository().Get(ctx, "missing"); return err },
+	} {
+		if err := get(); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a missing %s, got %v", name, err)
+		}
+	}
+}
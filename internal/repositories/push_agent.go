@@ -0,0 +1,369 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+var (
+	// ErrInvalidTask is returned for task reports without an ID or with an
+	// unknown status.
+	ErrInvalidTask = errors.New("invalid task")
+	// ErrTaskFinished is returned when a completed, failed or cancelled
+	// task is reported with a different status.
+	ErrTaskFinished = errors.New("task already finished")
+	// ErrInvalidTransition is returned when a task is reported in a state
+	// its current one cannot move to, such as running back to pending.
+	ErrInvalidTransition = errors.New("invalid task transition")
+)
+
+// PushAgentRepository holds the agents that report themselves through
+// heartbeats and task reports. Agents register on first contact and are
+// reported stale once no heartbeat arrived for staleAfter. Finished tasks
+// stay listed for taskRetention so watchers see their final status.
+//
+// With a path, the agents are saved to that JSON file after every change
+// and loaded from it on start.
+type PushAgentRepository struct {
+	mu            sync.RWMutex
+	agents        map[string]*pushedAgent
+	path          string
+	staleAfter    time.Duration
+	taskRetention time.Duration
+	now           func() time.Time
+}
+
+type pushedAgent struct {
+	Agent         models.Agent         `json:"agent"`
+	LastHeartbeat time.Time            `json:"last_heartbeat"`
+	FinishedAt    map[string]time.Time `json:"finished_at,omitempty"`
+}
+
+// NewPushAgentRepository loads previously saved agents from path, if set
+// and present.
+func NewPushAgentRepository(path string, staleAfter, taskRetention time.Duration) (*PushAgentRepository, error) {
+	r := &PushAgentRepository{
+		agents:        make(map[string]*pushedAgent),
+		path:          path,
+		staleAfter:    staleAfter,
+		taskRetention: taskRetention,
+		now:           time.Now,
+	}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read agent store: %w", err)
+	}
+	var saved []*pushedAgent
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("decode agent store %s: %w", path, err)
+	}
+	for _, agent := range saved {
+		r.agents[agent.Agent.Name] = agent
+	}
+	return r, nil
+}
+
+func (r *PushAgentRepository) GetAll(ctx context.Context) ([]models.Agent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	agents := make([]models.Agent, 0, len(r.agents))
+	for _, pushed := range r.agents {
+		agents = append(agents, r.viewLocked(pushed, now))
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	return agents, nil
+}
+
+func (r *PushAgentRepository) Get(ctx context.Context, name string) (*models.Agent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pushed, ok := r.agents[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	agent := r.viewLocked(pushed, r.now())
+	return &agent, nil
+}
+
+// viewLocked returns a copy of a pushed agent as it is served: without
+// tasks past their retention and marked stale without recent heartbeats.
+func (r *PushAgentRepository) viewLocked(pushed *pushedAgent, now time.Time) models.Agent {
+	agent := pushed.Agent
+	agent.Models = append([]string(nil), agent.Models...)
+	agent.Activity.ActiveTaskIDs = pushed.currentTasks(now, r.taskRetention)
+	agent.Activity.Stale = r.staleAfter > 0 && now.Sub(pushed.LastHeartbeat) > r.staleAfter
+	return agent
+}
+
+func (r *PushAgentRepository) Close() {}
+
+// Heartbeat records that the named agent is alive, registering it or
+// updating the details it reports.
+func (r *PushAgentRepository) Heartbeat(name string, heartbeat models.AgentHeartbeat) (models.Agent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateLocked(name, func(pushed *pushedAgent) error {
+		now := r.now()
+		agent := &pushed.Agent
+		if heartbeat.Description != "" {
+			agent.Description = heartbeat.Description
+		}
+		if heartbeat.MaxParallelInvocations > 0 {
+			agent.MaxParallelInvocations = heartbeat.MaxParallelInvocations
+		}
+		if heartbeat.DeploymentName != "" {
+			agent.DeploymentName = heartbeat.DeploymentName
+		}
+		if heartbeat.Models != nil {
+			agent.Models = append([]string(nil), heartbeat.Models...)
+		}
+		pushed.LastHeartbeat = now
+		agent.Activity.LastHeartbeat = now.UTC()
+		agent.Activity.UpdatedAt = agent.Activity.LastHeartbeat
+		pushed.prune(now, r.taskRetention)
+		return nil
+	})
+}
+
+// ReportTasks applies the reported tasks to the named agent, registering
+// it if needed. A report counts as a heartbeat. Nothing is applied if any
+// task is invalid, reported twice, or makes a transition its lifecycle does
+// not allow.
+func (r *PushAgentRepository) ReportTasks(name string, tasks []models.TaskStatus) (models.Agent, error) {
+	seen := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if task.ID == "" {
+			return models.Agent{}, fmt.Errorf("%w: missing id", ErrInvalidTask)
+		}
+		if !task.Status.Valid() {
+			return models.Agent{}, fmt.Errorf("%w: %s has unknown status %q", ErrInvalidTask, task.ID, task.Status)
+		}
+		if seen[task.ID] {
+			return models.Agent{}, fmt.Errorf("%w: %s is reported more than once", ErrInvalidTask, task.ID)
+		}
+		seen[task.ID] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateLocked(name, func(pushed *pushedAgent) error {
+		for _, task := range tasks {
+			current, ok := pushed.find(task.ID)
+			if !ok || current.Status.CanTransition(task.Status) {
+				continue
+			}
+			if current.Status.Final() {
+				return fmt.Errorf("%w: %s is %s", ErrTaskFinished, task.ID, current.Status)
+			}
+			return fmt.Errorf("%w: %s cannot go from %s to %s", ErrInvalidTransition, task.ID, current.Status, task.Status)
+		}
+
+		now := r.now()
+		activity := &pushed.Agent.Activity
+		for _, report := range tasks {
+			i, ok := pushed.index(report.ID)
+			if !ok {
+				activity.ActiveTaskIDs = append(activity.ActiveTaskIDs, models.TaskStatus{ID: report.ID})
+				i = len(activity.ActiveTaskIDs) - 1
+			}
+			task := &activity.ActiveTaskIDs[i]
+			if !applyReport(task, report, now) || !task.Status.Final() {
+				continue
+			}
+			if pushed.FinishedAt == nil {
+				pushed.FinishedAt = make(map[string]time.Time)
+			}
+			pushed.FinishedAt[task.ID] = now
+		}
+		pushed.LastHeartbeat = now
+		activity.LastHeartbeat = now.UTC()
+		activity.UpdatedAt = activity.LastHeartbeat
+		pushed.prune(now, r.taskRetention)
+		return nil
+	})
+}
+
+// updateLocked applies change to a copy of the named agent, registering it
+// if needed, and keeps the copy only once it is saved, so that a rejected
+// change or a failed save leaves the agents as they were.
+func (r *PushAgentRepository) updateLocked(name string, change func(*pushedAgent) error) (models.Agent, error) {
+	previous, existed := r.agents[name]
+	var pushed *pushedAgent
+	if existed {
+		pushed = previous.clone()
+	} else {
+		pushed = &pushedAgent{Agent: models.Agent{
+			Name:     name,
+			Models:   []string{},
+			Activity: models.Activity{ActiveTaskIDs: []models.TaskStatus{}},
+		}}
+	}
+	if err := change(pushed); err != nil {
+		return models.Agent{}, err
+	}
+
+	r.agents[name] = pushed
+	agent, err := r.saveLocked(pushed)
+	if err != nil {
+		if existed {
+			r.agents[name] = previous
+		} else {
+			delete(r.agents, name)
+		}
+		return models.Agent{}, err
+	}
+	return agent, nil
+}
+
+// saveLocked writes every agent to the store file, replacing it
+// atomically, and returns the current view of the changed one.
+func (r *PushAgentRepository) saveLocked(changed *pushedAgent) (models.Agent, error) {
+	agent := changed.Agent
+	agent.Activity.ActiveTaskIDs = append([]models.TaskStatus{}, agent.Activity.ActiveTaskIDs...)
+	if r.path == "" {
+		return agent, nil
+	}
+
+	saved := make([]*pushedAgent, 0, len(r.agents))
+	for _, pushed := range r.agents {
+		saved = append(saved, pushed)
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Agent.Name < saved[j].Agent.Name })
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return models.Agent{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return models.Agent{}, fmt.Errorf("save agent store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return models.Agent{}, fmt.Errorf("save agent store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return models.Agent{}, fmt.Errorf("save agent store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return models.Agent{}, fmt.Errorf("save agent store: %w", err)
+	}
+	return agent, nil
+}
+
+func (a *pushedAgent) clone() *pushedAgent {
+	c := *a
+	c.Agent.Models = append([]string{}, a.Agent.Models...)
+	c.Agent.Activity.ActiveTaskIDs = append([]models.TaskStatus{}, a.Agent.Activity.ActiveTaskIDs...)
+	if a.FinishedAt != nil {
+		c.FinishedAt = make(map[string]time.Time, len(a.FinishedAt))
+		for id, finishedAt := range a.FinishedAt {
+			c.FinishedAt[id] = finishedAt
+		}
+	}
+	return &c
+}
+
+func (a *pushedAgent) index(id string) (int, bool) {
+	for i, task := range a.Agent.Activity.ActiveTaskIDs {
+		if task.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (a *pushedAgent) find(id string) (models.TaskStatus, bool) {
+	if i, ok := a.index(id); ok {
+		return a.Agent.Activity.ActiveTaskIDs[i], true
+	}
+	return models.TaskStatus{}, false
+}
+
+// currentTasks returns a copy of the tasks without those that finished
+// more than retention ago.
+func (a *pushedAgent) currentTasks(now time.Time, retention time.Duration) []models.TaskStatus {
+	tasks := make([]models.TaskStatus, 0, len(a.Agent.Activity.ActiveTaskIDs))
+	for _, task := range a.Agent.Activity.ActiveTaskIDs {
+		if finishedAt, ok := a.FinishedAt[task.ID]; ok && now.Sub(finishedAt) > retention {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func (a *pushedAgent) prune(now time.Time, retention time.Duration) {
+	a.Agent.Activity.ActiveTaskIDs = a.currentTasks(now, retention)
+	for id, finishedAt := range a.FinishedAt {
+		if now.Sub(finishedAt) > retention {
+			delete(a.FinishedAt, id)
+		}
+	}
+}
+
+// applyReport merges a reported task into the stored one and reports
+// whether its status changed. Details the report leaves empty keep their
+// values. Entering running stamps StartedAt and entering a final state
+// FinishedAt, unless the report carries them.
+func applyReport(task *models.TaskStatus, report models.TaskStatus, now time.Time) bool {
+	if !report.StartedAt.IsZero() {
+		task.StartedAt = report.StartedAt.UTC()
+	}
+	if !report.FinishedAt.IsZero() {
+		task.FinishedAt = report.FinishedAt.UTC()
+	}
+	if report.Error != "" {
+		task.Error = report.Error
+	}
+	if report.Model != "" {
+		task.Model = report.Model
+	}
+	if report.InputTokens > 0 {
+		task.InputTokens = report.InputTokens
+	}
+	if report.OutputTokens > 0 {
+		task.OutputTokens = report.OutputTokens
+	}
+	if report.Queue != "" {
+		task.Queue = report.Queue
+	}
+	if report.QueueTaskID != "" {
+		task.QueueTaskID = report.QueueTaskID
+	}
+
+	changed := task.Status != report.Status
+	task.Status = report.Status
+	if task.Status == models.TaskRunning && task.StartedAt.IsZero() {
+		task.StartedAt = now.UTC()
+	}
+	if task.Status.Final() && task.FinishedAt.IsZero() {
+		task.FinishedAt = now.UTC()
+	}
+	if !task.StartedAt.IsZero() && !task.FinishedAt.IsZero() {
+		task.Duration = models.Duration(task.FinishedAt.Sub(task.StartedAt))
+	}
+	return changed
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/config"
+)
+
+func TestPushAgentRepository_StaleAndRetention(t *testing.T) {
+	repo, err := NewPushAgentRepository("", time.Minute, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	if _, err := repo.Heartbeat("agent-1", models.AgentHeartbeat{DeploymentName: "agent-deployment-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-1", Status: "running"}, {ID: "task-2", Status: "running"}}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Second)
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-1", Status: "completed"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later heartbeat keeps the registered details.
+	if _, err := repo.Heartbeat("agent-1", models.AgentHeartbeat{}); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, _ := repo.GetAll(context.Background())
+	if len(agents) != 1 || agents[0].DeploymentName != "agent-deployment-1" || agents[0].Activity.Stale {
+		t.Fatalf("Unexpected agents %+v", agents)
+	}
+	if len(agents[0].Activity.ActiveTaskIDs) != 2 {
+		t.Errorf("Expected the completed task to stay listed, got %+v", agents[0].Activity.ActiveTaskIDs)
+	}
+
+	now = now.Add(6 * time.Minute)
+	agents, _ = repo.GetAll(context.Background())
+	if !agents[0].Activity.Stale {
+		t.Error("Expected the agent to be stale without heartbeats")
+	}
+	if tasks := agents[0].Activity.ActiveTaskIDs; len(tasks) != 1 || tasks[0].ID != "task-2" {
+		t.Errorf("Expected the completed task to be dropped after the retention, got %+v", tasks)
+	}
+
+	agent, err := repo.Get(context.Background(), "agent-1")
+	if err != nil || !agent.Activity.Stale || len(agent.Activity.ActiveTaskIDs) != 1 {
+		t.Errorf("Expected Get to serve the agent as GetAll does, got %+v, %v", agent, err)
+	}
+	if _, err := repo.Get(context.Background(), "agent-9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown agent, got %v", err)
+	}
+}
+
+func TestPushAgentRepository_RejectsInvalidReports(t *testing.T) {
+	repo, _ := NewPushAgentRepository("", time.Minute, time.Minute)
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-1", Status: "failed"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-2", Status: "running"}, {ID: "task-1", Status: "running"}})
+	if !errors.Is(err, ErrTaskFinished) {
+		t.Errorf("Expected ErrTaskFinished, got %v", err)
+	}
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-3", Status: "queued"}}); !errors.Is(err, ErrInvalidTask) {
+		t.Errorf("Expected ErrInvalidTask, got %v", err)
+	}
+	_, err = repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-4", Status: "completed"}, {ID: "task-4", Status: "running"}})
+	if !errors.Is(err, ErrInvalidTask) {
+		t.Errorf("Expected ErrInvalidTask for a task reported twice, got %v", err)
+	}
+
+	agents, _ := repo.GetAll(context.Background())
+	if tasks := agents[0].Activity.ActiveTaskIDs; len(tasks) != 1 || tasks[0].Status != "failed" {
+		t.Errorf("Expected rejected reports to change nothing, got %+v", tasks)
+	}
+}
+
+func TestPushAgentRepository_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	repo, err := NewPushAgentRepository(path, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Heartbeat("agent-1", models.AgentHeartbeat{Models: []string{"gpt-4"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-1", Status: "running"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewPushAgentRepository(path, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents, _ := reopened.GetAll(context.Background())
+	if len(agents) != 1 || agents[0].Models[0] != "gpt-4" || agents[0].Activity.ActiveTaskIDs[0].ID != "task-1" {
+		t.Errorf("Expected the saved agent to be loaded, got %+v", agents)
+	}
+}
+
+func TestPushAgentRepository_FailedSaveChangesNothing(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewPushAgentRepository(filepath.Join(dir, "agents.json"), time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-1", Status: "running"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.path = filepath.Join(dir, "missing", "agents.json")
+	if _, err := repo.ReportTasks("agent-1", []models.TaskStatus{{ID: "task-1", Status: "completed"}}); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+	if _, err := repo.Heartbeat("agent-2", models.AgentHeartbeat{}); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+
+	agents, _ := repo.GetAll(context.Background())
+	if len(agents) != 1 || agents[0].Activity.ActiveTaskIDs[0].Status != models.TaskRunning {
+		t.Errorf("Expected failed saves to change nothing, got %+v", agents)
+	}
+}
+
+func TestRegistryBuild_Push(t *testing.T) {
+	repos, err := NewRegistry().Build(&config.Config{EnableMockData: true, AgentBackend: "push", AgentStaleAfter: 60})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer repos.Close()
+	if _, ok := repos.Agents.(*PushAgentRepository); !ok {
+		t.Errorf("Expected push agent repository, got %T", repos.Agents)
+	}
+}
+
+func TestPushAgentRepository_TaskLifecycle(t *testing.T) {
+	repo, _ := NewPushAgentRepository("", time.Minute, time.Minute)
+	now := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	report := func(task models.TaskStatus) (models.TaskStatus, error) {
+		agent, err := repo.ReportTasks("agent-1", []models.TaskStatus{task})
+		if err != nil || len(agent.Activity.ActiveTaskIDs) == 0 {
+			return models.TaskStatus{}, err
+		}
+		return agent.Activity.ActiveTaskIDs[0], nil
+	}
+
+	if _, err := report(models.TaskStatus{ID: "task-1", Status: models.TaskPending, Queue: "default", QueueTaskID: "q-1"}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	task, err := report(models.TaskStatus{ID: "task-1", Status: models.TaskRunning, Model: "gpt-4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.StartedAt.Equal(now) || task.QueueTaskID != "q-1" || task.Model != "gpt-4" {
+		t.Errorf("Expected the start to be stamped and earlier details kept, got %+v", task)
+	}
+
+	if _, err := report(models.TaskStatus{ID: "task-1", Status: models.TaskPending}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+
+	finishedAt := now.Add(90 * time.Second)
+	now = now.Add(2 * time.Minute)
+	task, err = report(models.TaskStatus{ID: "task-1", Status: models.TaskFailed, FinishedAt: finishedAt,
+		Error: "model request timed out", InputTokens: 1200, OutputTokens: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.FinishedAt.Equal(finishedAt) || task.Duration != models.Duration(90*time.Second) ||
+		task.Error != "model request timed out" || task.InputTokens != 1200 || task.OutputTokens != 300 {
+		t.Errorf("Expected the reported finish, duration and details, got %+v", task)
+	}
+
+	if _, err := report(models.TaskStatus{ID: "task-1", Status: models.TaskCancelled}); !errors.Is(err, ErrTaskFinished) {
+		t.Errorf("Expected ErrTaskFinished, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+// RabbitMQQueueRepository lists the queues of one virtual host through the
+// RabbitMQ management HTTP API. The consumers of a queue are reported as a
+// single consumer group: Pending is the unacknowledged message count and
+// Lag the ready message count.
+//
+// When peek is positive, up to that many messages per queue are fetched to
+// fill in Tasks. The management API can only peek by requeueing, so peeked
+// messages are marked redelivered and may change position. Quorum queues
+// are never peeked: each requeue counts towards their delivery limit, past
+// which messages are dead-lettered or dropped.
+type RabbitMQQueueRepository struct {
+	baseURL  string
+	username string
+	password string
+	vhost    string
+	peek     int
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewRabbitMQQueueRepository queries the management API at baseURL, e.g.
+// "http://rabbitmq:15672". A nil client uses one with a 30 second timeout.
+func NewRabbitMQQueueRepository(baseURL, username, password, vhost string, peek int, client *http.Client) *RabbitMQQueueRepository {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &RabbitMQQueueRepository{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		vhost:    vhost,
+		peek:     peek,
+		client:   client,
+		now:      time.Now,
+	}
+}
+
+type rabbitmqQueue struct {
+	Name                   string `json:"name"`
+	MessagesReady          int64  `json:"messages_ready"`
+	MessagesUnacknowledged int64  `json:"messages_unacknowledged"`
+	Consumers              int    `json:"consumers"`
+	Type                   string `json:"type"`
+}
+
+type rabbitmqMessage struct {
+	Properties struct {
+		MessageID string                 `json:"message_id"`
+		Priority  *int                   `json:"priority"`
+		Timestamp int64                  `json:"timestamp"`
+		Headers   map[string]interface{} `json:"headers"`
+	} `json:"properties"`
+}
+
+// rabbitmqQueueColumns limits queue listings to the fields read.
+const rabbitmqQueueColumns = "name,type,messages_ready,messages_unacknowledged,consumers"
+
+func (r *RabbitMQQueueRepository) GetAll(ctx context.Context) ([]models.Queue, error) {
+	var listed []rabbitmqQueue
+	query := url.Values{"columns": {rabbitmqQueueColumns}}
+	if err := r.do(ctx, http.MethodGet, "/api/queues/"+url.PathEscape(r.vhost)+"?"+query.Encode(), nil, &listed); err != nil {
+		return nil, fmt.Errorf("list queues: %w", err)
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Name < listed[j].Name })
+
+	updatedAt := r.now().UTC()
+	queues := make([]models.Queue, 0, len(listed))
+	for _, q := range listed {
+		queue, err := r.buildQueue(ctx, q, updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, queue)
+	}
+	return queues, nil
+}
+
+func (r *RabbitMQQueueRepository) Get(ctx context.Context, name string) (*models.Queue, error) {
+	var q rabbitmqQueue
+	query := url.Values{"columns": {rabbitmqQueueColumns}}
+	path := "/api/queues/" + url.PathEscape(r.vhost) + "/" + url.PathEscape(name) + "?" + query.Encode()
+	if err := r.do(ctx, http.MethodGet, path, nil, &q); err != nil {
+		return nil, fmt.Errorf("get queue %s: %w", name, err)
+	}
+
+	queue, err := r.buildQueue(ctx, q, r.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+func (r *RabbitMQQueueRepository) buildQueue(ctx context.Context, q rabbitmqQueue, updatedAt time.Time) (models.Queue, error) {
+	queue := models.Queue{
+		Name:      q.Name,
+		UpdatedAt: updatedAt,
+		Tasks:     []models.QueueTask{},
+		ConsumerGroups: []models.ConsumerGroup{{
+			Name:      q.Name,
+			Consumers: q.Consumers,
+			Pending:   q.MessagesUnacknowledged,
+			Lag:       q.MessagesReady,
+		}},
+	}
+
+	if r.peek > 0 && q.MessagesReady > 0 && q.Type != "quorum" {
+		tasks, err := r.peekTasks(ctx, q.Name)
+		if err != nil {
+			return models.Queue{}, fmt.Errorf("peek queue %s: %w", q.Name, err)
+		}
+		queue.Tasks = tasks
+	}
+	return queue, nil
+}
+
+func (r *RabbitMQQueueRepository) Close() {}
+
+// peekTasks reads task IDs, priorities and submission times from message
+// properties, falling back to the task_id, priority and submitted_at
+// headers.
+func (r *RabbitMQQueueRepository) peekTasks(ctx context.Context, queue string) ([]models.QueueTask, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"count":    r.peek,
+		"ackmode":  "ack_requeue_true",
+		"encoding": "auto",
+		"truncate": 1,
+	})
+
+	var messages []rabbitmqMessage
+	path := "/api/queues/" + url.PathEscape(r.vhost) + "/" + url.PathEscape(queue) + "/get"
+	if err := r.do(ctx, http.MethodPost, path, body, &messages); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]models.QueueTask, 0, len(messages))
+	for _, message := range messages {
+		props := message.Properties
+		task := models.QueueTask{
+			ID:          props.MessageID,
+			Priority:    models.Priority{Level: fieldPriority(props.Headers["priority"])},
+			SubmittedAt: fieldTime(props.Headers["submitted_at"]),
+		}
+		if task.ID == "" {
+			task.ID = fieldString(props.Headers["task_id"])
+		}
+		if props.Priority != nil {
+			task.Priority.Level = strconv.Itoa(*props.Priority)
+		}
+		if props.Timestamp > 0 {
+			task.SubmittedAt = time.Unix(props.Timestamp, 0).UTC()
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (r *RabbitMQQueueRepository) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("%s %s responded %s: %s", method, req.URL.EscapedPath(), resp.Status, strings.TrimSpace(string(message)))
+		if resp.StatusCode == http.StatusNotFound {
+			// The management API answers 404 for unknown queues and vhosts.
+			err = fmt.Errorf("%w: %v", ErrNotFound, err)
+		}
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"telemetron/pkg/config"
+)
+
+// fakeRabbitMQ serves the management API endpoints the repository uses for
+// the default vhost, and counts peek requests.
+func fakeRabbitMQ(t *testing.T, peeks *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "guest" || pass != "guest" {
+			http.Error(w, `{"error":"not_authorised"}`, http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/queues/%2F":
+			w.Write([]byte(`[
+				{"name": "tasks.default", "type": "classic", "messages_ready": 2, "messages_unacknowledged": 1, "consumers": 3},
+				{"name": "tasks.idle", "type": "classic", "messages_ready": 0, "messages_unacknowledged": 0, "consumers": 0},
+				{"name": "tasks.quorum", "type": "quorum", "messages_ready": 4, "messages_unacknowledged": 0, "consumers": 1}
+			]`))
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/queues/%2F/tasks.default":
+			w.Write([]byte(`{"name": "tasks.default", "type": "classic", "messages_ready": 2, "messages_unacknowledged": 1, "consumers": 3}`))
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/queues/%2F/missing":
+			http.Error(w, `{"error":"Object Not Found","reason":"Not Found"}`, http.StatusNotFound)
+		case r.Method == http.MethodPost && r.URL.EscapedPath() == "/api/queues/%2F/tasks.default/get":
+			*peeks++
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["ackmode"] != "ack_requeue_true" || body["count"] != float64(5) {
+				t.Errorf("Unexpected peek request %v", body)
+			}
+			w.Write([]byte(`[
+				{"payload": "…", "properties": {"message_id": "task-1", "priority": 7, "timestamp": 1770372000}},
+				{"payload": "…", "properties": {"headers": {"task_id": "task-2", "priority": "high", "submitted_at": "2026-02-06T10:05:00Z"}}}
+			]`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestRabbitMQQueueRepository_GetAll(t *testing.T) {
+	peeks := 0
+	server := fakeRabbitMQ(t, &peeks)
+	defer server.Close()
+
+	queues, err := NewRabbitMQQueueRepository(server.URL, "guest", "guest", "/", 0, nil).GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(queues) != 3 || queues[0].Name != "tasks.default" {
+		t.Fatalf("Unexpected queues %+v", queues)
+	}
+
+	group := queues[0].ConsumerGroups[0]
+	if group.Consumers != 3 || group.Pending != 1 || group.Lag != 2 {
+		t.Errorf("Unexpected consumer counts %+v", group)
+	}
+	if len(queues[0].Tasks) != 0 || peeks != 0 {
+		t.Errorf("Expected no peeking by default, got %d tasks and %d peeks", len(queues[0].Tasks), peeks)
+	}
+}
+
+func TestRabbitMQQueueRepository_Peek(t *testing.T) {
+	peeks := 0
+	server := fakeRabbitMQ(t, &peeks)
+	defer server.Close()
+
+	queues, err := NewRabbitMQQueueRepository(server.URL+"/", "guest", "guest", "/", 5, nil).GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if peeks != 1 {
+		t.Errorf("Expected only the classic queue with ready messages to be peeked, got %d peeks", peeks)
+	}
+	if quorum := queues[2]; quorum.Name != "tasks.quorum" || len(quorum.Tasks) != 0 || quorum.ConsumerGroups[0].Lag != 4 {
+		t.Errorf("Expected the quorum queue to be listed without peeking, got %+v", quorum)
+	}
+
+	tasks := queues[0].Tasks
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 peeked tasks, got %+v", tasks)
+	}
+	if tasks[0].ID != "task-1" || tasks[0].Priority.Level != "7" || !tasks[0].SubmittedAt.Equal(time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected task from message properties, got %+v", tasks[0])
+	}
+	if tasks[1].ID != "task-2" || tasks[1].Priority.Level != "high" || !tasks[1].SubmittedAt.Equal(time.Date(2026, 2, 6, 10, 5, 0, 0, time.UTC)) {
+		t.Errorf("Expected task from headers, got %+v", tasks[1])
+	}
+}
+
+func TestRabbitMQQueueRepository_Get(t *testing.T) {
+	peeks := 0
+	server := fakeRabbitMQ(t, &peeks)
+	defer server.Close()
+
+	repo := NewRabbitMQQueueRepository(server.URL, "guest", "guest", "/", 5, nil)
+	queue, err := repo.Get(context.Background(), "tasks.default")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if queue.ConsumerGroups[0].Lag != 2 || len(queue.Tasks) != 2 || peeks != 1 {
+		t.Errorf("Expected the queue with its peeked tasks, got %+v", queue)
+	}
+	if _, err := repo.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown queue, got %v", err)
+	}
+}
+
+func TestRabbitMQQueueRepository_Unauthorized(t *testing.T) {
+	server := fakeRabbitMQ(t, new(int))
+	defer server.Close()
+
+	_, err := NewRabbitMQQueueRepository(server.URL, "guest", "wrong", "/", 0, nil).GetAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an authorization error, got %v", err)
+	}
+}
+
+func TestRegistryBuild_RabbitMQ(t *testing.T) {
+	registry := NewRegistry()
+	if _, err := registry.Build(&config.Config{EnableMockData: true, QueueBackend: "rabbitmq"}); err == nil {
+		t.Error("Expected an error without RABBITMQ_URL")
+	}
+
+	repos, err := registry.Build(&config.Config{EnableMockData: true, QueueBackend: "rabbitmq", RabbitMQURL: "http://rabbitmq:15672", RabbitMQVHost: "/"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer repos.Close()
+	if _, ok := repos.Queues.(*RabbitMQQueueRepository); !ok {
+		t.Errorf("Expected RabbitMQ queue repository, got %T", repos.Queues)
+	}
+}
@@ -0,0 +1,382 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"telemetron/internal/models"
+)
+
+// redisMaxTasks bounds how many tasks are read from each queue key.
+const redisMaxTasks = 1000
+
+// RedisQueueRepository discovers task queues among Redis keys matching a
+// pattern. Lists and sorted sets hold pending tasks directly; for streams
+// with consumer groups, the pending tasks are the entries some group has
+// not yet received or acknowledged.
+//
+// Members and stream entries may be plain task IDs or JSON objects with
+// "id", "priority" and "submitted_at" fields.
+type RedisQueueRepository struct {
+	client  redis.UniversalClient
+	pattern string
+	now     func() time.Time
+}
+
+// NewRedisQueueRepository scans client for keys matching pattern, e.g.
+// "queue:*". Queue names are the keys without the pattern's literal prefix.
+func NewRedisQueueRepository(client redis.UniversalClient, pattern string) *RedisQueueRepository {
+	return &RedisQueueRepository{client: client, pattern: pattern, now: time.Now}
+}
+
+// NewRedisQueueRepositoryFromURL connects to a redis:// or rediss:// URL.
+func NewRedisQueueRepositoryFromURL(url, pattern string) (*RedisQueueRepository, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	return NewRedisQueueRepository(redis.NewClient(opts), pattern), nil
+}
+
+func (r *RedisQueueRepository) GetAll(ctx context.Context) ([]models.Queue, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, r.pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("scan %q: %w", r.pattern, err)
+	}
+	sort.Strings(keys)
+
+	updatedAt := r.now().UTC()
+	queues := make([]models.Queue, 0, len(keys))
+	for _, key := range keys {
+		queue, ok, err := r.readQueue(ctx, key, updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			queues = append(queues, queue)
+		}
+	}
+	return queues, nil
+}
+
+// Get reads only the key the queue name stands for, provided it matches
+// the pattern.
+func (r *RedisQueueRepository) Get(ctx context.Context, name string) (*models.Queue, error) {
+	key := r.keyPrefix() + name
+	if !redisMatch(r.pattern, key) {
+		return nil, ErrNotFound
+	}
+	queue, ok, err := r.readQueue(ctx, key, r.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &queue, nil
+}
+
+// readQueue reads the queue held at key, reporting false for keys that
+// are missing or of a type that holds no queue.
+func (r *RedisQueueRepository) readQueue(ctx context.Context, key string, updatedAt time.Time) (models.Queue, bool, error) {
+	keyType, err := r.client.Type(ctx, key).Result()
+	if err != nil {
+		return models.Queue{}, false, fmt.Errorf("type of %s: %w", key, err)
+	}
+
+	queue := models.Queue{Name: r.queueName(key), UpdatedAt: updatedAt}
+	switch keyType {
+	case "list":
+		queue.Tasks, err = r.listTasks(ctx, key)
+	case "zset":
+		queue.Tasks, err = r.sortedSetTasks(ctx, key)
+	case "stream":
+		queue.Tasks, queue.ConsumerGroups, err = r.streamTasks(ctx, key)
+	default:
+		return models.Queue{}, false, nil
+	}
+	if err != nil {
+		return models.Queue{}, false, fmt.Errorf("read queue %s: %w", key, err)
+	}
+	if queue.Tasks == nil {
+		queue.Tasks = []models.QueueTask{}
+	}
+	return queue, true, nil
+}
+
+func (r *RedisQueueRepository) Close() {
+	r.client.Close()
+}
+
+// keyPrefix is the pattern's literal prefix.
+func (r *RedisQueueRepository) keyPrefix() string {
+	prefix := r.pattern
+	if i := strings.IndexAny(prefix, "*?[\\"); i >= 0 {
+		prefix = prefix[:i]
+	}
+	return prefix
+}
+
+func (r *RedisQueueRepository) queueName(key string) string {
+	if name := strings.TrimPrefix(key, r.keyPrefix()); name != "" {
+		return name
+	}
+	return key
+}
+
+// redisMatch reports whether key matches a glob-style pattern the way
+// SCAN MATCH does: * and ? match any characters, [...] a set or range,
+// optionally negated with ^, and \ escapes the next character.
+func redisMatch(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			for i := len(key); i >= 0; i-- {
+				if redisMatch(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if key == "" {
+				return false
+			}
+		case '[':
+			if key == "" {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return false
+			}
+			set := pattern[1 : end+1]
+			negate := strings.HasPrefix(set, "^")
+			if negate {
+				set = set[1:]
+			}
+			matched := false
+			for i := 0; i < len(set); i++ {
+				switch {
+				case set[i] == '\\' && i+1 < len(set):
+					i++
+					matched = matched || set[i] == key[0]
+				case i+2 < len(set) && set[i+1] == '-':
+					lo, hi := set[i], set[i+2]
+					if lo > hi {
+						lo, hi = hi, lo
+					}
+					matched = matched || (key[0] >= lo && key[0] <= hi)
+					i += 2
+				default:
+					matched = matched || set[i] == key[0]
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			pattern = pattern[end+1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if key == "" || pattern[0] != key[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return key == ""
+}
+
+func (r *RedisQueueRepository) listTasks(ctx context.Context, key string) ([]models.QueueTask, error) {
+	members, err := r.client.LRange(ctx, key, 0, redisMaxTasks-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]models.QueueTask, 0, len(members))
+	for _, member := range members {
+		tasks = append(tasks, decodeRedisTask(member, nil))
+	}
+	return tasks, nil
+}
+
+// sortedSetTasks uses each member's score as its priority unless the
+// member carries its own.
+func (r *RedisQueueRepository) sortedSetTasks(ctx context.Context, key string) ([]models.QueueTask, error) {
+	members, err := r.client.ZRangeWithScores(ctx, key, 0, redisMaxTasks-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]models.QueueTask, 0, len(members))
+	for _, member := range members {
+		raw, _ := member.Member.(string)
+		task := decodeRedisTask(raw, nil)
+		if task.Priority.Level == "" {
+			task.Priority.Level = strconv.FormatFloat(member.Score, 'f', -1, 64)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (r *RedisQueueRepository) streamTasks(ctx context.Context, key string) ([]models.QueueTask, []models.ConsumerGroup, error) {
+	groups, err := r.client.XInfoGroups(ctx, key).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(groups) == 0 {
+		entries, err := r.client.XRangeN(ctx, key, "-", "+", redisMaxTasks).Result()
+		if err != nil {
+			return nil, nil, err
+		}
+		return streamEntryTasks(entries), nil, nil
+	}
+
+	var (
+		entries = make(map[string]redis.XMessage)
+		pending []string
+		result  = make([]models.ConsumerGroup, 0, len(groups))
+	)
+	for _, group := range groups {
+		// Entries after the group's last delivered ID have not reached it.
+		undelivered, err := r.client.XRangeN(ctx, key, "("+group.LastDeliveredID, "+", redisMaxTasks).Result()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range undelivered {
+			entries[entry.ID] = entry
+		}
+
+		lag := int64(len(undelivered))
+		if lag == redisMaxTasks && group.Lag > lag {
+			lag = group.Lag
+		}
+		result = append(result, models.ConsumerGroup{
+			Name:            group.Name,
+			Consumers:       int(group.Consumers),
+			Pending:         group.Pending,
+			Lag:             lag,
+			LastDeliveredID: group.LastDeliveredID,
+		})
+
+		if group.Pending == 0 {
+			continue
+		}
+		delivered, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: key, Group: group.Name, Start: "-", End: "+", Count: redisMaxTasks,
+		}).Result()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range delivered {
+			if _, ok := entries[entry.ID]; !ok {
+				pending = append(pending, entry.ID)
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		pipe := r.client.Pipeline()
+		cmds := make([]*redis.XMessageSliceCmd, 0, len(pending))
+		for _, id := range pending {
+			cmds = append(cmds, pipe.XRange(ctx, key, id, id))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, nil, err
+		}
+		for _, cmd := range cmds {
+			for _, entry := range cmd.Val() {
+				entries[entry.ID] = entry
+			}
+		}
+	}
+
+	ordered := make([]redis.XMessage, 0, len(entries))
+	for _, entry := range entries {
+		ordered = append(ordered, entry)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return streamIDLess(ordered[i].ID, ordered[j].ID) })
+	if len(ordered) > redisMaxTasks {
+		ordered = ordered[:redisMaxTasks]
+	}
+	return streamEntryTasks(ordered), result, nil
+}
+
+// streamEntryTasks decodes stream entries. An entry's fields are read like
+// a JSON task; a "task" field holding JSON is used as the whole task.
+// Entries without a submitted_at are dated by their ID.
+func streamEntryTasks(entries []redis.XMessage) []models.QueueTask {
+	tasks := make([]models.QueueTask, 0, len(entries))
+	for _, entry := range entries {
+		var task models.QueueTask
+		if raw, ok := entry.Values["task"].(string); ok {
+			task = decodeRedisTask(raw, nil)
+		} else {
+			task = decodeRedisTask("", entry.Values)
+		}
+		if task.ID == "" {
+			task.ID = entry.ID
+		}
+		if task.SubmittedAt.IsZero() {
+			if ms, _ := splitStreamID(entry.ID); ms > 0 {
+				task.SubmittedAt = time.UnixMilli(int64(ms)).UTC()
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// decodeRedisTask reads a task from a raw member, which is either a JSON
+// object or a bare task ID, or from already split fields.
+func decodeRedisTask(raw string, fields map[string]interface{}) models.QueueTask {
+	if fields == nil {
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return models.QueueTask{ID: raw}
+		}
+	}
+
+	task := models.QueueTask{
+		ID:          fieldString(fields["id"]),
+		Priority:    models.Priority{Level: fieldPriority(fields["priority"])},
+		SubmittedAt: fieldTime(fields["submitted_at"]),
+	}
+	if task.ID == "" && raw != "" {
+		task.ID = raw
+	}
+	return task
+}
+
+// streamIDLess orders stream IDs of the form "<ms>-<seq>" numerically.
+func streamIDLess(a, b string) bool {
+	aMs, aSeq := splitStreamID(a)
+	bMs, bSeq := splitStreamID(b)
+	if aMs != bMs {
+		return aMs < bMs
+	}
+	return aSeq < bSeq
+}
+
+func splitStreamID(id string) (uint64, uint64) {
+	ms, seq, _ := strings.Cut(id, "-")
+	msValue, _ := strconv.ParseUint(ms, 10, 64)
+	seqValue, _ := strconv.ParseUint(seq, 10, 64)
+	return msValue, seqValue
+}
@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/config"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	return server, client
+}
+
+func findQueue(queues []models.Queue, name string) *models.Queue {
+	for i := range queues {
+		if queues[i].Name == name {
+			return &queues[i]
+		}
+	}
+	return nil
+}
+
+func TestRedisQueueRepository_ListsAndSortedSets(t *testing.T) {
+	server, client := newTestRedis(t)
+	server.RPush("queue:default", `{"id":"task-1","priority":"high","submitted_at":"2026-02-06T10:00:00Z"}`)
+	server.RPush("queue:default", "task-2")
+	server.ZAdd("queue:ranked", 1, "task-3")
+	server.ZAdd("queue:ranked", 5, `{"id":"task-4","priority":{"level":"low"},"submitted_at":1770372000}`)
+	server.Set("queue:config", "not a queue")
+	server.RPush("other:list", "ignored")
+
+	repo := NewRedisQueueRepository(client, "queue:*")
+	defer repo.Close()
+
+	queues, err := repo.GetAll(context.Background())
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(queues) != 2 {
+		t.Fatalf("Expected the list and sorted set only, got %+v", queues)
+	}
+
+	list := findQueue(queues, "default")
+	if list == nil || len(list.Tasks) != 2 {
+		t.Fatalf("Unexpected default queue %+v", list)
+	}
+	if list.Tasks[0] != (models.QueueTask{ID: "task-1", Priority: models.Priority{Level: "high"}, SubmittedAt: time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)}) {
+		t.Errorf("Unexpected JSON task %+v", list.Tasks[0])
+	}
+	if list.Tasks[1].ID != "task-2" || list.Tasks[1].Priority.Level != "" {
+		t.Errorf("Unexpected plain task %+v", list.Tasks[1])
+	}
+
+	ranked := findQueue(queues, "ranked")
+	if ranked == nil || len(ranked.Tasks) != 2 {
+		t.Fatalf("Unexpected ranked queue %+v", ranked)
+	}
+	if ranked.Tasks[0].ID != "task-3" || ranked.Tasks[0].Priority.Level != "1" {
+		t.Errorf("Expected the score as priority, got %+v", ranked.Tasks[0])
+	}
+	if ranked.Tasks[1].Priority.Level != "low" || !ranked.Tasks[1].SubmittedAt.Equal(time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the member's own priority and Unix time, got %+v", ranked.Tasks[1])
+	}
+
+	if queue, err := repo.Get(context.Background(), "ranked"); err != nil || len(queue.Tasks) != 2 || queue.Name != "ranked" {
+		t.Errorf("Expected Get to read the ranked queue, got %+v, %v", queue, err)
+	}
+	for _, name := range []string{"config", "missing"} {
+		if _, err := repo.Get(context.Background(), name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): expected ErrNotFound, got %v", name, err)
+		}
+	}
+}
+
+func TestRedisMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"queue:*", "queue:default", true},
+		{"queue:*", "queue:a/b", true},
+		{"queue:*", "other:default", false},
+		{"queue:*:tasks", "queue:x:tasks", true},
+		{"queue:*:tasks", "queue:x", false},
+		{"queue:?", "queue:a", true},
+		{"queue:?", "queue:ab", false},
+		{"queue:[a-c]", "queue:b", true},
+		{"queue:[^a-c]", "queue:b", false},
+		{`queue:\*`, "queue:*", true},
+		{`queue:\*`, "queue:a", false},
+	} {
+		if got := redisMatch(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("redisMatch(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedisQueueRepository_StreamConsumerGroups(t *testing.T) {
+	_, client := newTestRedis(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"task-1", "task-2", "task-3", "task-4"} {
+		client.XAdd(ctx, &redis.XAddArgs{Stream: "queue:events", ID: "1770372000000-*", Values: map[string]interface{}{"id": id, "priority": "high"}})
+	}
+	client.XGroupCreate(ctx, "queue:events", "workers", "0")
+
+	// One worker reads two tasks and acknowledges the first.
+	read, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{Group: "workers", Consumer: "worker-1", Streams: []string{"queue:events", ">"}, Count: 2}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.XAck(ctx, "queue:events", "workers", read[0].Messages[0].ID)
+
+	queues, err := NewRedisQueueRepository(client, "queue:*").GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+
+	stream := findQueue(queues, "events")
+	if stream == nil {
+		t.Fatalf("Expected events stream queue, got %+v", queues)
+	}
+
+	var ids []string
+	for _, task := range stream.Tasks {
+		ids = append(ids, task.ID)
+	}
+	if len(ids) != 3 || ids[0] != "task-2" || ids[1] != "task-3" || ids[2] != "task-4" {
+		t.Errorf("Expected the unacknowledged and undelivered tasks in order, got %v", ids)
+	}
+	if !stream.Tasks[0].SubmittedAt.Equal(time.UnixMilli(1770372000000)) {
+		t.Errorf("Expected submitted_at from the entry ID, got %s", stream.Tasks[0].SubmittedAt)
+	}
+
+	if len(stream.ConsumerGroups) != 1 {
+		t.Fatalf("Expected one consumer group, got %+v", stream.ConsumerGroups)
+	}
+	group := stream.ConsumerGroups[0]
+	if group.Name != "workers" || group.Consumers != 1 || group.Pending != 1 || group.Lag != 2 {
+		t.Errorf("Unexpected consumer group %+v", group)
+	}
+}
+
+func TestRedisQueueRepository_StreamWithoutGroups(t *testing.T) {
+	_, client := newTestRedis(t)
+	ctx := context.Background()
+	client.XAdd(ctx, &redis.XAddArgs{Stream: "jobs", Values: map[string]interface{}{"task": `{"id":"task-9","priority":"low"}`}})
+
+	queues, err := NewRedisQueueRepository(client, "jobs").GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(queues) != 1 || queues[0].Name != "jobs" {
+		t.Fatalf("Expected the exact key as queue name, got %+v", queues)
+	}
+	if task := queues[0].Tasks[0]; task.ID != "task-9" || task.Priority.Level != "low" {
+		t.Errorf("Expected the task field to be decoded, got %+v", task)
+	}
+	if queues[0].ConsumerGroups != nil {
+		t.Errorf("Expected no consumer groups, got %+v", queues[0].ConsumerGroups)
+	}
+}
+
+func TestRedisQueueRepository_Unavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	server.Close()
+
+	if _, err := NewRedisQueueRepository(client, "queue:*").GetAll(context.Background()); err == nil {
+		t.Error("Expected an error when Redis is unreachable")
+	}
+}
+
+func TestRegistryBuild_Redis(t *testing.T) {
+	server := miniredis.RunT(t)
+	repos, err := NewRegistry().Build(&config.Config{
+		EnableMockData: true,
+		QueueBackend:   "redis",
+		RedisURL:       "redis://" + server.Addr() + "/0",
+		RedisPattern:   "queue:*",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer repos.Close()
+	if _, ok := repos.Queues.(*RedisQueueRepository); !ok {
+		t.Errorf("Expected Redis queue repository, got %T", repos.Queues)
+	}
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"telemetron/pkg/config"
+)
+
+// MockBackend is the backend name of the built-in mock repositories.
+const MockBackend = "mock"
+
+// Factory builds a repository from configuration.
+type Factory[T any] func(cfg *config.Config) (T, error)
+
+// Backends holds the named factories for one repository kind.
+type Backends[T any] struct {
+	kind      string
+	factories map[string]Factory[T]
+}
+
+func newBackends[T any](kind string) *Backends[T] {
+	return &Backends[T]{kind: kind, factories: make(map[string]Factory[T])}
+}
+
+// Register binds name to factory, replacing any previous binding.
+func (b *Backends[T]) Register(name string, factory Factory[T]) {
+	b.factories[name] = factory
+}
+
+// Names lists the registered backend names in sorted order.
+func (b *Backends[T]) Names() []string {
+	names := make([]string, 0, len(b.factories))
+	for name := range b.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (b *Backends[T]) build(name string, cfg *config.Config) (T, error) {
+	var zero T
+
+	if name == "" {
+		if !cfg.EnableMockData {
+			return zero, fmt.Errorf("no %s backend configured and mock data is disabled", b.kind)
+		}
+		name = MockBackend
+	}
+	if name == MockBackend && !cfg.EnableMockData {
+		return zero, fmt.Errorf("%s backend %q selected but mock data is disabled", b.kind, name)
+	}
+
+	factory, ok := b.factories[name]
+	if !ok {
+		return zero, fmt.Errorf("unknown %s backend %q (available: %s)", b.kind, name, strings.Join(b.Names(), ", "))
+	}
+
+	repo, err := factory(cfg)
+	if err != nil {
+		return zero, fmt.Errorf("%s backend %q: %w", b.kind, name, err)
+	}
+	return repo, nil
+}
+
+// Registry maps backend names to repository factories for each section of
+// the system state, so sources can be mixed per section.
+type Registry struct {
+	Agents    *Backends[AgentRepository]
+	Workloads *Backends[WorkloadRepository]
+	Queues    *Backends[QueueRepository]
+	LiteLLM   *Backends[LiteLLMRepository]
+}
+
+// NewRegistry returns a registry with all built-in backends registered.
+func NewRegistry() *Registry {
+	r := &Registry{
+		Agents:    newBackends[AgentRepository]("agent"),
+		Workloads: newBackends[WorkloadRepository]("workload"),
+		Queues:    newBackends[QueueRepository]("queue"),
+		LiteLLM:   newBackends[LiteLLMRepository]("litellm"),
+	}
+
+	r.Agents.Register(MockBackend, func(*config.Config) (AgentRepository, error) {
+		return NewMockAgentRepository(), nil
+	})
+	r.Workloads.Register(MockBackend, func(*config.Config) (WorkloadRepository, error) {
+		return NewMockWorkloadRepository(), nil
+	})
+	r.Queues.Register(MockBackend, func(*config.Config) (QueueRepository, error) {
+		return NewMockQueueRepository(), nil
+	})
+	r.LiteLLM.Register(MockBackend, func(*config.Config) (LiteLLMRepository, error) {
+		return NewMockLiteLLMRepository(), nil
+	})
+
+	r.Agents.Register("push", func(cfg *config.Config) (AgentRepository, error) {
+		return NewPushAgentRepository(cfg.AgentStorePath,
+			time.Duration(cfg.AgentStaleAfter)*time.Second, time.Duration(cfg.AgentTaskRetention)*time.Second)
+	})
+	r.Workloads.Register("kubernetes", func(cfg *config.Config) (WorkloadRepository, error) {
+		return NewKubernetesWorkloadRepositoryFromKubeconfig(cfg.KubeConfigPath, cfg.KubeNamespace, cfg.KubeSelector)
+	})
+	r.Queues.Register("redis", func(cfg *config.Config) (QueueRepository, error) {
+		return NewRedisQueueRepositoryFromURL(cfg.RedisURL, cfg.RedisPattern)
+	})
+	r.Queues.Register("rabbitmq", func(cfg *config.Config) (QueueRepository, error) {
+		if cfg.RabbitMQURL == "" {
+			return nil, fmt.Errorf("RABBITMQ_URL is not set")
+		}
+		return NewRabbitMQQueueRepository(cfg.RabbitMQURL, cfg.RabbitMQUsername, cfg.RabbitMQPassword,
+			cfg.RabbitMQVHost, cfg.RabbitMQPeek, nil), nil
+	})
+	r.Queues.Register("kafka", func(cfg *config.Config) (QueueRepository, error) {
+		brokers := splitList(cfg.KafkaBrokers)
+		if len(brokers) == 0 {
+			return nil, fmt.Errorf("KAFKA_BROKERS is not set")
+		}
+		return NewKafkaQueueRepositoryFromBrokers(brokers, splitList(cfg.KafkaTopics))
+	})
+	r.LiteLLM.Register("proxy", func(cfg *config.Config) (LiteLLMRepository, error) {
+		if cfg.LiteLLMURL == "" {
+			return nil, fmt.Errorf("LITELLM_URL is not set")
+		}
+		return NewLiteLLMProxyRepository(cfg.LiteLLMURL, cfg.LiteLLMAPIKey, nil), nil
+	})
+
+	return r
+}
+
+// Repositories bundles one repository per section of the system state.
+type Repositories struct {
+	Agents    AgentRepository
+	Workloads WorkloadRepository
+	Queues    QueueRepository
+	LiteLLM   LiteLLMRepository
+}
+
+// Build constructs the repositories selected by the *_BACKEND settings.
+// Sections without an explicit backend use mock data when it is enabled.
+func (r *Registry) Build(cfg *config.Config) (*Repositories, error) {
+	repos := &Repositories{}
+	var err error
+
+	if repos.Agents, err = r.Agents.build(cfg.AgentBackend, cfg); err != nil {
+		return nil, err
+	}
+	if repos.Workloads, err = r.Workloads.build(cfg.WorkloadBackend, cfg); err != nil {
+		repos.Close()
+		return nil, err
+	}
+	if repos.Queues, err = r.Queues.build(cfg.QueueBackend, cfg); err != nil {
+		repos.Close()
+		return nil, err
+	}
+	if repos.LiteLLM, err = r.LiteLLM.build(cfg.LiteLLMBackend, cfg); err != nil {
+		repos.Close()
+		return nil, err
+	}
+	return repos, nil
+}
+
+// Close closes every repository that was built.
+func (r *Repositories) Close() {
+	if r.Agents != nil {
+		r.Agents.Close()
+	}
+	if r.Workloads != nil {
+		r.Workloads.Close()
+	}
+	if r.Queues != nil {
+		r.Queues.Close()
+	}
+	if r.LiteLLM != nil {
+		r.LiteLLM.Close()
+	}
+}
+
+// splitList splits a comma-separated setting, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
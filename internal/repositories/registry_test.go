@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"telemetron/internal/models"
+	"telemetron/pkg/config"
+)
+
+type stubQueueRepository struct {
+	closed bool
+}
+
+func (s *stubQueueRepository) GetAll(context.Context) ([]models.Queue, error) {
+	return []models.Queue{{Name: "stub"}}, nil
+}
+
+func (s *stubQueueRepository) Get(context.Context, string) (*models.Queue, error) {
+	return nil, ErrNotFound
+}
+
+func (s *stubQueueRepository) Close() { s.closed = true }
+
+func TestRegistryBuild_DefaultsToMock(t *testing.T) {
+	repos, err := NewRegistry().Build(&config.Config{EnableMockData: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer repos.Close()
+
+	if _, ok := repos.Agents.(*MockAgentRepository); !ok {
+		t.Errorf("Expected mock agent repository, got %T", repos.Agents)
+	}
+
+	if _, ok := repos.LiteLLM.(*MockLiteLLMRepository); !ok {
+		t.Errorf("Expected mock LiteLLM repository, got %T", repos.LiteLLM)
+	}
+}
+
+func TestRegistryBuild_MixesBackends(t *testing.T) {
+	stub := &stubQueueRepository{}
+	registry := NewRegistry()
+	registry.Queues.Register("stub", func(*config.Config) (QueueRepository, error) {
+		return stub, nil
+	})
+
+	repos, err := registry.Build(&config.Config{EnableMockData: true, QueueBackend: "stub"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if repos.Queues != stub {
+		t.Errorf("Expected stub queue repository, got %T", repos.Queues)
+	}
+
+	if _, ok := repos.Workloads.(*MockWorkloadRepository); !ok {
+		t.Errorf("Expected mock workload repository, got %T", repos.Workloads)
+	}
+
+	repos.Close()
+	if !stub.closed {
+		t.Error("Expected Close to reach the stub repository")
+	}
+}
+
+func TestRegistryBuild_MockDisabled(t *testing.T) {
+	_, err := NewRegistry().Build(&config.Config{EnableMockData: false})
+	if err == nil || !strings.Contains(err.Error(), "mock data is disabled") {
+		t.Fatalf("Expected mock disabled error, got %v", err)
+	}
+
+	_, err = NewRegistry().Build(&config.Config{EnableMockData: false, AgentBackend: MockBackend})
+	if err == nil {
+		t.Fatal("Expected explicit mock backend to be rejected")
+	}
+}
+
+func TestRegistryBuild_UnknownBackend(t *testing.T) {
+	_, err := NewRegistry().Build(&config.Config{EnableMockData: true, LiteLLMBackend: "nope"})
+	if err == nil || !strings.Contains(err.Error(), `unknown litellm backend "nope"`) {
+		t.Fatalf("Expected unknown backend error, got %v", err)
+	}
+}
+
+func TestRegistryBuild_ClosesOnFailure(t *testing.T) {
+	stub := &stubQueueRepository{}
+	registry := NewRegistry()
+	registry.Queues.Register("stub", func(*config.Config) (QueueRepository, error) {
+		return stub, nil
+	})
+	registry.LiteLLM.Register("broken", func(*config.Config) (LiteLLMRepository, error) {
+		return nil, errors.New("boom")
+	})
+
+	_, err := registry.Build(&config.Config{EnableMockData: true, QueueBackend: "stub", LiteLLMBackend: "broken"})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("Expected factory error, got %v", err)
+	}
+
+	if !stub.closed {
+		t.Error("Expected already built repositories to be closed")
+	}
+}
+
+func TestBackendsNames(t *testing.T) {
+	names := NewRegistry().Workloads.Names()
+	if strings.Join(names, ",") != "kubernetes,mock" {
+		t.Errorf("Unexpected workload backends %v", names)
+	}
+}
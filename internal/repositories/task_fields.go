@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"strconv"
+	"time"
+)
+
+// Helpers for decoding loosely typed task fields from message brokers,
+// where the same field may arrive as a string, a number or an object.
+
+func fieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// fieldPriority accepts "high", 3 or {"level": "high"}.
+func fieldPriority(value interface{}) string {
+	if object, ok := value.(map[string]interface{}); ok {
+		return fieldString(object["level"])
+	}
+	return fieldString(value)
+}
+
+// fieldTime accepts RFC3339 strings and Unix timestamps in seconds or
+// milliseconds, as numbers or strings. Other values yield the zero time.
+func fieldTime(value interface{}) time.Time {
+	raw := fieldString(value)
+	if raw == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC()
+	}
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		if n > 1e12 {
+			return time.UnixMilli(int64(n)).UTC()
+		}
+		return time.Unix(int64(n), 0).UTC()
+	}
+	return time.Time{}
+}
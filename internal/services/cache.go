@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"telemetron/internal/models"
+)
+
+// sectionCache memoizes one repository's GetAll for ttl. Concurrent misses
+// share a single fetch, and once loaded an expired entry is served stale
+// while a background refresh replaces it. The shared fetch is detached from
+// any one caller's cancellation and bounded by timeout instead.
+//
+// The last good result is kept even when caching is disabled so that a
+// failing source can still be reported with its previous data.
+type sectionCache[T any] struct {
+	fetch   func(ctx context.Context) ([]T, error)
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+	group   singleflight.Group
+
+	mu        sync.RWMutex
+	items     []T
+	fetchedAt time.Time
+	loaded    bool
+	lastErr   error
+
+	// generation counts invalidations; fetched is the generation the
+	// stored items were fetched in.
+	generation uint64
+	fetched    uint64
+}
+
+// sectionResult is one section of a snapshot together with how it was
+// obtained.
+type sectionResult[T any] struct {
+	items  []T
+	cache  models.CacheInfo
+	status models.SectionStatus
+}
+
+func newSectionCache[T any](ttl, timeout time.Duration, fetch func(ctx context.Context) ([]T, error)) *sectionCache[T] {
+	return &sectionCache[T]{fetch: fetch, ttl: ttl, timeout: timeout, now: time.Now}
+}
+
+func (c *sectionCache[T]) get(ctx context.Context) sectionResult[T] {
+	return c.getWithin(ctx, 0)
+}
+
+// getWithin is get with entries refreshed once they are older than maxAge
+// rather than the cache's ttl, when maxAge is positive and shorter.
+func (c *sectionCache[T]) getWithin(ctx context.Context, maxAge time.Duration) sectionResult[T] {
+	refresh := func() (interface{}, error) {
+		return nil, c.refresh(context.WithoutCancel(ctx))
+	}
+
+	if c.ttl > 0 {
+		ttl := c.ttl
+		if maxAge > 0 && maxAge < ttl {
+			ttl = maxAge
+		}
+
+		c.mu.RLock()
+		loaded, fetchedAt := c.loaded && c.fetched == c.generation, c.fetchedAt
+		c.mu.RUnlock()
+
+		if loaded {
+			if c.now().Sub(fetchedAt) >= ttl {
+				c.group.DoChan("refresh", refresh)
+			}
+			return c.cached()
+		}
+	}
+
+	select {
+	case <-c.group.DoChan("refresh", refresh):
+		return c.cached()
+	case <-ctx.Done():
+		result := c.cached()
+		result.status = c.failed(ctx.Err())
+		return result
+	}
+}
+
+// invalidate makes the next get wait for a fresh fetch instead of serving
+// the stored items.
+func (c *sectionCache[T]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+}
+
+// cached reports the stored result, degraded when the latest fetch failed.
+func (c *sectionCache[T]) cached() sectionResult[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.loaded {
+		return sectionResult[T]{status: c.statusLocked(c.lastErr)}
+	}
+
+	age := c.now().Sub(c.fetchedAt)
+	return sectionResult[T]{
+		items: cloneSlice(c.items),
+		cache: models.CacheInfo{
+			FetchedAt: c.fetchedAt.UTC(),
+			Age:       models.Duration(age),
+			Stale:     c.lastErr != nil || (c.ttl > 0 && age >= c.ttl),
+		},
+		status: c.statusLocked(c.lastErr),
+	}
+}
+
+func (c *sectionCache[T]) failed(err error) models.SectionStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.statusLocked(err)
+}
+
+func (c *sectionCache[T]) statusLocked(err error) models.SectionStatus {
+	status := models.SectionStatus{Status: models.SectionOK}
+	if c.loaded {
+		status.LastSuccess = c.fetchedAt.UTC()
+	}
+	if err != nil {
+		status.Error = err.Error()
+		status.Status = models.SectionError
+		if c.loaded {
+			status.Status = models.SectionDegraded
+		}
+	}
+	return status
+}
+
+func (c *sectionCache[T]) refresh(ctx context.Context) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	c.mu.RLock()
+	generation := c.generation
+	c.mu.RUnlock()
+
+	items, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastErr = err
+	if err != nil {
+		return err
+	}
+	c.items = items
+	c.fetched = generation
+	c.fetchedAt = c.now()
+	c.loaded = true
+	return nil
+}
+
+func cloneSlice[T any](items []T) []T {
+	if items == nil {
+		return nil
+	}
+	out := make([]T, len(items))
+	copy(out, items)
+	return out
+}
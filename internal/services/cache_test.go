@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSectionCache_ServesFreshValue(t *testing.T) {
+	var calls int32
+	cache := newSectionCache(time.Minute, 0, func(context.Context) ([]int, error) {
+		atomic.AddInt32(&calls, 1)
+		return []int{1}, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		result := cache.get(context.Background())
+		if result.status.Status != models.SectionOK {
+			t.Fatalf("Expected ok status, got %+v", result.status)
+		}
+		if len(result.items) != 1 || result.cache.Stale {
+			t.Fatalf("Unexpected result %+v", result)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 fetch, got %d", calls)
+	}
+}
+
+func TestSectionCache_DeduplicatesConcurrentMisses(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	cache := newSectionCache(time.Minute, 0, func(context.Context) ([]int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []int{1}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result := cache.get(context.Background()); result.status.Error != "" {
+				t.Errorf("Expected no error, got %v", result.status.Error)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected concurrent misses to share 1 fetch, got %d", calls)
+	}
+}
+
+func TestSectionCache_StaleWhileRevalidate(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var value int32
+	refreshed := make(chan struct{}, 1)
+	cache := newSectionCache(time.Minute, 0, func(context.Context) ([]int32, error) {
+		v := atomic.AddInt32(&value, 1)
+		if v > 1 {
+			refreshed <- struct{}{}
+		}
+		return []int32{v}, nil
+	})
+	cache.now = clock.Now
+
+	cache.get(context.Background())
+
+	clock.Advance(90 * time.Second)
+
+	result := cache.get(context.Background())
+	if result.items[0] != 1 || !result.cache.Stale || result.cache.Age.Seconds() != 90 {
+		t.Fatalf("Expected stale first value, got %+v", result)
+	}
+	if result.status.Status != models.SectionOK {
+		t.Errorf("Expected an expired entry to still be ok, got %+v", result.status)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected background refresh")
+	}
+
+	// The refresh stores its result before the fetch returns to
+	// singleflight, so poll briefly for it to become visible.
+	deadline := time.Now().Add(time.Second)
+	for {
+		result = cache.get(context.Background())
+		if result.items[0] == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if result.items[0] != 2 || result.cache.Stale {
+		t.Errorf("Expected refreshed value, got %+v", result)
+	}
+}
+
+func TestSectionCache_ErrorWithoutValue(t *testing.T) {
+	cache := newSectionCache(time.Minute, 0, func(context.Context) ([]int, error) {
+		return nil, errors.New("backend down")
+	})
+
+	result := cache.get(context.Background())
+	if result.status.Status != models.SectionError || result.status.Error != "backend down" {
+		t.Fatalf("Expected error status when nothing is cached, got %+v", result.status)
+	}
+	if result.items != nil || !result.status.LastSuccess.IsZero() {
+		t.Errorf("Expected no data, got %+v", result)
+	}
+}
+
+func TestSectionCache_ZeroTTLAlwaysFetches(t *testing.T) {
+	var calls int32
+	cache := newSectionCache(0, 0, func(context.Context) ([]int, error) {
+		atomic.AddInt32(&calls, 1)
+		return []int{1}, nil
+	})
+
+	cache.get(context.Background())
+	cache.get(context.Background())
+
+	if calls != 2 {
+		t.Errorf("Expected 2 fetches without caching, got %d", calls)
+	}
+}
+
+func TestSectionCache_Invalidate(t *testing.T) {
+	var calls int32
+	cache := newSectionCache(time.Minute, 0, func(context.Context) ([]int, error) {
+		return []int{int(atomic.AddInt32(&calls, 1))}, nil
+	})
+
+	cache.get(context.Background())
+	cache.invalidate()
+
+	result := cache.get(context.Background())
+	if len(result.items) != 1 || result.items[0] != 2 {
+		t.Fatalf("Expected a fresh fetch after invalidation, got %+v", result.items)
+	}
+	if result = cache.get(context.Background()); result.items[0] != 2 || calls != 2 {
+		t.Errorf("Expected the refetched value to be cached again, got %+v after %d fetches", result.items, calls)
+	}
+}
+
+func TestSectionCache_GetWithinMaxAge(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	refreshed := make(chan struct{}, 1)
+	var calls int32
+	cache := newSectionCache(5*time.Minute, 0, func(context.Context) ([]int, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			refreshed <- struct{}{}
+		}
+		return []int{1}, nil
+	})
+	cache.now = clock.Now
+
+	cache.get(context.Background())
+	clock.Advance(time.Second)
+	cache.getWithin(context.Background(), 2*time.Second)
+	if calls != 1 {
+		t.Fatalf("Expected an entry younger than max age to be served, got %d fetches", calls)
+	}
+
+	clock.Advance(2 * time.Second)
+	if result := cache.get(context.Background()); result.cache.Stale {
+		t.Fatalf("Expected an entry within the ttl not to be stale, got %+v", result)
+	}
+	cache.getWithin(context.Background(), 2*time.Second)
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected an entry older than max age to be refreshed")
+	}
+}
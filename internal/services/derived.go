@@ -0,0 +1,77 @@
+package services
+
+import (
+	"time"
+
+	"telemetron/internal/models"
+)
+
+// deriveDurations fills in the durations derived from a snapshot's
+// timestamps as of now, so that clients need not compute them. The
+// snapshot's sections are copies of the cached ones, but agent and queue
+// tasks are still shared and are copied before they are changed.
+func deriveDurations(state *models.SystemState, now time.Time) {
+	for i := range state.Agents {
+		activity := &state.Agents[i].Activity
+		activity.Staleness = models.Since(activity.UpdatedAt, now)
+		if activity.ActiveTaskIDs == nil {
+			continue
+		}
+		activity.ActiveTaskIDs = append([]models.TaskStatus(nil), activity.ActiveTaskIDs...)
+		for j := range activity.ActiveTaskIDs {
+			if task := &activity.ActiveTaskIDs[j]; task.Status == models.TaskRunning {
+				task.Duration = models.Since(task.StartedAt, now)
+			}
+		}
+	}
+	for i := range state.Workload {
+		live := &state.Workload[i].Live
+		live.Staleness = models.Since(live.UpdatedAt, now)
+	}
+	for i := range state.Queues {
+		queue := &state.Queues[i]
+		queue.Staleness = models.Since(queue.UpdatedAt, now)
+		queue.MaxWait = 0
+		if queue.Tasks == nil {
+			continue
+		}
+		queue.Tasks = append([]models.QueueTask(nil), queue.Tasks...)
+		for j := range queue.Tasks {
+			task := &queue.Tasks[j]
+			task.Age = models.Since(task.SubmittedAt, now)
+			queue.MaxWait = max(queue.MaxWait, task.Age)
+		}
+	}
+}
+
+// bytesPerMiB converts pod memory, reported in MiB, to bytes.
+const bytesPerMiB = 1 << 20
+
+// deriveUtilization compares each running pod's usage with its workload's
+// limits and totals them per workload. Pods are shared with the cache and
+// copied before they are changed.
+func deriveUtilization(state *models.SystemState) {
+	for i := range state.Workload {
+		workload := &state.Workload[i]
+		total := models.WorkloadUtilization{}
+		if workload.Pods != nil {
+			workload.Pods = append([]models.Pod(nil), workload.Pods...)
+		}
+		for j := range workload.Pods {
+			pod := &workload.Pods[j]
+			pod.Utilization = models.Utilization{
+				CPU:    models.NewResourceUsage(pod.CPU, workload.PodMaxCPU.Float64()),
+				Memory: models.NewResourceUsage(float64(pod.Memory)*bytesPerMiB, workload.PodMaxRAM.Float64()),
+			}
+			if pod.Status != "running" {
+				continue
+			}
+			total.CPU = total.CPU.Add(pod.Utilization.CPU)
+			total.Memory = total.Memory.Add(pod.Utilization.Memory)
+			if pod.Utilization.CPU.Ratio > models.NearLimitRatio || pod.Utilization.Memory.Ratio > models.NearLimitRatio {
+				total.PodsNearLimit++
+			}
+		}
+		workload.Utilization = total
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"telemetron/internal/models"
+)
+
+// Single entity lookups use the repositories' key lookups rather than the
+// section caches, so an entity is as current as its source. Each is bounded
+// by the source timeout, fills in the same derived fields as a snapshot,
+// and returns repositories.ErrNotFound for keys the source does not have.
+
+// GetAgents returns every agent from the section cache, as a snapshot
+// would. It fails only when the agents could not be loaded at all.
+func (s *SystemService) GetAgents(ctx context.Context) ([]models.Agent, error) {
+	result := s.agents.get(ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if result.status.Status == models.SectionError {
+		return nil, errors.New(result.status.Error)
+	}
+	state := &models.SystemState{Agents: nonNil(result.items)}
+	deriveDurations(state, s.now())
+	return state.Agents, nil
+}
+
+// GetAgent returns the agent with name.
+func (s *SystemService) GetAgent(ctx context.Context, name string) (*models.Agent, error) {
+	ctx, cancel := s.sourceContext(ctx)
+	defer cancel()
+
+	agent, err := s.agentRepo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	state := &models.SystemState{Agents: []models.Agent{*agent}}
+	deriveDurations(state, s.now())
+	return &state.Agents[0], nil
+}
+
+// GetWorkload returns the workload of deployment with its pods'
+// utilization.
+func (s *SystemService) GetWorkload(ctx context.Context, deployment string) (*models.Workload, error) {
+	ctx, cancel := s.sourceContext(ctx)
+	defer cancel()
+
+	workload, err := s.workloadRepo.Get(ctx, deployment)
+	if err != nil {
+		return nil, err
+	}
+	state := &models.SystemState{Workload: []models.Workload{*workload}}
+	deriveDurations(state, s.now())
+	deriveUtilization(state)
+	return &state.Workload[0], nil
+}
+
+// GetQueue returns the queue with name.
+func (s *SystemService) GetQueue(ctx context.Context, name string) (*models.Queue, error) {
+	ctx, cancel := s.sourceContext(ctx)
+	defer cancel()
+
+	queue, err := s.queueRepo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	state := &models.SystemState{Queues: []models.Queue{*queue}}
+	deriveDurations(state, s.now())
+	return &state.Queues[0], nil
+}
+
+// GetModel returns the LiteLLM entries of model, one per provider.
+func (s *SystemService) GetModel(ctx context.Context, model string) ([]models.LiteLLM, error) {
+	ctx, cancel := s.sourceContext(ctx)
+	defer cancel()
+
+	return s.llmRepo.Get(ctx, model)
+}
+
+func (s *SystemService) sourceContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.sourceTimeout > 0 {
+		return context.WithTimeout(ctx, s.sourceTimeout)
+	}
+	return context.WithCancel(ctx)
+}
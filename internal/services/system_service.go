@@ -1,6 +1,10 @@
 package services
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"telemetron/internal/models"
 	"telemetron/internal/repositories"
 )
@@ -10,6 +14,33 @@ type SystemService struct {
 	workloadRepo repositories.WorkloadRepository
 	queueRepo    repositories.QueueRepository
 	llmRepo      repositories.LiteLLMRepository
+
+	cacheTTL      time.Duration
+	sourceTimeout time.Duration
+	agents        *sectionCache[models.Agent]
+	workloads     *sectionCache[models.Workload]
+	queues        *sectionCache[models.Queue]
+	litellm       *sectionCache[models.LiteLLM]
+	now           func() time.Time
+}
+
+// Option configures a SystemService.
+type Option func(*SystemService)
+
+// WithCacheTTL caches each repository's results for ttl. A zero ttl
+// disables caching; concurrent fetches are still de-duplicated.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *SystemService) {
+		s.cacheTTL = ttl
+	}
+}
+
+// WithSourceTimeout bounds each repository fetch. A zero timeout leaves
+// fetches bounded only by the caller's context.
+func WithSourceTimeout(timeout time.Duration) Option {
+	return func(s *SystemService) {
+		s.sourceTimeout = timeout
+	}
 }
 
 func NewSystemService(
@@ -17,43 +48,121 @@ func NewSystemService(
 	workload repositories.WorkloadRepository,
 	queue repositories.QueueRepository,
 	llm repositories.LiteLLMRepository,
+	opts ...Option,
 ) *SystemService {
-	return &SystemService{
+	s := &SystemService{
 		agentRepo:    agent,
 		workloadRepo: workload,
 		queueRepo:    queue,
 		llmRepo:      llm,
+		now:          time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.agents = newSectionCache(s.cacheTTL, s.sourceTimeout, func(ctx context.Context) ([]models.Agent, error) {
+		return s.agentRepo.GetAll(ctx)
+	})
+	s.workloads = newSectionCache(s.cacheTTL, s.sourceTimeout, func(ctx context.Context) ([]models.Workload, error) {
+		return s.workloadRepo.GetAll(ctx)
+	})
+	s.queues = newSectionCache(s.cacheTTL, s.sourceTimeout, func(ctx context.Context) ([]models.Queue, error) {
+		return s.queueRepo.GetAll(ctx)
+	})
+	s.litellm = newSectionCache(s.cacheTTL, s.sourceTimeout, func(ctx context.Context) ([]models.LiteLLM, error) {
+		return s.llmRepo.GetAll(ctx)
+	})
+	return s
 }
 
-func (s *SystemService) GetSystemState() (*models.SystemState, error) {
-	agents, err := s.agentRepo.GetAll()
-	if err != nil {
+// GetSystemState fetches every section concurrently and returns whatever
+// succeeded. Failing sections are reported in the state's Status block
+// rather than failing the whole snapshot; an error is returned only when
+// ctx itself is done.
+func (s *SystemService) GetSystemState(ctx context.Context) (*models.SystemState, error) {
+	return s.GetSystemStateWithin(ctx, 0)
+}
+
+// GetSystemStateWithin is GetSystemState for pollers that must see changes
+// sooner than the cache TTL: sections older than maxAge are refreshed in the
+// background, so each poll sees data no older than the previous one. The
+// cache is shared, so other readers benefit from the refreshes too.
+func (s *SystemService) GetSystemStateWithin(ctx context.Context, maxAge time.Duration) (*models.SystemState, error) {
+	var (
+		wg        sync.WaitGroup
+		agents    sectionResult[models.Agent]
+		workloads sectionResult[models.Workload]
+		queues    sectionResult[models.Queue]
+		litellm   sectionResult[models.LiteLLM]
+	)
+
+	wg.Add(4)
+	go func() { defer wg.Done(); agents = s.agents.getWithin(ctx, maxAge) }()
+	go func() { defer wg.Done(); workloads = s.workloads.getWithin(ctx, maxAge) }()
+	go func() { defer wg.Done(); queues = s.queues.getWithin(ctx, maxAge) }()
+	go func() { defer wg.Done(); litellm = s.litellm.getWithin(ctx, maxAge) }()
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	workloads, err := s.workloadRepo.GetAll()
-	if err != nil {
-		return nil, err
+	now := s.now()
+	state := &models.SystemState{
+		ID:         "system-1",
+		CapturedAt: now.UTC(),
+		Agents:     nonNil(agents.items),
+		Workload:   nonNil(workloads.items),
+		Queues:     nonNil(queues.items),
+		LiteLLM:    nonNil(litellm.items),
+		Status: map[string]models.SectionStatus{
+			models.SectionAgents:   agents.status,
+			models.SectionWorkload: workloads.status,
+			models.SectionQueues:   queues.status,
+			models.SectionLiteLLM:  litellm.status,
+		},
 	}
 
-	queues, err := s.queueRepo.GetAll()
-	if err != nil {
-		return nil, err
+	if s.cacheTTL > 0 {
+		state.Cache = make(map[string]models.CacheInfo, 4)
+		for section, info := range map[string]models.CacheInfo{
+			models.SectionAgents:   agents.cache,
+			models.SectionWorkload: workloads.cache,
+			models.SectionQueues:   queues.cache,
+			models.SectionLiteLLM:  litellm.cache,
+		} {
+			if !info.FetchedAt.IsZero() {
+				state.Cache[section] = info
+			}
+		}
 	}
+	deriveDurations(state, now)
+	deriveUtilization(state)
+	return state, nil
+}
 
-	litellm, err := s.llmRepo.GetAll()
-	if err != nil {
-		return nil, err
+// Invalidate drops the cached data of a section, named as in
+// SystemState's JSON keys, so the next snapshot fetches it again. Sources
+// that are updated by push call it after each change.
+func (s *SystemService) Invalidate(section string) {
+	switch section {
+	case models.SectionAgents:
+		s.agents.invalidate()
+	case models.SectionWorkload:
+		s.workloads.invalidate()
+	case models.SectionQueues:
+		s.queues.invalidate()
+	case models.SectionLiteLLM:
+		s.litellm.invalidate()
 	}
+}
 
-	return &models.SystemState{
-		ID:       "system-1",
-		Agents:   agents,
-		Workload: workloads,
-		Queues:   queues,
-		LiteLLM:  litellm,
-	}, nil
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
 }
 
 func (s *SystemService) Close() {
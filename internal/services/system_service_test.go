@@ -1,8 +1,14 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"telemetron/internal/models"
 	"telemetron/internal/repositories"
 	"testing"
+	"time"
 )
 
 func TestNewSystemService(t *testing.T) {
@@ -30,7 +36,7 @@ func TestGetSystemState_Success(t *testing.T) {
 	defer service.Close()
 
 	// Act
-	state, err := service.GetSystemState()
+	state, err := service.GetSystemState(context.Background())
 
 	// Assert
 	if err != nil {
@@ -53,3 +59,361 @@ func TestGetSystemState_Success(t *testing.T) {
 		t.Error("Expected workload in system state")
 	}
 }
+
+func TestGetSystemState_CacheInfo(t *testing.T) {
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(),
+		repositories.NewMockLiteLLMRepository(),
+		WithCacheTTL(time.Minute),
+	)
+	defer service.Close()
+
+	state, err := service.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, section := range []string{"agents", "workload", "queues", "litellm"} {
+		info, ok := state.Cache[section]
+		if !ok {
+			t.Errorf("Expected cache info for %s", section)
+			continue
+		}
+		if info.Stale || info.FetchedAt.IsZero() {
+			t.Errorf("Expected fresh cache info for %s, got %+v", section, info)
+		}
+	}
+}
+
+type slowLiteLLMRepository struct {
+	delay time.Duration
+}
+
+func (r *slowLiteLLMRepository) GetAll(ctx context.Context) ([]models.LiteLLM, error) {
+	select {
+	case <-time.After(r.delay):
+		return []models.LiteLLM{{Model: "slow"}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *slowLiteLLMRepository) Get(context.Context, string) ([]models.LiteLLM, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func (r *slowLiteLLMRepository) Close() {}
+
+func TestGetSystemState_SourceTimeout(t *testing.T) {
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(),
+		&slowLiteLLMRepository{delay: time.Second},
+		WithSourceTimeout(20*time.Millisecond),
+	)
+	defer service.Close()
+
+	start := time.Now()
+	state, err := service.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected partial snapshot, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected timeout to cut the fetch short, took %v", elapsed)
+	}
+
+	status := state.Status[models.SectionLiteLLM]
+	if status.Status != models.SectionError || !strings.Contains(status.Error, "deadline exceeded") {
+		t.Errorf("Expected litellm section to time out, got %+v", status)
+	}
+
+	if state.LiteLLM == nil || len(state.LiteLLM) != 0 {
+		t.Errorf("Expected empty litellm section, got %v", state.LiteLLM)
+	}
+
+	if len(state.Agents) == 0 || state.Status[models.SectionAgents].Status != models.SectionOK {
+		t.Error("Expected agents to be unaffected by the slow source")
+	}
+}
+
+type flakyQueueRepository struct {
+	calls int
+}
+
+func (r *flakyQueueRepository) GetAll(context.Context) ([]models.Queue, error) {
+	r.calls++
+	if r.calls > 1 {
+		return nil, errors.New("queue backend down")
+	}
+	return []models.Queue{{Name: "default"}}, nil
+}
+
+func (r *flakyQueueRepository) Get(context.Context, string) (*models.Queue, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func (r *flakyQueueRepository) Close() {}
+
+func TestGetSystemState_DegradedKeepsLastSuccess(t *testing.T) {
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		&flakyQueueRepository{},
+		repositories.NewMockLiteLLMRepository(),
+	)
+	defer service.Close()
+
+	if _, err := service.GetSystemState(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	state, err := service.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	status := state.Status[models.SectionQueues]
+	if status.Status != models.SectionDegraded || status.Error != "queue backend down" || status.LastSuccess.IsZero() {
+		t.Errorf("Expected degraded queues section, got %+v", status)
+	}
+
+	if len(state.Queues) != 1 || state.Queues[0].Name != "default" {
+		t.Errorf("Expected last successful queues, got %v", state.Queues)
+	}
+}
+
+func TestGetSystemState_CallerCancellation(t *testing.T) {
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(),
+		&slowLiteLLMRepository{delay: time.Second},
+	)
+	defer service.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := service.GetSystemState(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected caller deadline to abort the request, got %v", err)
+	}
+}
+
+type fixedQueueRepository struct {
+	queues []models.Queue
+}
+
+func (r *fixedQueueRepository) GetAll(context.Context) ([]models.Queue, error) {
+	return r.queues, nil
+}
+
+func (r *fixedQueueRepository) Get(context.Context, string) (*models.Queue, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func (r *fixedQueueRepository) Close() {}
+
+func TestGetSystemState_DerivedDurations(t *testing.T) {
+	now := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	queues := &fixedQueueRepository{queues: []models.Queue{{
+		Name:      "default",
+		UpdatedAt: now.Add(-30 * time.Second),
+		Tasks: []models.QueueTask{
+			{ID: "task-1", SubmittedAt: now.Add(-5 * time.Minute)},
+			{ID: "task-2", SubmittedAt: now.Add(-time.Minute)},
+			{ID: "task-3"},
+		},
+	}}}
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		queues,
+		repositories.NewMockLiteLLMRepository(),
+		WithCacheTTL(time.Hour),
+	)
+	defer service.Close()
+	service.now = func() time.Time { return now }
+
+	state, err := service.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	queue := state.Queues[0]
+	if queue.Staleness != models.Duration(30*time.Second) || queue.MaxWait != models.Duration(5*time.Minute) {
+		t.Errorf("Expected 30s staleness and 5m max wait, got %v and %v", queue.Staleness, queue.MaxWait)
+	}
+	ages := []models.Duration{models.Duration(5 * time.Minute), models.Duration(time.Minute), 0}
+	for i, task := range queue.Tasks {
+		if task.Age != ages[i] {
+			t.Errorf("Expected %s to be %v old, got %v", task.ID, ages[i], task.Age)
+		}
+	}
+	if !state.CapturedAt.Equal(now) {
+		t.Errorf("Expected the snapshot to be captured at %v, got %v", now, state.CapturedAt)
+	}
+
+	// Ages are computed per snapshot, not stored in the cached section.
+	now = now.Add(time.Minute)
+	state, err = service.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if age := state.Queues[0].Tasks[0].Age; age != models.Duration(6*time.Minute) {
+		t.Errorf("Expected the task to have aged to 6m, got %v", age)
+	}
+	if age := queues.queues[0].Tasks[0].Age; age != 0 {
+		t.Errorf("Expected the repository's tasks to be left alone, got age %v", age)
+	}
+}
+
+type fixedWorkloadRepository struct {
+	workloads []models.Workload
+}
+
+func (r *fixedWorkloadRepository) GetAll(context.Context) ([]models.Workload, error) {
+	return r.workloads, nil
+}
+
+func (r *fixedWorkloadRepository) Get(context.Context, string) (*models.Workload, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func (r *fixedWorkloadRepository) Close() {}
+
+func TestGetSystemState_Utilization(t *testing.T) {
+	workloads := &fixedWorkloadRepository{workloads: []models.Workload{{
+		DeploymentName: "agents",
+		PodMaxCPU:      models.MustParseQuantity("500m"),
+		PodMaxRAM:      models.MustParseQuantity("1Gi"),
+		Pods: []models.Pod{
+			{PodID: "pod-1", CPU: 0.46875, Memory: 512, Status: "running"},
+			{PodID: "pod-2", CPU: 0.125, Memory: 1000, Status: "running"},
+			{PodID: "pod-3", Status: "pending"},
+		},
+	}, {
+		DeploymentName: "unlimited",
+		Pods:           []models.Pod{{PodID: "pod-4", CPU: 2, Memory: 64, Status: "running"}},
+	}}}
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		workloads,
+		repositories.NewMockQueueRepository(),
+		repositories.NewMockLiteLLMRepository(),
+	)
+	defer service.Close()
+
+	state, err := service.GetSystemState(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pod := state.Workload[0].Pods[0].Utilization
+	if pod.CPU != (models.ResourceUsage{Used: 0.46875, Limit: 0.5, Ratio: 0.9375, Headroom: 0.03125}) {
+		t.Errorf("Unexpected pod CPU utilization %+v", pod.CPU)
+	}
+	if pod.Memory != (models.ResourceUsage{Used: 512 << 20, Limit: 1 << 30, Ratio: 0.5, Headroom: 512 << 20}) {
+		t.Errorf("Unexpected pod memory utilization %+v", pod.Memory)
+	}
+
+	total := state.Workload[0].Utilization
+	if total.CPU != (models.ResourceUsage{Used: 0.59375, Limit: 1, Ratio: 0.59375, Headroom: 0.40625}) {
+		t.Errorf("Expected the running pods' CPU to be totalled, got %+v", total.CPU)
+	}
+	if total.Memory.Used != 1512<<20 || total.Memory.Limit != 2<<30 {
+		t.Errorf("Expected the running pods' memory to be totalled, got %+v", total.Memory)
+	}
+	if total.PodsNearLimit != 2 {
+		t.Errorf("Expected 2 pods near a limit, got %d", total.PodsNearLimit)
+	}
+
+	if unlimited := state.Workload[1].Utilization; unlimited.CPU != (models.ResourceUsage{Used: 2}) || unlimited.PodsNearLimit != 0 {
+		t.Errorf("Expected usage without limits to carry no ratio, got %+v", unlimited)
+	}
+	if workloads.workloads[0].Pods[0].Utilization != (models.Utilization{}) {
+		t.Error("Expected the repository's pods to be left alone")
+	}
+}
+
+func TestGetEntities(t *testing.T) {
+	service := NewSystemService(
+		repositories.NewMockAgentRepository(),
+		repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(),
+		repositories.NewMockLiteLLMRepository(),
+	)
+	defer service.Close()
+	ctx := context.Background()
+
+	agents, err := service.GetAgents(ctx)
+	if err != nil || len(agents) != 2 {
+		t.Fatalf("Expected the mock agents, got %+v, %v", agents, err)
+	}
+
+	agent, err := service.GetAgent(ctx, "agent-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := agent.Activity.ActiveTaskIDs[0]; task.Status != models.TaskRunning || task.Duration <= 0 {
+		t.Errorf("Expected the running task's duration to be derived, got %+v", task)
+	}
+
+	workload, err := service.GetWorkload(ctx, "agent-deployment-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu := workload.Pods[0].Utilization.CPU; cpu.Ratio != 0.5 || workload.Utilization.CPU.Used != 1 {
+		t.Errorf("Expected utilization to be derived, got %+v and %+v", cpu, workload.Utilization)
+	}
+
+	queue, err := service.GetQueue(ctx, "default")
+	if err != nil || queue.MaxWait <= 0 {
+		t.Errorf("Expected the default queue with its max wait, got %+v, %v", queue, err)
+	}
+
+	entries, err := service.GetModel(ctx, "claude-3-opus")
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected claude-3-opus, got %+v, %v", entries, err)
+	}
+
+	if _, err := service.GetWorkload(ctx, "missing"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown workload, got %v", err)
+	}
+}
+
+type countingAgentRepository struct {
+	calls int32
+}
+
+func (r *countingAgentRepository) GetAll(context.Context) ([]models.Agent, error) {
+	atomic.AddInt32(&r.calls, 1)
+	return []models.Agent{{Name: "agent-1"}}, nil
+}
+
+func (r *countingAgentRepository) Get(context.Context, string) (*models.Agent, error) {
+	return &models.Agent{Name: "agent-1"}, nil
+}
+
+func (r *countingAgentRepository) Close() {}
+
+func TestGetAgents_ServedFromCache(t *testing.T) {
+	agents := &countingAgentRepository{}
+	service := NewSystemService(agents, repositories.NewMockWorkloadRepository(),
+		repositories.NewMockQueueRepository(), repositories.NewMockLiteLLMRepository(), WithCacheTTL(time.Minute))
+	defer service.Close()
+
+	if _, err := service.GetSystemState(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := service.GetAgents(context.Background())
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("Expected the cached agent, got %+v, %v", listed, err)
+	}
+	if calls := atomic.LoadInt32(&agents.calls); calls != 1 {
+		t.Errorf("Expected the agents to be fetched once, got %d fetches", calls)
+	}
+}
@@ -0,0 +1,297 @@
+// Package stream turns successive SystemState snapshots into a replayable
+// sequence of change events for Server-Sent Events clients.
+package stream
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"telemetron/internal/diff"
+	"telemetron/internal/models"
+	"telemetron/pkg/logger"
+)
+
+// Event types. Changes without a dedicated type are published as
+// "<kind>.<op>", e.g. "pod.added" or "litellm.changed".
+const (
+	EventState      = "state"
+	EventTaskStatus = "task.status"
+	EventTaskFailed = "task.failed"
+	EventQueueDepth = "queue.depth"
+)
+
+const subscriberBuffer = 64
+
+// StateSource produces the live system state to watch.
+type StateSource interface {
+	GetSystemState(ctx context.Context) (*models.SystemState, error)
+}
+
+// StateSourceFunc adapts a function to a StateSource.
+type StateSourceFunc func(ctx context.Context) (*models.SystemState, error)
+
+// GetSystemState calls f.
+func (f StateSourceFunc) GetSystemState(ctx context.Context) (*models.SystemState, error) {
+	return f(ctx)
+}
+
+// Event is one message on the stream. State events carry the ID of the
+// last change they include, so resuming from them skips nothing.
+type Event struct {
+	ID   uint64
+	Type string
+	Data interface{}
+}
+
+// TaskStatusChange is the payload of EventTaskStatus and EventTaskFailed.
+type TaskStatusChange struct {
+	Agent  string `json:"agent"`
+	TaskID string `json:"task_id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// QueueDepthChange is the payload of EventQueueDepth.
+type QueueDepthChange struct {
+	Queue string `json:"queue"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+}
+
+// Subscription delivers events to one client. C is closed when the client
+// falls too far behind or the broadcaster stops.
+type Subscription struct {
+	C <-chan Event
+	c chan Event
+}
+
+// Broadcaster polls a StateSource, diffs consecutive snapshots and fans the
+// resulting events out to subscribers, keeping the most recent ones for
+// Last-Event-ID resumption.
+type Broadcaster struct {
+	source   StateSource
+	interval time.Duration
+	capacity int
+
+	mu          sync.Mutex
+	current     *models.SystemState
+	lastID      uint64
+	buffer      []Event
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroadcaster polls source every interval and keeps up to capacity
+// events for replay.
+func NewBroadcaster(source StateSource, interval time.Duration, capacity int) *Broadcaster {
+	return &Broadcaster{
+		source:      source,
+		interval:    interval,
+		capacity:    capacity,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Run polls until ctx is done, then closes every subscription.
+func (b *Broadcaster) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.Poll(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Error("Failed to poll system state for stream", zap.Error(err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			b.closeAll()
+			return
+		}
+	}
+}
+
+// Poll fetches the state once and publishes the changes since the
+// previous poll. The first poll publishes the full state.
+func (b *Broadcaster) Poll(ctx context.Context) error {
+	state, err := b.source.GetSystemState(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	previous := b.current
+	b.current = state
+
+	if previous == nil {
+		b.publishLocked(Event{ID: b.lastID, Type: EventState, Data: state})
+		return nil
+	}
+
+	changes, err := diff.Compute(previous, state)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range eventsFor(changes.Changes, previous, state) {
+		b.lastID++
+		event.ID = b.lastID
+		b.buffer = append(b.buffer, event)
+		b.publishLocked(event)
+	}
+	if excess := len(b.buffer) - b.capacity; excess > 0 {
+		b.buffer = append([]Event(nil), b.buffer[excess:]...)
+	}
+	return nil
+}
+
+// Subscribe registers a client. When lastEventID names an event that is
+// still buffered, the events after it are returned for replay; otherwise
+// the replay is a single state event with the current snapshot.
+func (b *Broadcaster) Subscribe(lastEventID string) ([]Event, *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: c, c: c}
+	b.subscribers[sub] = struct{}{}
+
+	if id, err := strconv.ParseUint(lastEventID, 10, 64); err == nil && b.canResumeLocked(id) {
+		var replay []Event
+		for _, event := range b.buffer {
+			if event.ID > id {
+				replay = append(replay, event)
+			}
+		}
+		return replay, sub
+	}
+
+	if b.current == nil {
+		return nil, sub
+	}
+	return []Event{{ID: b.lastID, Type: EventState, Data: b.current}}, sub
+}
+
+// Unsubscribe removes a client and closes its channel.
+func (b *Broadcaster) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(sub)
+}
+
+// Subscribers returns the number of connected clients.
+func (b *Broadcaster) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+func (b *Broadcaster) canResumeLocked(id uint64) bool {
+	if b.current == nil || id > b.lastID {
+		return false
+	}
+	firstID := b.lastID + 1
+	if len(b.buffer) > 0 {
+		firstID = b.buffer[0].ID
+	}
+	return id+1 >= firstID
+}
+
+// publishLocked delivers event to every subscriber, dropping those whose
+// buffer is full so they reconnect and resume instead of stalling others.
+func (b *Broadcaster) publishLocked(event Event) {
+	for sub := range b.subscribers {
+		select {
+		case sub.c <- event:
+		default:
+			b.removeLocked(sub)
+		}
+	}
+}
+
+func (b *Broadcaster) removeLocked(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.c)
+	}
+}
+
+func (b *Broadcaster) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		b.removeLocked(sub)
+	}
+}
+
+// eventsFor maps a diff to stream events: task status transitions, task
+// failures and queue depth changes get dedicated types, everything else is published
+// as the diff change itself.
+func eventsFor(changes []diff.Change, previous, current *models.SystemState) []Event {
+	var events []Event
+
+	for _, change := range changes {
+		if change.Kind == "task" && change.Op == diff.Changed {
+			if transition, ok := taskTransition(change); ok {
+				eventType := EventTaskStatus
+				if transition.To == string(models.TaskFailed) {
+					eventType = EventTaskFailed
+				}
+				events = append(events, Event{Type: eventType, Data: transition})
+				continue
+			}
+		}
+		events = append(events, Event{Type: change.Kind + "." + change.Op, Data: change})
+	}
+
+	before := queueDepths(previous)
+	for _, queue := range current.Queues {
+		if depth := len(queue.Tasks); depth != before[queue.Name] {
+			events = append(events, Event{Type: EventQueueDepth, Data: QueueDepthChange{Queue: queue.Name, From: before[queue.Name], To: depth}})
+		}
+		delete(before, queue.Name)
+	}
+	for name, depth := range before {
+		if depth != 0 {
+			events = append(events, Event{Type: EventQueueDepth, Data: QueueDepthChange{Queue: name, From: depth, To: 0}})
+		}
+	}
+	return events
+}
+
+func taskTransition(change diff.Change) (TaskStatusChange, bool) {
+	for _, field := range change.Fields {
+		if field.Field != "status" {
+			continue
+		}
+		from, _ := field.From.(string)
+		to, _ := field.To.(string)
+		agent, taskID := splitTaskEntity(change.Entity)
+		return TaskStatusChange{Agent: agent, TaskID: taskID, From: from, To: to}, true
+	}
+	return TaskStatusChange{}, false
+}
+
+// splitTaskEntity splits "agent/active_task_ids/task" into agent and task.
+// Agent names may contain "/", so the split is on the collection segment.
+func splitTaskEntity(entity string) (string, string) {
+	agent, task, ok := strings.Cut(entity, "/active_task_ids/")
+	if !ok {
+		return "", entity
+	}
+	return agent, task
+}
+
+func queueDepths(state *models.SystemState) map[string]int {
+	depths := make(map[string]int, len(state.Queues))
+	for _, queue := range state.Queues {
+		depths[queue.Name] = len(queue.Tasks)
+	}
+	return depths
+}
@@ -0,0 +1,189 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+type sequenceSource struct {
+	mu     sync.Mutex
+	states []*models.SystemState
+}
+
+func (s *sequenceSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.states[0]
+	if len(s.states) > 1 {
+		s.states = s.states[1:]
+	}
+	return state, nil
+}
+
+func stateWith(taskStatus models.TaskState, queueTasks int, pods ...string) *models.SystemState {
+	state := &models.SystemState{
+		Agents: []models.Agent{{Name: "agent-1", Activity: models.Activity{
+			ActiveTaskIDs: []models.TaskStatus{{ID: "task-1", Status: taskStatus}},
+		}}},
+		Workload: []models.Workload{{DeploymentName: "deploy-1"}},
+		Queues:   []models.Queue{{Name: "default"}},
+	}
+	for _, pod := range pods {
+		state.Workload[0].Pods = append(state.Workload[0].Pods, models.Pod{PodID: pod, Status: "running"})
+	}
+	for i := 0; i < queueTasks; i++ {
+		state.Queues[0].Tasks = append(state.Queues[0].Tasks, models.QueueTask{ID: string(rune('a' + i))})
+	}
+	return state
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.C:
+		if !ok {
+			t.Fatal("Subscription closed unexpectedly")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestBroadcaster_PublishesChanges(t *testing.T) {
+	source := &sequenceSource{states: []*models.SystemState{
+		stateWith("pending", 1, "pod-1"),
+		stateWith("running", 2, "pod-1", "pod-2"),
+	}}
+	b := NewBroadcaster(source, time.Hour, 10)
+
+	replay, sub := b.Subscribe("")
+	if len(replay) != 0 {
+		t.Fatalf("Expected no replay before the first poll, got %v", replay)
+	}
+
+	b.Poll(context.Background())
+	if event := receive(t, sub); event.Type != EventState {
+		t.Fatalf("Expected initial state event, got %s", event.Type)
+	}
+
+	b.Poll(context.Background())
+	got := map[string]Event{}
+	for i := 0; i < 4; i++ {
+		event := receive(t, sub)
+		got[event.Type] = event
+	}
+
+	transition, ok := got[EventTaskStatus].Data.(TaskStatusChange)
+	if !ok || transition != (TaskStatusChange{Agent: "agent-1", TaskID: "task-1", From: "pending", To: "running"}) {
+		t.Errorf("Unexpected task transition %+v", got[EventTaskStatus])
+	}
+
+	depth, ok := got[EventQueueDepth].Data.(QueueDepthChange)
+	if !ok || depth.From != 1 || depth.To != 2 {
+		t.Errorf("Unexpected queue depth change %+v", got[EventQueueDepth])
+	}
+
+	for _, eventType := range []string{"pod.added", "queue_task.added"} {
+		if _, ok := got[eventType]; !ok {
+			t.Errorf("Expected %s event, got %v", eventType, got)
+		}
+	}
+}
+
+func TestBroadcaster_ResumeFromLastEventID(t *testing.T) {
+	source := &sequenceSource{states: []*models.SystemState{
+		stateWith("pending", 0),
+		stateWith("running", 0),
+		stateWith("failed", 0),
+	}}
+	b := NewBroadcaster(source, time.Hour, 10)
+	for i := 0; i < 3; i++ {
+		b.Poll(context.Background())
+	}
+
+	replay, sub := b.Subscribe("1")
+	defer b.Unsubscribe(sub)
+	if len(replay) != 1 || replay[0].ID != 2 || replay[0].Type != EventTaskFailed {
+		t.Fatalf("Expected to replay the task failure (event 2) only, got %+v", replay)
+	}
+
+	replay, _ = b.Subscribe("2")
+	if len(replay) != 0 {
+		t.Errorf("Expected nothing to replay when up to date, got %+v", replay)
+	}
+
+	replay, _ = b.Subscribe("99")
+	if len(replay) != 1 || replay[0].Type != EventState || replay[0].ID != 2 {
+		t.Errorf("Expected full state for an unknown ID, got %+v", replay)
+	}
+}
+
+func TestBroadcaster_ResumeOutsideBufferSendsState(t *testing.T) {
+	source := &sequenceSource{states: []*models.SystemState{
+		stateWith("pending", 0),
+		stateWith("running", 0),
+		stateWith("failed", 0),
+		stateWith("completed", 0),
+	}}
+	b := NewBroadcaster(source, time.Hour, 1)
+	for i := 0; i < 4; i++ {
+		b.Poll(context.Background())
+	}
+
+	replay, _ := b.Subscribe("1")
+	if len(replay) != 1 || replay[0].Type != EventState {
+		t.Errorf("Expected full state once the gap was evicted, got %+v", replay)
+	}
+}
+
+func TestBroadcaster_DropsSlowSubscribers(t *testing.T) {
+	states := []*models.SystemState{}
+	for i := 0; i < subscriberBuffer+2; i++ {
+		states = append(states, stateWith("pending", i%2))
+	}
+	b := NewBroadcaster(&sequenceSource{states: states}, time.Hour, 10)
+
+	_, sub := b.Subscribe("")
+	for range states {
+		b.Poll(context.Background())
+	}
+
+	count := 0
+	for range sub.C {
+		count++
+	}
+	if count != subscriberBuffer {
+		t.Errorf("Expected the subscription to close after %d buffered events, got %d", subscriberBuffer, count)
+	}
+}
+
+func TestBroadcaster_Subscribers(t *testing.T) {
+	b := NewBroadcaster(&sequenceSource{states: []*models.SystemState{stateWith("pending", 0)}}, time.Hour, 10)
+	if n := b.Subscribers(); n != 0 {
+		t.Errorf("Expected no subscribers, got %d", n)
+	}
+
+	_, first := b.Subscribe("")
+	_, second := b.Subscribe("")
+	if n := b.Subscribers(); n != 2 {
+		t.Errorf("Expected 2 subscribers, got %d", n)
+	}
+
+	b.Unsubscribe(first)
+	b.Unsubscribe(second)
+	if n := b.Subscribers(); n != 0 {
+		t.Errorf("Expected no subscribers after unsubscribing, got %d", n)
+	}
+}
+
+func TestSplitTaskEntity(t *testing.T) {
+	if agent, task := splitTaskEntity("team/agent-1/active_task_ids/task-1"); agent != "team/agent-1" || task != "task-1" {
+		t.Errorf("Expected agent team/agent-1 and task task-1, got %q and %q", agent, task)
+	}
+}
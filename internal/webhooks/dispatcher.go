@@ -0,0 +1,360 @@
+// Package webhooks delivers state-change events and alerts to subscribed
+// HTTP receivers, with retries, HMAC signatures and a dead-letter log.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"telemetron/pkg/logger"
+)
+
+// Delivery states.
+const (
+	StatusPending   = "pending"
+	StatusRetrying  = "retrying"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-Telemetron-Event"
+	HeaderDelivery  = "X-Telemetron-Delivery"
+	HeaderSignature = "X-Telemetron-Signature"
+)
+
+const (
+	queueSize  = 256
+	maxHistory = 1000
+	maxBackoff = 5 * time.Minute
+)
+
+// Event is the JSON body posted to receivers.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Delivery tracks one event sent to one subscription.
+type Delivery struct {
+	ID            string          `json:"id"`
+	Subscription  string          `json:"subscription"`
+	Event         string          `json:"event"`
+	EventID       string          `json:"event_id"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithMaxAttempts gives up on a delivery after n attempts.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = n
+	}
+}
+
+// WithBackoff waits backoff before the first retry, doubling it for each
+// retry after that up to five minutes.
+func WithBackoff(backoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.backoff = backoff
+	}
+}
+
+// WithTimeout bounds each delivery attempt.
+func WithTimeout(timeout time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.client.Timeout = timeout
+	}
+}
+
+// WithDeadLetterLog appends every delivery that is given up on to w, one
+// JSON object per line.
+func WithDeadLetterLog(w io.Writer) Option {
+	return func(d *Dispatcher) {
+		d.deadLetters = w
+	}
+}
+
+type subscriber struct {
+	Subscription
+	queue chan *Delivery
+}
+
+// Dispatcher fans events out to subscriptions. Each subscription has its
+// own queue and worker, so a slow or failing receiver only delays itself.
+type Dispatcher struct {
+	subscribers []*subscriber
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	deadLetters io.Writer
+	now         func() time.Time
+
+	mu      sync.Mutex
+	history []*Delivery
+}
+
+func NewDispatcher(subscriptions []Subscription, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: 5,
+		backoff:     time.Second,
+		now:         time.Now,
+	}
+	for _, sub := range subscriptions {
+		d.subscribers = append(d.subscribers, &subscriber{
+			Subscription: sub,
+			queue:        make(chan *Delivery, queueSize),
+		})
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run delivers queued events until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sub := range d.subscribers {
+		wg.Add(1)
+		go func(sub *subscriber) {
+			defer wg.Done()
+			for {
+				select {
+				case delivery := <-sub.queue:
+					d.deliver(ctx, sub, delivery)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(sub)
+	}
+	wg.Wait()
+}
+
+// Publish queues an event for every subscription that wants its type.
+func (d *Dispatcher) Publish(eventType string, data interface{}) {
+	event := Event{ID: newID(), Type: eventType, CreatedAt: d.now().UTC(), Data: data}
+	var payload []byte
+
+	for _, sub := range d.subscribers {
+		if !sub.Wants(eventType) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				logger.Log.Error("Failed to encode webhook event", zap.String("event", eventType), zap.Error(err))
+				return
+			}
+		}
+
+		delivery := &Delivery{
+			ID:           event.ID + "-" + sub.Name,
+			Subscription: sub.Name,
+			Event:        eventType,
+			EventID:      event.ID,
+			Status:       StatusPending,
+			CreatedAt:    event.CreatedAt,
+			UpdatedAt:    event.CreatedAt,
+			Payload:      payload,
+		}
+		d.record(delivery)
+
+		select {
+		case sub.queue <- delivery:
+		default:
+			d.update(delivery, func(delivery *Delivery) {
+				delivery.Error = "delivery queue full"
+			})
+			d.giveUp(delivery)
+		}
+	}
+}
+
+// Deliveries returns recorded deliveries, most recent first, optionally
+// filtered by subscription and status. A limit of 0 returns all of them.
+func (d *Dispatcher) Deliveries(subscription, status string, limit int) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := []Delivery{}
+	for i := len(d.history) - 1; i >= 0; i-- {
+		delivery := d.history[i]
+		if subscription != "" && delivery.Subscription != subscription {
+			continue
+		}
+		if status != "" && delivery.Status != status {
+			continue
+		}
+		deliveries = append(deliveries, *delivery)
+		if limit > 0 && len(deliveries) == limit {
+			break
+		}
+	}
+	return deliveries
+}
+
+// deliver attempts a delivery until it succeeds, fails permanently or runs
+// out of attempts.
+func (d *Dispatcher) deliver(ctx context.Context, sub *subscriber, delivery *Delivery) {
+	backoff := d.backoff
+	for {
+		code, err := d.send(ctx, sub, delivery)
+
+		attempts := 0
+		d.update(delivery, func(delivery *Delivery) {
+			delivery.Attempts++
+			delivery.ResponseCode = code
+			delivery.NextAttemptAt = nil
+			delivery.Error = ""
+			if err != nil {
+				delivery.Error = err.Error()
+			}
+			attempts = delivery.Attempts
+		})
+
+		if err == nil {
+			d.update(delivery, func(delivery *Delivery) {
+				delivery.Status = StatusDelivered
+			})
+			return
+		}
+		if attempts >= d.maxAttempts || !retryable(code) || ctx.Err() != nil {
+			d.giveUp(delivery)
+			return
+		}
+
+		next := d.now().Add(backoff)
+		d.update(delivery, func(delivery *Delivery) {
+			delivery.Status = StatusRetrying
+			delivery.NextAttemptAt = &next
+		})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			d.giveUp(delivery)
+			return
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// send posts the payload once and returns the response code.
+func (d *Dispatcher) send(ctx context.Context, sub *subscriber, delivery *Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	if sub.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, delivery.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// giveUp marks a delivery dead and appends it to the dead-letter log.
+func (d *Dispatcher) giveUp(delivery *Delivery) {
+	var record []byte
+	d.update(delivery, func(delivery *Delivery) {
+		delivery.Status = StatusDead
+		delivery.NextAttemptAt = nil
+		record, _ = json.Marshal(delivery)
+	})
+
+	logger.Log.Error("Webhook delivery failed",
+		zap.String("subscription", delivery.Subscription),
+		zap.String("event", delivery.Event),
+		zap.String("delivery", delivery.ID))
+
+	if d.deadLetters == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.deadLetters.Write(append(record, '\n')); err != nil {
+		logger.Log.Error("Failed to write webhook dead letter", zap.Error(err))
+	}
+}
+
+func (d *Dispatcher) record(delivery *Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.history = append(d.history, delivery)
+	if excess := len(d.history) - maxHistory; excess > 0 {
+		d.history = append([]*Delivery(nil), d.history[excess:]...)
+	}
+}
+
+func (d *Dispatcher) update(delivery *Delivery, apply func(*Delivery)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	apply(delivery)
+	delivery.UpdatedAt = d.now().UTC()
+}
+
+// retryable reports whether a failed attempt is worth repeating. Transport
+// errors, timeouts, throttling and server errors are; other client errors
+// will fail the same way again.
+func retryable(code int) bool {
+	return code == 0 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
+
+// Sign returns the X-Telemetron-Signature value for payload: "sha256="
+// followed by the hex HMAC-SHA256 of the body keyed with secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,301 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"telemetron/internal/alerting"
+	"telemetron/internal/models"
+	"telemetron/internal/stream"
+)
+
+type received struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+// receiver is an httptest webhook endpoint that answers with the queued
+// status codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	codes    []int
+	requests []received
+	server   *httptest.Server
+}
+
+func newReceiver(t *testing.T, codes ...int) *receiver {
+	r := &receiver{codes: codes}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, received{
+			event:     req.Header.Get(HeaderEvent),
+			signature: req.Header.Get(HeaderSignature),
+			body:      body,
+		})
+		code := http.StatusOK
+		if len(r.codes) > 0 {
+			code, r.codes = r.codes[0], r.codes[1:]
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) received() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.requests...)
+}
+
+// lockedBuffer is a dead-letter log safe to read while workers write.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func startDispatcher(t *testing.T, subs []Subscription, opts ...Option) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(subs, append([]Option{WithBackoff(time.Millisecond)}, opts...)...)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return d
+}
+
+func waitForStatus(t *testing.T, d *Dispatcher, subscription, status string, count int) []Delivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if deliveries := d.Deliveries(subscription, status, 0); len(deliveries) >= count {
+			return deliveries
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d %s deliveries to %s, have %+v", count, status, subscription, d.Deliveries("", "", 0))
+	return nil
+}
+
+func TestParseSubscriptions(t *testing.T) {
+	t.Setenv("OPS_WEBHOOK_SECRET", "s3cret")
+	subs, err := ParseSubscriptions([]byte(`
+webhooks:
+  - name: ops
+    url: https://ops.example.com/hooks
+    secret: ${OPS_WEBHOOK_SECRET}
+    events: ["task.failed", "alert.*"]
+  - name: audit
+    url: http://audit.internal/events
+`))
+	if err != nil {
+		t.Fatalf("ParseSubscriptions failed: %v", err)
+	}
+	if len(subs) != 2 || subs[0].Secret != "s3cret" {
+		t.Fatalf("Unexpected subscriptions %+v", subs)
+	}
+
+	for eventType, want := range map[string]bool{
+		"task.failed":    true,
+		"alert.firing":   true,
+		"alert.resolved": true,
+		"task.status":    false,
+		"pod.changed":    false,
+	} {
+		if got := subs[0].Wants(eventType); got != want {
+			t.Errorf("ops.Wants(%q) = %v, want %v", eventType, got, want)
+		}
+	}
+	if !subs[1].Wants("pod.changed") {
+		t.Error("Expected a subscription without filters to receive every event")
+	}
+
+	for name, data := range map[string]string{
+		"missing name": "webhooks:\n  - url: https://example.com\n",
+		"bad url":      "webhooks:\n  - name: a\n    url: example.com\n",
+		"duplicate":    "webhooks:\n  - name: a\n    url: https://example.com\n  - name: a\n    url: https://example.com\n",
+		"bad pattern":  "webhooks:\n  - name: a\n    url: https://example.com\n    events: [\"[\"]\n",
+	} {
+		if _, err := ParseSubscriptions([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	ops := newReceiver(t)
+	audit := newReceiver(t)
+	d := startDispatcher(t, []Subscription{
+		{Name: "ops", URL: ops.server.URL, Secret: "s3cret", Events: []string{"task.failed"}},
+		{Name: "audit", URL: audit.server.URL},
+	})
+
+	failure := stream.TaskStatusChange{Agent: "agent-1", TaskID: "task-1", From: "running", To: "failed"}
+	d.Publish(stream.EventTaskFailed, failure)
+	d.Publish("pod.changed", map[string]string{"entity": "deploy-1/pods/pod-1"})
+
+	waitForStatus(t, d, "ops", StatusDelivered, 1)
+	waitForStatus(t, d, "audit", StatusDelivered, 2)
+
+	requests := ops.received()
+	if len(requests) != 1 || requests[0].event != stream.EventTaskFailed {
+		t.Fatalf("Expected ops to receive only task.failed, got %+v", requests)
+	}
+	if requests[0].signature != Sign("s3cret", requests[0].body) {
+		t.Errorf("Signature %q does not match the body", requests[0].signature)
+	}
+
+	var event struct {
+		ID   string                  `json:"id"`
+		Type string                  `json:"type"`
+		Data stream.TaskStatusChange `json:"data"`
+	}
+	if err := json.Unmarshal(requests[0].body, &event); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if event.ID == "" || event.Type != stream.EventTaskFailed || event.Data != failure {
+		t.Errorf("Unexpected payload %+v", event)
+	}
+
+	if signature := audit.received()[0].signature; signature != "" {
+		t.Errorf("Expected no signature without a secret, got %q", signature)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	flaky := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	d := startDispatcher(t, []Subscription{{Name: "flaky", URL: flaky.server.URL}})
+
+	d.Publish("queue.depth", stream.QueueDepthChange{Queue: "default", From: 1, To: 2})
+
+	delivery := waitForStatus(t, d, "flaky", StatusDelivered, 1)[0]
+	if delivery.Attempts != 3 || delivery.ResponseCode != http.StatusOK || delivery.Error != "" {
+		t.Errorf("Expected delivery on the third attempt, got %+v", delivery)
+	}
+	if len(flaky.received()) != 3 {
+		t.Errorf("Expected 3 requests, got %d", len(flaky.received()))
+	}
+}
+
+func TestDispatcher_DeadLetters(t *testing.T) {
+	down := newReceiver(t, 500, 500, 500, 500)
+	rejecting := newReceiver(t, http.StatusBadRequest)
+	deadLetters := &lockedBuffer{}
+	d := startDispatcher(t, []Subscription{
+		{Name: "down", URL: down.server.URL},
+		{Name: "rejecting", URL: rejecting.server.URL},
+	}, WithMaxAttempts(3), WithDeadLetterLog(deadLetters))
+
+	d.Publish("agent.removed", map[string]string{"entity": "agent-2"})
+
+	dead := waitForStatus(t, d, "down", StatusDead, 1)[0]
+	if dead.Attempts != 3 || dead.ResponseCode != 500 {
+		t.Errorf("Expected to give up after 3 attempts, got %+v", dead)
+	}
+	rejected := waitForStatus(t, d, "rejecting", StatusDead, 1)[0]
+	if rejected.Attempts != 1 {
+		t.Errorf("Expected a client error not to be retried, got %d attempts", rejected.Attempts)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace([]byte(deadLetters.String())), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %q", deadLetters.String())
+	}
+	var record Delivery
+	if err := json.Unmarshal(lines[0], &record); err != nil {
+		t.Fatalf("Dead letter is not JSON: %v", err)
+	}
+	if record.Status != StatusDead || record.Event != "agent.removed" || len(record.Payload) == 0 {
+		t.Errorf("Unexpected dead letter %+v", record)
+	}
+}
+
+func TestDispatcher_WatchAndAlerts(t *testing.T) {
+	hook := newReceiver(t)
+	d := startDispatcher(t, []Subscription{{Name: "hook", URL: hook.server.URL}})
+
+	running := &models.SystemState{Agents: []models.Agent{{Name: "agent-1", Activity: models.Activity{
+		ActiveTaskIDs: []models.TaskStatus{{ID: "task-1", Status: "running"}},
+	}}}}
+	failed := &models.SystemState{Agents: []models.Agent{{Name: "agent-1", Activity: models.Activity{
+		ActiveTaskIDs: []models.TaskStatus{{ID: "task-1", Status: "failed"}},
+	}}}}
+	source := &toggleSource{states: [2]*models.SystemState{running, failed}}
+	broadcaster := stream.NewBroadcaster(source, time.Hour, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watching := make(chan struct{})
+	go func() {
+		d.Watch(ctx, broadcaster)
+		close(watching)
+	}()
+
+	d.NotifyAlerts([]alerting.Alert{{Rule: "agent-overloaded", Entity: "agent-1", State: alerting.StateFiring}})
+
+	// Keep producing task transitions until one reaches the receiver, as
+	// the watcher subscribes asynchronously.
+	types := map[string]bool{}
+	deadline := time.Now().Add(2 * time.Second)
+	for !types[stream.EventTaskFailed] && time.Now().Before(deadline) {
+		broadcaster.Poll(context.Background())
+		time.Sleep(5 * time.Millisecond)
+		for _, delivery := range d.Deliveries("hook", "", 0) {
+			types[delivery.Event] = true
+		}
+	}
+
+	if !types[stream.EventTaskFailed] || !types["alert.firing"] || types[stream.EventState] {
+		t.Errorf("Expected task.failed and alert.firing deliveries without state, got %v", types)
+	}
+	waitForStatus(t, d, "hook", StatusDelivered, 2)
+
+	cancel()
+	select {
+	case <-watching:
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not stop after cancellation")
+	}
+}
+
+// toggleSource alternates between two states on every call.
+type toggleSource struct {
+	mu     sync.Mutex
+	calls  int
+	states [2]*models.SystemState
+}
+
+func (s *toggleSource) GetSystemState(context.Context) (*models.SystemState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.states[s.calls%2], nil
+}
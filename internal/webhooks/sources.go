@@ -0,0 +1,63 @@
+package webhooks
+
+import (
+	"context"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"telemetron/internal/alerting"
+	"telemetron/internal/stream"
+	"telemetron/pkg/logger"
+)
+
+// Watch publishes every change event from the broadcaster until ctx is
+// done. Full state events are not forwarded. If the subscription is dropped
+// for falling behind, Watch resumes from the last event it saw.
+func (d *Dispatcher) Watch(ctx context.Context, broadcaster *stream.Broadcaster) {
+	lastID := ""
+	for {
+		replay, sub := broadcaster.Subscribe(lastID)
+		for _, event := range replay {
+			lastID = d.publishStreamEvent(event, lastID)
+		}
+
+	receive:
+		for {
+			select {
+			case event, ok := <-sub.C:
+				if !ok {
+					break receive
+				}
+				lastID = d.publishStreamEvent(event, lastID)
+			case <-ctx.Done():
+				broadcaster.Unsubscribe(sub)
+				return
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Log.Warn("Webhook stream subscription dropped, resuming", zap.String("last_event_id", lastID))
+	}
+}
+
+func (d *Dispatcher) publishStreamEvent(event stream.Event, lastID string) string {
+	if event.Type == stream.EventState {
+		if lastID != "" {
+			logger.Log.Warn("Webhook stream resumed from a full state, changes may have been missed")
+		}
+	} else {
+		d.Publish(event.Type, event.Data)
+	}
+	return strconv.FormatUint(event.ID, 10)
+}
+
+// NotifyAlerts publishes alert transitions as "alert.firing" and
+// "alert.resolved" events. It is meant for alerting.WithNotifier.
+func (d *Dispatcher) NotifyAlerts(alerts []alerting.Alert) {
+	for _, alert := range alerts {
+		d.Publish("alert."+alert.State, alert)
+	}
+}
@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Subscription is one webhook receiver. Events lists the event types it
+// wants, as patterns such as "task.failed" or "alert.*"; an empty list
+// receives every event.
+type Subscription struct {
+	Name   string   `yaml:"name"`
+	URL    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Events []string `yaml:"events"`
+}
+
+type subscriptionFile struct {
+	Webhooks []Subscription `yaml:"webhooks"`
+}
+
+// LoadSubscriptions reads subscriptions from a YAML file of the form
+//
+//	webhooks:
+//	  - name: ops
+//	    url: https://ops.example.com/hooks/telemetron
+//	    secret: ${OPS_WEBHOOK_SECRET}
+//	    events: ["task.failed", "alert.*"]
+func LoadSubscriptions(path string) ([]Subscription, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSubscriptions(data)
+}
+
+// ParseSubscriptions decodes and validates YAML subscriptions. Environment
+// variables in url and secret are expanded so secrets can stay out of the
+// file.
+func ParseSubscriptions(data []byte) ([]Subscription, error) {
+	var file subscriptionFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse webhook subscriptions: %w", err)
+	}
+
+	seen := make(map[string]bool, len(file.Webhooks))
+	for i := range file.Webhooks {
+		sub := &file.Webhooks[i]
+		if sub.Name == "" {
+			return nil, fmt.Errorf("webhook %d has no name", i+1)
+		}
+		if seen[sub.Name] {
+			return nil, fmt.Errorf("duplicate webhook %q", sub.Name)
+		}
+		seen[sub.Name] = true
+
+		sub.URL = os.ExpandEnv(sub.URL)
+		sub.Secret = os.ExpandEnv(sub.Secret)
+
+		target, err := url.Parse(sub.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, fmt.Errorf("webhook %q: invalid url %q", sub.Name, sub.URL)
+		}
+		for _, pattern := range sub.Events {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("webhook %q: invalid event pattern %q", sub.Name, pattern)
+			}
+		}
+	}
+	return file.Webhooks, nil
+}
+
+// Wants reports whether the subscription receives events of eventType.
+func (s Subscription) Wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, pattern := range s.Events {
+		if ok, _ := path.Match(pattern, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
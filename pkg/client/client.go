@@ -0,0 +1,169 @@
+// Package client talks to a Telemetron server: Client reads the system
+// state and sends agent reports, and Reporter delivers an agent's
+// heartbeats and task updates in the background.
+//
+// The package depends only on the standard library and Telemetron's data
+// models, which are re-exported here as aliases.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"telemetron/internal/models"
+)
+
+// Aliases of the server's models, so that callers outside this module can
+// name them.
+type (
+	SystemState    = models.SystemState
+	SectionStatus  = models.SectionStatus
+	Agent          = models.Agent
+	Activity       = models.Activity
+	TaskStatus     = models.TaskStatus
+	TaskState      = models.TaskState
+	Workload       = models.Workload
+	Pod            = models.Pod
+	Quantity       = models.Quantity
+	Utilization    = models.Utilization
+	ResourceUsage  = models.ResourceUsage
+	Queue          = models.Queue
+	QueueTask      = models.QueueTask
+	Duration       = models.Duration
+	LiteLLM        = models.LiteLLM
+	AgentHeartbeat = models.AgentHeartbeat
+	TaskReport     = models.TaskReport
+)
+
+// Task states. Completed, failed and cancelled are final.
+const (
+	TaskPending   = models.TaskPending
+	TaskRunning   = models.TaskRunning
+	TaskCompleted = models.TaskCompleted
+	TaskFailed    = models.TaskFailed
+	TaskCancelled = models.TaskCancelled
+)
+
+// APIError is returned when the server answers with an unexpected status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telemetron responded %d: %s", e.StatusCode, e.Message)
+}
+
+// Client calls the Telemetron HTTP API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through c instead of a client with a 10
+// second timeout.
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.http = c
+	}
+}
+
+// New returns a client for the server at baseURL, e.g.
+// "http://telemetron:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SystemState fetches the current system state. When every section
+// failed the server answers 503; the state is still returned, together
+// with an *APIError.
+func (c *Client) SystemState(ctx context.Context) (*SystemState, error) {
+	var state SystemState
+	err := c.do(ctx, http.MethodGet, "/system/state", nil, &state)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable && state.Status != nil {
+		return &state, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Heartbeat registers the named agent or marks it alive, and returns it
+// as the server now reports it.
+func (c *Client) Heartbeat(ctx context.Context, name string, heartbeat AgentHeartbeat) (*Agent, error) {
+	var agent Agent
+	if err := c.do(ctx, http.MethodPost, "/agents/"+url.PathEscape(name)+"/heartbeat", heartbeat, &agent); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// ReportTasks sets the status of the given tasks of the named agent. The
+// server rejects the whole report if any task is invalid or was already
+// finished with another status.
+func (c *Client) ReportTasks(ctx context.Context, name string, tasks []TaskStatus) (*Agent, error) {
+	var agent Agent
+	if err := c.do(ctx, http.MethodPost, "/agents/"+url.PathEscape(name)+"/tasks", TaskReport{Tasks: tasks}, &agent); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// do sends body as JSON and decodes the response into out. Responses that
+// are not 200 yield an *APIError, but a JSON body is still decoded.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	message := strings.TrimSpace(string(data))
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") && json.Unmarshal(data, out) == nil {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go/build"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_SystemState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/system/state" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "system-1", "agents": [{"name": "agent-1", "activity": {"active_task_ids": [{"id": "task-1", "status": "running"}]}}],
+			"status": {"agents": {"status": "ok"}}}`))
+	}))
+	defer server.Close()
+
+	state, err := New(server.URL + "/").SystemState(context.Background())
+	if err != nil {
+		t.Fatalf("SystemState failed: %v", err)
+	}
+	if len(state.Agents) != 1 || state.Agents[0].Activity.ActiveTaskIDs[0].Status != TaskRunning {
+		t.Errorf("Unexpected state %+v", state)
+	}
+}
+
+func TestClient_SystemStateUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status": {"agents": {"status": "error", "error": "boom"}}}`))
+	}))
+	defer server.Close()
+
+	state, err := New(server.URL).SystemState(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a 503 APIError, got %v", err)
+	}
+	if state == nil || state.Status["agents"].Error != "boom" {
+		t.Errorf("Expected the state to be returned with its status block, got %+v", state)
+	}
+}
+
+func TestClient_AgentReports(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		if strings.HasSuffix(r.URL.Path, "/tasks") {
+			var report TaskReport
+			json.NewDecoder(r.Body).Decode(&report)
+			if report.Tasks[0].Status == TaskRunning {
+				http.Error(w, "task already finished: task-1 is completed", http.StatusConflict)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(Agent{Name: "agent 1"})
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	agent, err := c.Heartbeat(context.Background(), "agent 1", AgentHeartbeat{Models: []string{"gpt-4"}})
+	if err != nil || agent.Name != "agent 1" {
+		t.Fatalf("Heartbeat returned %+v, %v", agent, err)
+	}
+	if _, err := c.ReportTasks(context.Background(), "agent 1", []TaskStatus{{ID: "task-1", Status: TaskCompleted}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.ReportTasks(context.Background(), "agent 1", []TaskStatus{{ID: "task-1", Status: TaskRunning}})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict || !strings.Contains(apiErr.Message, "already finished") {
+		t.Errorf("Expected a 409 APIError, got %v", err)
+	}
+	if paths[0] != "/agents/agent%201/heartbeat" {
+		t.Errorf("Expected the agent name to be escaped, got %s", paths[0])
+	}
+}
+
+// TestClient_Dependencies keeps the package free of the server's
+// dependencies.
+func TestClient_Dependencies(t *testing.T) {
+	pkg, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range pkg.Imports {
+		if path != "telemetron/internal/models" && strings.Contains(strings.Split(path, "/")[0], ".") {
+			t.Errorf("Unexpected dependency %s", path)
+		}
+		if strings.HasPrefix(path, "telemetron/") && path != "telemetron/internal/models" {
+			t.Errorf("Unexpected dependency %s", path)
+		}
+	}
+}
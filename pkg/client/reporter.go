@@ -0,0 +1,333 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Reporter defaults.
+const (
+	DefaultHeartbeatInterval = 15 * time.Second
+	DefaultFlushInterval     = time.Second
+	DefaultBatchSize         = 100
+	DefaultBufferSize        = 1000
+)
+
+// Reporter reports one agent's activity without blocking it. Task updates
+// are buffered, with later updates of a task merged into earlier ones, and
+// sent in batches by Run alongside periodic heartbeats. Start and finish
+// times are taken when a task is updated, not when the update is sent.
+//
+// The buffer holds at most BufferSize tasks; updates of further tasks are
+// dropped and counted. Updates the server rejects as invalid are dropped
+// too, without the rest of their batch; batches that fail otherwise are
+// retried with the next flush.
+type Reporter struct {
+	client *Client
+	name   string
+	info   AgentHeartbeat
+
+	heartbeatInterval time.Duration
+	flushInterval     time.Duration
+	batchSize         int
+	bufferSize        int
+	onError           func(error)
+
+	mu      sync.Mutex
+	order   []string
+	pending map[string]TaskStatus
+	dropped int
+	full    chan struct{}
+}
+
+// ReporterOption configures a Reporter. Options given a value that is not
+// positive keep the default.
+type ReporterOption func(*Reporter)
+
+// WithHeartbeatInterval sets how often Run sends a heartbeat. It should be
+// well below the server's AGENT_STALE_SECONDS.
+func WithHeartbeatInterval(d time.Duration) ReporterOption {
+	return func(r *Reporter) {
+		if d > 0 {
+			r.heartbeatInterval = d
+		}
+	}
+}
+
+// WithFlushInterval sets how often Run sends buffered task updates.
+func WithFlushInterval(d time.Duration) ReporterOption {
+	return func(r *Reporter) {
+		if d > 0 {
+			r.flushInterval = d
+		}
+	}
+}
+
+// WithBatchSize bounds the tasks sent per report. Reaching it triggers a
+// flush before the interval elapses.
+func WithBatchSize(n int) ReporterOption {
+	return func(r *Reporter) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithBufferSize bounds how many tasks with undelivered updates are kept.
+func WithBufferSize(n int) ReporterOption {
+	return func(r *Reporter) {
+		if n > 0 {
+			r.bufferSize = n
+		}
+	}
+}
+
+// WithErrorHandler is called with every failed heartbeat or report.
+func WithErrorHandler(fn func(error)) ReporterOption {
+	return func(r *Reporter) {
+		r.onError = fn
+	}
+}
+
+// NewReporter reports as the agent called name. info is sent with every
+// heartbeat, registering the agent with its models and parallelism.
+func NewReporter(client *Client, name string, info AgentHeartbeat, opts ...ReporterOption) *Reporter {
+	r := &Reporter{
+		client:            client,
+		name:              name,
+		info:              info,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		flushInterval:     DefaultFlushInterval,
+		batchSize:         DefaultBatchSize,
+		bufferSize:        DefaultBufferSize,
+		onError:           func(error) {},
+		pending:           make(map[string]TaskStatus),
+		full:              make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register sends a heartbeat right away, so that start-up fails loudly
+// when the server cannot be reached.
+func (r *Reporter) Register(ctx context.Context) error {
+	_, err := r.client.Heartbeat(ctx, r.name, r.info)
+	return err
+}
+
+// QueueTask reports a task as pending.
+func (r *Reporter) QueueTask(id string) {
+	r.UpdateTask(TaskStatus{ID: id, Status: TaskPending})
+}
+
+// StartTask reports a task as running.
+func (r *Reporter) StartTask(id string) {
+	r.UpdateTask(TaskStatus{ID: id, Status: TaskRunning, StartedAt: time.Now()})
+}
+
+// FinishTask reports a task as completed.
+func (r *Reporter) FinishTask(id string) {
+	r.UpdateTask(TaskStatus{ID: id, Status: TaskCompleted, FinishedAt: time.Now()})
+}
+
+// FailTask reports a task as failed with err.
+func (r *Reporter) FailTask(id string, err error) {
+	task := TaskStatus{ID: id, Status: TaskFailed, FinishedAt: time.Now()}
+	if err != nil {
+		task.Error = err.Error()
+	}
+	r.UpdateTask(task)
+}
+
+// CancelTask reports a task as cancelled.
+func (r *Reporter) CancelTask(id string) {
+	r.UpdateTask(TaskStatus{ID: id, Status: TaskCancelled, FinishedAt: time.Now()})
+}
+
+// UpdateTask reports a task with details such as its model, token counts
+// or the queue task it came from. Its Status must be set; other fields
+// left empty keep the values of earlier updates.
+func (r *Reporter) UpdateTask(task TaskStatus) { r.update(task) }
+
+// Dropped returns how many task updates were dropped, because the buffer
+// was full or the server rejected them.
+func (r *Reporter) Dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+func (r *Reporter) update(task TaskStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if earlier, ok := r.pending[task.ID]; ok {
+		task = mergeTask(earlier, task)
+	} else {
+		if len(r.order) >= r.bufferSize {
+			r.dropped++
+			return
+		}
+		r.order = append(r.order, task.ID)
+	}
+	r.pending[task.ID] = task
+
+	if len(r.order) >= r.batchSize {
+		select {
+		case r.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run sends a heartbeat every heartbeat interval and buffered task updates
+// every flush interval, until ctx is done. It then makes a last attempt to
+// deliver what is left, bounded by the flush interval.
+func (r *Reporter) Run(ctx context.Context) {
+	heartbeat := time.NewTicker(r.heartbeatInterval)
+	defer heartbeat.Stop()
+	flush := time.NewTicker(r.flushInterval)
+	defer flush.Stop()
+
+	r.heartbeat(ctx)
+	for {
+		select {
+		case <-heartbeat.C:
+			r.heartbeat(ctx)
+		case <-flush.C:
+			r.flushAll(ctx)
+		case <-r.full:
+			r.flushAll(ctx)
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.flushInterval)
+			defer cancel()
+			r.flushAll(final)
+			return
+		}
+	}
+}
+
+// Flush sends all buffered task updates, returning the first error.
+func (r *Reporter) Flush(ctx context.Context) error {
+	for {
+		batch := r.take()
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := r.send(ctx, batch); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Reporter) flushAll(ctx context.Context) {
+	if err := r.Flush(ctx); err != nil {
+		r.onError(err)
+	}
+}
+
+func (r *Reporter) heartbeat(ctx context.Context) {
+	if _, err := r.client.Heartbeat(ctx, r.name, r.info); err != nil && ctx.Err() == nil {
+		r.onError(err)
+	}
+}
+
+// take removes up to one batch of updates from the buffer, oldest first.
+func (r *Reporter) take() []TaskStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := min(len(r.order), r.batchSize)
+	batch := make([]TaskStatus, 0, n)
+	for _, id := range r.order[:n] {
+		batch = append(batch, r.pending[id])
+		delete(r.pending, id)
+	}
+	r.order = r.order[n:]
+	return batch
+}
+
+// send reports a batch. The server rejects a whole report for one invalid
+// task, so a rejected batch is split until the rejected updates are alone
+// and can be dropped. On other failures the updates go back to the buffer,
+// merged with newer ones that arrived meanwhile.
+func (r *Reporter) send(ctx context.Context, batch []TaskStatus) error {
+	_, err := r.client.ReportTasks(ctx, r.name, batch)
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusConflict) {
+		if len(batch) > 1 {
+			half := len(batch) / 2
+			first := r.send(ctx, batch[:half])
+			if second := r.send(ctx, batch[half:]); first == nil {
+				first = second
+			}
+			return first
+		}
+		r.mu.Lock()
+		r.dropped++
+		r.mu.Unlock()
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var requeued []string
+	for _, task := range batch {
+		if newer, ok := r.pending[task.ID]; ok {
+			r.pending[task.ID] = mergeTask(task, newer)
+			continue
+		}
+		if len(r.order)+len(requeued) >= r.bufferSize {
+			r.dropped++
+			continue
+		}
+		r.pending[task.ID] = task
+		requeued = append(requeued, task.ID)
+	}
+	r.order = append(requeued, r.order...)
+	return err
+}
+
+// mergeTask applies a later update of a task over an earlier one; fields
+// the later update leaves empty keep their earlier values.
+func mergeTask(earlier, later TaskStatus) TaskStatus {
+	merged := later
+	if merged.Status == "" {
+		merged.Status = earlier.Status
+	}
+	if merged.StartedAt.IsZero() {
+		merged.StartedAt = earlier.StartedAt
+	}
+	if merged.FinishedAt.IsZero() {
+		merged.FinishedAt = earlier.FinishedAt
+	}
+	if merged.Error == "" {
+		merged.Error = earlier.Error
+	}
+	if merged.Model == "" {
+		merged.Model = earlier.Model
+	}
+	if merged.InputTokens == 0 {
+		merged.InputTokens = earlier.InputTokens
+	}
+	if merged.OutputTokens == 0 {
+		merged.OutputTokens = earlier.OutputTokens
+	}
+	if merged.Queue == "" {
+		merged.Queue = earlier.Queue
+	}
+	if merged.QueueTaskID == "" {
+		merged.QueueTaskID = earlier.QueueTaskID
+	}
+	return merged
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIngestion records the heartbeats and task reports it receives. It
+// fails task reports while failing is set, and rejects those that include
+// the rejected task.
+type fakeIngestion struct {
+	mu         sync.Mutex
+	heartbeats []AgentHeartbeat
+	reports    [][]TaskStatus
+	failing    int
+	rejected   string
+}
+
+func (f *fakeIngestion) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if strings.HasSuffix(r.URL.Path, "/heartbeat") {
+		var heartbeat AgentHeartbeat
+		json.NewDecoder(r.Body).Decode(&heartbeat)
+		f.heartbeats = append(f.heartbeats, heartbeat)
+	} else {
+		if f.failing != 0 {
+			http.Error(w, http.StatusText(f.failing), f.failing)
+			return
+		}
+		var report TaskReport
+		json.NewDecoder(r.Body).Decode(&report)
+		for _, task := range report.Tasks {
+			if task.ID == f.rejected {
+				http.Error(w, "task already finished", http.StatusConflict)
+				return
+			}
+		}
+		f.reports = append(f.reports, report.Tasks)
+	}
+	w.Write([]byte(`{}`))
+}
+
+func (f *fakeIngestion) heartbeatCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.heartbeats)
+}
+
+func TestReporter_BatchesAndCoalesces(t *testing.T) {
+	fake := &fakeIngestion{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reporter := NewReporter(New(server.URL), "agent-1", AgentHeartbeat{MaxParallelInvocations: 2, Models: []string{"gpt-4"}},
+		WithBatchSize(2), WithBufferSize(3))
+	if err := reporter.Register(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter.QueueTask("task-1")
+	reporter.StartTask("task-1")
+	reporter.StartTask("task-2")
+	reporter.FailTask("task-3", errors.New("model request timed out"))
+	reporter.FinishTask("task-4")
+	if reporter.Dropped() != 1 {
+		t.Errorf("Expected the update beyond the buffer to be dropped, got %d", reporter.Dropped())
+	}
+
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.reports) != 2 || len(fake.reports[0]) != 2 || len(fake.reports[1]) != 1 {
+		t.Fatalf("Expected batches of 2 and 1, got %+v", fake.reports)
+	}
+	if first := fake.reports[0][0]; first.ID != "task-1" || first.Status != TaskRunning || first.StartedAt.IsZero() {
+		t.Errorf("Expected the latest status per task in order, got %+v", fake.reports)
+	}
+	if failed := fake.reports[1][0]; failed.Status != TaskFailed || failed.Error != "model request timed out" || failed.FinishedAt.IsZero() {
+		t.Errorf("Expected the failure with its error, got %+v", failed)
+	}
+	if len(fake.heartbeats) != 1 || fake.heartbeats[0].MaxParallelInvocations != 2 {
+		t.Errorf("Expected the registration heartbeat to carry the agent info, got %+v", fake.heartbeats)
+	}
+}
+
+func TestReporter_RetriesAndDrops(t *testing.T) {
+	fake := &fakeIngestion{failing: http.StatusBadGateway}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reporter := NewReporter(New(server.URL), "agent-1", AgentHeartbeat{})
+	reporter.StartTask("task-1")
+	if err := reporter.Flush(context.Background()); err == nil {
+		t.Fatal("Expected the flush to fail")
+	}
+
+	// The update is retried, merged into the newer status.
+	reporter.FinishTask("task-1")
+	fake.failing = 0
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.reports) != 1 || fake.reports[0][0].Status != TaskCompleted || fake.reports[0][0].StartedAt.IsZero() {
+		t.Errorf("Expected one report with the newest status and the start time, got %+v", fake.reports)
+	}
+
+	fake.failing = http.StatusConflict
+	reporter.StartTask("task-1")
+	if err := reporter.Flush(context.Background()); err == nil {
+		t.Fatal("Expected the flush to fail")
+	}
+	if reporter.Dropped() != 1 || reporter.Flush(context.Background()) != nil {
+		t.Errorf("Expected the rejected update to be dropped, got %d dropped", reporter.Dropped())
+	}
+}
+
+func TestReporter_DropsOnlyRejectedTasks(t *testing.T) {
+	fake := &fakeIngestion{rejected: "task-3"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reporter := NewReporter(New(server.URL), "agent-1", AgentHeartbeat{})
+	for _, id := range []string{"task-1", "task-2", "task-3", "task-4", "task-5"} {
+		reporter.StartTask(id)
+	}
+	if err := reporter.Flush(context.Background()); err == nil {
+		t.Fatal("Expected the rejection to be returned")
+	}
+
+	var delivered []string
+	for _, report := range fake.reports {
+		for _, task := range report {
+			delivered = append(delivered, task.ID)
+		}
+	}
+	if strings.Join(delivered, ",") != "task-1,task-2,task-4,task-5" || reporter.Dropped() != 1 {
+		t.Errorf("Expected only task-3 to be dropped, got %v delivered and %d dropped", delivered, reporter.Dropped())
+	}
+}
+
+func TestReporter_Run(t *testing.T) {
+	fake := &fakeIngestion{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	reporter := NewReporter(New(server.URL), "agent-1", AgentHeartbeat{},
+		WithHeartbeatInterval(time.Hour), WithFlushInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		reporter.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for fake.heartbeatCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	reporter.StartTask("task-1")
+	cancel()
+	<-done
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.heartbeats) != 1 || len(fake.reports) != 1 {
+		t.Errorf("Expected a heartbeat and a final flush, got %d heartbeats and reports %+v", len(fake.heartbeats), fake.reports)
+	}
+}
+
+func TestReporter_NonPositiveOptionsKeepDefaults(t *testing.T) {
+	reporter := NewReporter(New("http://localhost"), "agent-1", AgentHeartbeat{},
+		WithHeartbeatInterval(0), WithFlushInterval(-time.Second), WithBatchSize(0), WithBufferSize(-1))
+	if reporter.heartbeatInterval != DefaultHeartbeatInterval || reporter.flushInterval != DefaultFlushInterval ||
+		reporter.batchSize != DefaultBatchSize || reporter.bufferSize != DefaultBufferSize {
+		t.Errorf("Expected the defaults, got %+v", reporter)
+	}
+}
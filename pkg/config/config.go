@@ -1,26 +1,132 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 )
 
 type Config struct {
-	ServerPort     string
-	LogLevel       string
-	KubeConfigPath string
-	CacheTTL       int
-	EnableMockData bool
+	ServerPort       string
+	LogLevel         string
+	KubeConfigPath   string
+	KubeNamespace    string
+	KubeSelector     string
+	LiteLLMURL       string
+	LiteLLMAPIKey    string
+	RedisURL         string
+	RedisPattern     string
+	RabbitMQURL      string
+	RabbitMQUsername string
+	RabbitMQPassword string
+	RabbitMQVHost    string
+	RabbitMQPeek     int
+	KafkaBrokers     string
+	KafkaTopics      string
+	CacheTTL         int
+	SourceTimeout    int
+	EnableMockData   bool
+
+	AgentStorePath     string
+	AgentStaleAfter    int
+	AgentTaskRetention int
+	OTLPMappingPath    string
+	OTLPEvictAfter     int
+
+	HistoryPath         string
+	HistoryInterval     int
+	HistoryRetention    int
+	HistoryMaxSnapshots int
+
+	StreamInterval  int
+	StreamHeartbeat int
+	StreamBuffer    int
+
+	AlertRulesPath string
+	AlertInterval  int
+
+	WebhooksPath          string
+	WebhookMaxAttempts    int
+	WebhookBackoff        int
+	WebhookTimeout        int
+	WebhookDeadLetterPath string
+
+	AgentBackend    string
+	WorkloadBackend string
+	QueueBackend    string
+	LiteLLMBackend  string
 }
 
 func Load() *Config {
 	return &Config{
-		ServerPort:     getEnv("SERVER_PORT", "8080"),
-		LogLevel:       getEnv("LOG_LEVEL", "info"),
-		KubeConfigPath: getEnv("KUBECONFIG", ""),
-		CacheTTL:       getEnvAsInt("CACHE_TTL_SECONDS", 300),
-		EnableMockData: getEnvAsBool("ENABLE_MOCK_DATA", true),
+		ServerPort:       getEnv("SERVER_PORT", "8080"),
+		LogLevel:         getEnv("LOG_LEVEL", "info"),
+		KubeConfigPath:   getEnv("KUBECONFIG", ""),
+		KubeNamespace:    getEnvAllowEmpty("KUBE_NAMESPACE", "default"),
+		KubeSelector:     getEnv("KUBE_LABEL_SELECTOR", ""),
+		LiteLLMURL:       getEnv("LITELLM_URL", ""),
+		LiteLLMAPIKey:    getEnv("LITELLM_API_KEY", ""),
+		RedisURL:         getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		RedisPattern:     getEnv("REDIS_QUEUE_PATTERN", "queue:*"),
+		RabbitMQURL:      getEnv("RABBITMQ_URL", ""),
+		RabbitMQUsername: getEnv("RABBITMQ_USERNAME", "guest"),
+		RabbitMQPassword: getEnv("RABBITMQ_PASSWORD", "guest"),
+		RabbitMQVHost:    getEnv("RABBITMQ_VHOST", "/"),
+		RabbitMQPeek:     getEnvAsInt("RABBITMQ_PEEK_COUNT", 0),
+		KafkaBrokers:     getEnv("KAFKA_BROKERS", ""),
+		KafkaTopics:      getEnv("KAFKA_TOPICS", ""),
+		CacheTTL:         getEnvAsInt("CACHE_TTL_SECONDS", 300),
+		SourceTimeout:    getEnvAsInt("SOURCE_TIMEOUT_SECONDS", 5),
+		EnableMockData:   getEnvAsBool("ENABLE_MOCK_DATA", true),
+
+		AgentStorePath:     getEnv("AGENT_STORE_PATH", ""),
+		AgentStaleAfter:    getEnvAsInt("AGENT_STALE_SECONDS", 60),
+		AgentTaskRetention: getEnvAsInt("AGENT_TASK_RETENTION_SECONDS", 300),
+		OTLPMappingPath:    getEnv("OTLP_MAPPING_PATH", ""),
+		OTLPEvictAfter:     getEnvAsInt("OTLP_EVICT_SECONDS", 3600),
+
+		HistoryPath:         getEnv("HISTORY_PATH", ""),
+		HistoryInterval:     getEnvAsInt("HISTORY_INTERVAL_SECONDS", 60),
+		HistoryRetention:    getEnvAsInt("HISTORY_RETENTION_HOURS", 24),
+		HistoryMaxSnapshots: getEnvAsInt("HISTORY_MAX_SNAPSHOTS", 0),
+
+		StreamInterval:  getEnvAsInt("STREAM_INTERVAL_SECONDS", 2),
+		StreamHeartbeat: getEnvAsInt("STREAM_HEARTBEAT_SECONDS", 15),
+		StreamBuffer:    getEnvAsInt("STREAM_BUFFER_SIZE", 1000),
+
+		AlertRulesPath: getEnv("ALERT_RULES_PATH", ""),
+		AlertInterval:  getEnvAsInt("ALERT_INTERVAL_SECONDS", 30),
+
+		WebhooksPath:          getEnv("WEBHOOKS_PATH", ""),
+		WebhookMaxAttempts:    getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookBackoff:        getEnvAsInt("WEBHOOK_BACKOFF_SECONDS", 1),
+		WebhookTimeout:        getEnvAsInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+		WebhookDeadLetterPath: getEnv("WEBHOOK_DEAD_LETTER_PATH", ""),
+
+		AgentBackend:    getEnv("AGENT_BACKEND", ""),
+		WorkloadBackend: getEnv("WORKLOAD_BACKEND", ""),
+		QueueBackend:    getEnv("QUEUE_BACKEND", ""),
+		LiteLLMBackend:  getEnv("LITELLM_BACKEND", ""),
+	}
+}
+
+// Validate reports settings that would stop the server from running, such
+// as intervals that are not positive.
+func (c *Config) Validate() error {
+	for _, interval := range []struct {
+		name  string
+		value int
+	}{
+		{"STREAM_INTERVAL_SECONDS", c.StreamInterval},
+		{"STREAM_HEARTBEAT_SECONDS", c.StreamHeartbeat},
+		{"HISTORY_INTERVAL_SECONDS", c.HistoryInterval},
+		{"ALERT_INTERVAL_SECONDS", c.AlertInterval},
+	} {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", interval.name, interval.value)
+		}
 	}
+	return nil
 }
 
 func getEnv(key, fallback string) string {
@@ -30,6 +136,15 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvAllowEmpty is getEnv for variables where an empty value means
+// something: the fallback applies only when key is unset.
+func getEnvAllowEmpty(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
 func getEnvAsInt(key string, fallback int) int {
 	if value := os.Getenv(key); value != "" {
 		if intVal, err := strconv.Atoi(value); err == nil {
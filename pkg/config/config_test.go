@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoad_KubeNamespace(t *testing.T) {
+	t.Setenv("KUBE_NAMESPACE", "")
+	os.Unsetenv("KUBE_NAMESPACE")
+	if ns := Load().KubeNamespace; ns != "default" {
+		t.Errorf("Expected the default namespace when unset, got %q", ns)
+	}
+	t.Setenv("KUBE_NAMESPACE", "")
+	if ns := Load().KubeNamespace; ns != "" {
+		t.Errorf("Expected an empty namespace to be kept, got %q", ns)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Load().Validate(); err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+
+	t.Setenv("STREAM_HEARTBEAT_SECONDS", "0")
+	err := Load().Validate()
+	if err == nil || !strings.Contains(err.Error(), "STREAM_HEARTBEAT_SECONDS") {
+		t.Errorf("Expected a zero heartbeat to be rejected, got %v", err)
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"go.uber.org/zap"
 )
 
-var Log *zap.Logger
+// Log is a no-op logger until Init replaces it.
+var Log = zap.NewNop()
 
 func Init(level string) error {
 	var config zap.Config